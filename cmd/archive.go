@@ -36,24 +36,18 @@ var archiveCmd = &cobra.Command{
 
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		if dryRun {
-			return dryRunPreview(c, ids, "archive", &types.DestinationInfo{
-				ID:   string(archiveMB.ID),
-				Name: archiveMB.Name,
-			})
+			return dryRunPreview(c, ids, "archive", c.Destination(archiveMB))
 		}
 
 		succeeded, errors := c.MoveEmails(ids, archiveMB.ID)
 
 		result := types.MoveResult{
-			Matched:   len(ids),
-			Processed: len(succeeded) + len(errors),
-			Failed:    len(errors),
-			Archived:  succeeded,
-			Errors:    errors,
-			Destination: &types.DestinationInfo{
-				ID:   string(archiveMB.ID),
-				Name: archiveMB.Name,
-			},
+			Matched:     len(ids),
+			Processed:   len(succeeded) + len(errors),
+			Failed:      len(errors),
+			Archived:    succeeded,
+			Errors:      errors,
+			Destination: c.Destination(archiveMB),
 		}
 
 		if err := formatter().Format(os.Stdout, result); err != nil {
@@ -61,7 +55,7 @@ var archiveCmd = &cobra.Command{
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to archive", "")
+			return batchFailure(c, "one or more emails failed to archive")
 		}
 
 		return nil
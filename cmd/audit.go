@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/audit"
+	"github.com/cboone/fm/internal/types"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Query the audit log of changes made by fm",
+	Long: `Read the audit log written when the audit_log setting (or FM_AUDIT_LOG)
+names a file. Every mutating JMAP call fm makes is appended to it as one JSON
+line: time, profile, command line (tokens redacted), operation, JMAP method,
+IDs, destination mailbox, and the outcome for each ID. Dry runs and calls
+refused in read-only mode are recorded too.`,
+}
+
+var auditTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Show the most recent audit log entries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit < 1 {
+			return exitError("general_error", "--limit must be at least 1", "")
+		}
+		return runAuditQuery(audit.Filter{}, limit)
+	},
+}
+
+var auditSearchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Find audit log entries by ID, operation, profile, or time",
+	Long: `List the audit log entries that match every given filter, oldest first:
+  fm audit search --id M123
+  fm audit search --operation archive --since 2026-01-01
+  fm audit search --profile-name agent --failed`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var f audit.Filter
+		f.ID, _ = cmd.Flags().GetString("id")
+		f.Operation, _ = cmd.Flags().GetString("operation")
+		f.Profile, _ = cmd.Flags().GetString("profile-name")
+		f.FailedOnly, _ = cmd.Flags().GetBool("failed")
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit < 0 {
+			return exitError("general_error", "--limit must not be negative", "")
+		}
+
+		if s, _ := cmd.Flags().GetString("since"); strings.TrimSpace(s) != "" {
+			t, err := parseDate(strings.TrimSpace(s))
+			if err != nil {
+				return exitError("general_error", "invalid --since date: "+err.Error(),
+					"Use RFC 3339 format (e.g. 2026-01-15T00:00:00Z) or a bare date (e.g. 2026-01-15)")
+			}
+			f.Since = t
+		}
+		if s, _ := cmd.Flags().GetString("until"); strings.TrimSpace(s) != "" {
+			t, err := parseDate(strings.TrimSpace(s))
+			if err != nil {
+				return exitError("general_error", "invalid --until date: "+err.Error(),
+					"Use RFC 3339 format (e.g. 2026-01-15T00:00:00Z) or a bare date (e.g. 2026-01-15)")
+			}
+			f.Until = t
+		}
+
+		return runAuditQuery(f, limit)
+	},
+}
+
+// runAuditQuery prints the entries matching f, keeping the newest limit of
+// them (all when limit is 0).
+func runAuditQuery(f audit.Filter, limit int) error {
+	path := auditLogPath()
+	if path == "" {
+		return exitError("config_error", "no audit log is configured",
+			"Set audit_log in the config file or FM_AUDIT_LOG to a file path")
+	}
+
+	entries, err := audit.Read(path)
+	if err != nil {
+		return exitError("general_error", "reading audit log: "+err.Error(), "")
+	}
+
+	matched := []types.AuditEntry{}
+	for _, e := range entries {
+		if f.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	result := types.AuditLogResult{Path: path, Total: len(matched), Entries: matched}
+	if limit > 0 && len(matched) > limit {
+		result.Entries = matched[len(matched)-limit:]
+	}
+
+	return formatter().Format(os.Stdout, result)
+}
+
+func init() {
+	auditTailCmd.Flags().IntP("limit", "n", 20, "number of entries to show")
+
+	auditSearchCmd.Flags().String("id", "", "email, mailbox, or script ID (also matches the destination)")
+	auditSearchCmd.Flags().String("operation", "", "fm command (archive, sieve update, ...) or JMAP method (Email/set)")
+	auditSearchCmd.Flags().String("profile-name", "", "profile the change was made under")
+	auditSearchCmd.Flags().String("since", "", "entries at or after this date (RFC 3339 or YYYY-MM-DD)")
+	auditSearchCmd.Flags().String("until", "", "entries before this date (RFC 3339 or YYYY-MM-DD)")
+	auditSearchCmd.Flags().Bool("failed", false, "only entries where the call or any ID failed")
+	auditSearchCmd.Flags().IntP("limit", "n", 0, "show only the newest N matches (0 for all)")
+
+	auditCmd.AddCommand(auditTailCmd)
+	auditCmd.AddCommand(auditSearchCmd)
+	rootCmd.AddCommand(auditCmd)
+}
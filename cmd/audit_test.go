@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestAudit_RecordsChangesAndDryRuns(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-archive", "name": "Archive", "role": "archive"}},
+		[]map[string]any{{"id": "M1", "threadId": "T1", "subject": "Hello"}},
+		nil,
+	)
+	logPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	t.Setenv("FM_AUDIT_LOG", logPath)
+
+	if _, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "archive", "M1", "--dry-run")); err != nil {
+		t.Fatalf("dry run failed: %v\nstderr=%s", err, stderr)
+	}
+	if _, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "archive", "M1")); err != nil {
+		t.Fatalf("archive failed: %v\nstderr=%s", err, stderr)
+	}
+	if _, _, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "--read-only", "archive", "M1")); err == nil {
+		t.Fatal("expected read-only archive to fail")
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "test-token") {
+		t.Fatalf("token written to audit log:\n%s", data)
+	}
+
+	stdout, stderr, err := runCLICommand(t, []string{"audit", "tail", "--format", "json"})
+	if err != nil {
+		t.Fatalf("audit tail failed: %v\nstderr=%s", err, stderr)
+	}
+	var result types.AuditLogResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout)
+	}
+	if result.Total != 3 || len(result.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", result)
+	}
+
+	dry, real, refused := result.Entries[0], result.Entries[1], result.Entries[2]
+	if !dry.DryRun || dry.Operation != "archive" || dry.Destination != "mb-archive" || dry.IDs[0] != "M1" {
+		t.Errorf("unexpected dry-run entry: %+v", dry)
+	}
+	if real.DryRun || real.Method != "Email/set" || len(real.Results) != 1 || !real.Results[0].OK {
+		t.Errorf("unexpected archive entry: %+v", real)
+	}
+	if !strings.HasPrefix(real.Command, "fm archive ") || !strings.HasSuffix(real.Command, " M1") || !strings.Contains(real.Command, "--token=[redacted]") {
+		t.Errorf("unexpected command line: %q", real.Command)
+	}
+	if !strings.Contains(refused.Error, "read-only") {
+		t.Errorf("expected the refused call to be logged, got %+v", refused)
+	}
+
+	stdout, stderr, err = runCLICommand(t, []string{"audit", "search", "--failed", "--format", "json"})
+	if err != nil {
+		t.Fatalf("audit search failed: %v\nstderr=%s", err, stderr)
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout)
+	}
+	if result.Total != 1 || result.Entries[0].Error == "" {
+		t.Errorf("expected only the refused call, got %+v", result)
+	}
+}
+
+func TestAudit_RequiresConfiguredLog(t *testing.T) {
+	t.Setenv("FM_AUDIT_LOG", "")
+	config := writeProfileConfig(t, "keyring: off\n")
+
+	_, stderr, err := runCLICommand(t, []string{"--config", config, "audit", "tail"})
+	if err == nil || !strings.Contains(stderr, "config_error") {
+		t.Errorf("expected config_error, got err=%v stderr=%s", err, stderr)
+	}
+}
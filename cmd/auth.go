@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/credentials"
+	"github.com/cboone/fm/internal/types"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Store, check, and remove API credentials",
+	Long: `Keep the API token out of environment variables and the config file.
+
+'fm auth login' stores a token in the desktop keyring (Secret Service API,
+through secret-tool) or, when no keyring is available, in a private file
+(~/.config/fm/credentials.json, mode 0600). Tokens are stored per profile.
+Select the backend with the keyring setting: auto, secret-service, file, or
+off. 'fm auth oauth' signs in through an OAuth authorization server instead
+and stores refreshable tokens the same way. The token is never printed.`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store an API token for the current profile",
+	Long: `Read an API token, check it against the server, and store it for the
+current profile. The token is read from the terminal without echo, or from
+stdin with --token-stdin:
+  fm auth login
+  pass show fastmail | fm auth login --token-stdin`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tokenStdin, _ := cmd.Flags().GetBool("token-stdin")
+		noVerify, _ := cmd.Flags().GetBool("no-verify")
+
+		var token string
+		if tokenStdin {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return exitError("general_error", "reading token from stdin: "+err.Error(), "")
+			}
+			token = strings.TrimSpace(string(data))
+		} else {
+			var err error
+			token, err = credentials.ReadHidden(os.Stdin, os.Stderr, "Fastmail API token: ")
+			if err != nil {
+				return exitError("general_error", err.Error(),
+					"Pipe the token with --token-stdin")
+			}
+		}
+		if token == "" {
+			return exitError("general_error", "no token provided", "")
+		}
+
+		store, err := credentialStore()
+		if err != nil {
+			return exitError("config_error", err.Error(), "")
+		}
+		if store == nil {
+			return exitError("config_error", "the keyring setting is off",
+				"Set keyring to auto, secret-service, or file")
+		}
+
+		result := types.AuthResult{
+			Action:  "login",
+			Account: credentialAccount(),
+			Backend: store.Backend(),
+		}
+		if !noVerify {
+			c, err := client.New(viper.GetString("session_url"), token, viper.GetString("account_id"))
+			if err != nil {
+				return exitError("authentication_failed", err.Error(),
+					"Check the token; it was not stored")
+			}
+			result.Username = c.SessionInfo().Username
+		}
+
+		if err := store.Set(result.Account, token); err != nil {
+			return exitError("general_error", "storing token: "+err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where the token comes from and whether it works",
+	Long: `Report the token source (flag, env, config, token_command, keyring, or
+none) for the current profile and check it against the server. Exits with
+authentication_failed when no working token is found.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status := types.AuthStatus{Profile: activeProfile, Source: "none"}
+		if store, err := credentialStore(); err == nil && store != nil {
+			status.Backend = store.Backend()
+		}
+
+		tokens, source, err := resolveToken()
+		if err == nil {
+			status.Source = source
+			var c *client.Client
+			c, err = client.NewWithTokenSource(viper.GetString("session_url"), tokens, viper.GetString("account_id"))
+			if err == nil {
+				status.Authenticated = true
+				status.Username = c.SessionInfo().Username
+			}
+		}
+		if err != nil {
+			status.Error = err.Error()
+		}
+
+		if err := formatter().Format(os.Stdout, status); err != nil {
+			return err
+		}
+		if !status.Authenticated {
+			return exitError("authentication_failed", status.Error,
+				"Run 'fm auth login' or set FM_TOKEN")
+		}
+		return nil
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored token for the current profile",
+	Long: `Remove the token stored by 'fm auth login' for the current profile.
+Tokens set with FM_TOKEN, --token, token, or token_command are not affected.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := credentialStore()
+		if err != nil {
+			return exitError("config_error", err.Error(), "")
+		}
+		if store == nil {
+			return exitError("config_error", "the keyring setting is off",
+				"Set keyring to auto, secret-service, or file")
+		}
+
+		result := types.AuthResult{
+			Action:  "logout",
+			Account: credentialAccount(),
+			Backend: store.Backend(),
+		}
+		if err := store.Delete(result.Account); err != nil {
+			if errors.Is(err, credentials.ErrNotFound) {
+				return exitError("not_found", "no stored token for "+result.Account, "")
+			}
+			return exitError("general_error", "removing token: "+err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	authLoginCmd.Flags().Bool("token-stdin", false, "read the token from stdin instead of the terminal")
+	authLoginCmd.Flags().Bool("no-verify", false, "store the token without checking it against the server")
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authStatusCmd)
+	authCmd.AddCommand(authLogoutCmd)
+	rootCmd.AddCommand(authCmd)
+}
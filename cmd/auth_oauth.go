@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/credentials"
+	"github.com/cboone/fm/internal/oauth"
+	"github.com/cboone/fm/internal/types"
+)
+
+var authOAuthCmd = &cobra.Command{
+	Use:   "oauth",
+	Short: "Sign in with the OAuth device flow and store the tokens",
+	Long: `Sign in through an OAuth 2.0 authorization server using the device
+authorization grant. fm prints a URL and a code; open the URL in any browser,
+enter the code, and approve access. The access and refresh tokens are then
+stored for the current profile like a token from 'fm auth login'.
+
+Expired access tokens are refreshed automatically, and a request the server
+rejects with 401 is retried once with a refreshed token. Refreshed tokens are
+stored again.
+
+The authorization server is set in the config file (or FM_OAUTH_* variables):
+  oauth_device_url: https://auth.example.com/oauth/device
+  oauth_token_url: https://auth.example.com/oauth/token
+  oauth_client_id: my-client-id
+  oauth_scope: urn:ietf:params:jmap:core urn:ietf:params:jmap:mail`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		noVerify, _ := cmd.Flags().GetBool("no-verify")
+
+		cfg, err := oauthConfig()
+		if err != nil {
+			return exitError("config_error", err.Error(),
+				"Set oauth_device_url, oauth_token_url, and oauth_client_id in the config file")
+		}
+		store, err := credentialStore()
+		if err != nil {
+			return exitError("config_error", err.Error(), "")
+		}
+		if store == nil {
+			return exitError("config_error", "the keyring setting is off",
+				"Set keyring to auto, secret-service, or file")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		da, err := oauth.StartDeviceAuth(ctx, cfg)
+		if err != nil {
+			return exitError("authentication_failed", err.Error(), "")
+		}
+		if da.VerificationURIComplete != "" {
+			fmt.Fprintf(os.Stderr, "Open %s to approve access (code %s).\n", da.VerificationURIComplete, da.UserCode)
+		} else {
+			fmt.Fprintf(os.Stderr, "Open %s and enter the code %s to approve access.\n", da.VerificationURI, da.UserCode)
+		}
+		fmt.Fprintln(os.Stderr, "Waiting for approval...")
+
+		tok, err := oauth.PollToken(ctx, cfg, da)
+		if err != nil {
+			return exitError("authentication_failed", err.Error(), "Run 'fm auth oauth' again")
+		}
+
+		result := types.AuthResult{
+			Action:  "oauth",
+			Account: credentialAccount(),
+			Backend: store.Backend(),
+		}
+		tokens := oauthTokenSource(store, result.Account, tok)
+		if !noVerify {
+			// Nothing is stored until the server accepts the token. A
+			// token refreshed while verifying is the one saved below.
+			save := tokens.Save
+			tokens.Save = nil
+			c, err := client.NewWithTokenSource(viper.GetString("session_url"), tokens, viper.GetString("account_id"))
+			tokens.Save = save
+			if err != nil {
+				return exitError("authentication_failed", err.Error(),
+					"The server did not accept the OAuth token; it was not stored")
+			}
+			result.Username = c.SessionInfo().Username
+		}
+
+		if err := tokens.Save(tokens.Current()); err != nil {
+			return exitError("general_error", "storing token: "+err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+// oauthConfig reads the authorization server settings.
+func oauthConfig() (oauth.Config, error) {
+	cfg := oauth.Config{
+		DeviceAuthURL: viper.GetString("oauth_device_url"),
+		TokenURL:      viper.GetString("oauth_token_url"),
+		ClientID:      viper.GetString("oauth_client_id"),
+		Scopes:        strings.Fields(viper.GetString("oauth_scope")),
+	}
+	var missing []string
+	if cfg.DeviceAuthURL == "" {
+		missing = append(missing, "oauth_device_url")
+	}
+	if cfg.TokenURL == "" {
+		missing = append(missing, "oauth_token_url")
+	}
+	if cfg.ClientID == "" {
+		missing = append(missing, "oauth_client_id")
+	}
+	if len(missing) > 0 {
+		return cfg, fmt.Errorf("OAuth is not configured: missing %s", strings.Join(missing, ", "))
+	}
+	return cfg, nil
+}
+
+// oauthTokenSource returns a source for tok that writes every refreshed
+// token back to store under account. Missing settings are not an error
+// here; they only matter once the token needs refreshing.
+func oauthTokenSource(store credentials.Store, account string, tok oauth.Token) *oauth.Source {
+	cfg, _ := oauthConfig()
+	return oauth.NewSource(cfg, tok, func(t oauth.Token) error {
+		encoded, err := t.Encode()
+		if err != nil {
+			return err
+		}
+		return store.Set(account, encoded)
+	})
+}
+
+func init() {
+	authOAuthCmd.Flags().Bool("no-verify", false, "store the tokens without checking them against the server")
+	authCmd.AddCommand(authOAuthCmd)
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/credentials"
+	"github.com/cboone/fm/internal/oauth"
+	"github.com/cboone/fm/internal/types"
+)
+
+// withStdin replaces os.Stdin with content for the duration of the test.
+func withStdin(t *testing.T, content string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	old := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = old
+		r.Close()
+	})
+}
+
+func TestAuthLoginStatusLogout_FileBackend(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	t.Setenv("FM_TOKEN", "")
+	credsPath := filepath.Join(t.TempDir(), "credentials.json")
+	config := writeProfileConfig(t, "keyring: file\ncredentials_file: "+credsPath+"\nsession_url: "+server.server.URL+"/session\n")
+
+	withStdin(t, "secret-token-123\n")
+	stdout, stderr, err := runCLICommand(t, []string{"--config", config, "auth", "login", "--token-stdin"})
+	if err != nil {
+		t.Fatalf("login failed: %v\nstderr=%s", err, stderr)
+	}
+	if strings.Contains(stdout+stderr, "secret-token-123") {
+		t.Fatalf("token echoed in output: %s %s", stdout, stderr)
+	}
+	var login types.AuthResult
+	if err := json.Unmarshal([]byte(stdout), &login); err != nil {
+		t.Fatalf("decode login output: %v\n%s", err, stdout)
+	}
+	if login.Account != "default" || login.Backend != "file" || login.Username != "test@example.com" {
+		t.Errorf("unexpected login result: %+v", login)
+	}
+
+	stdout, stderr, err = runCLICommand(t, []string{"--config", config, "auth", "status"})
+	if err != nil {
+		t.Fatalf("status failed: %v\nstderr=%s", err, stderr)
+	}
+	var status types.AuthStatus
+	if err := json.Unmarshal([]byte(stdout), &status); err != nil {
+		t.Fatalf("decode status output: %v\n%s", err, stdout)
+	}
+	if status.Source != "keyring" || !status.Authenticated {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if strings.Contains(stdout, "secret-token-123") {
+		t.Fatalf("token echoed in status: %s", stdout)
+	}
+
+	if _, stderr, err = runCLICommand(t, []string{"--config", config, "auth", "logout"}); err != nil {
+		t.Fatalf("logout failed: %v\nstderr=%s", err, stderr)
+	}
+
+	stdout, stderr, err = runCLICommand(t, []string{"--config", config, "auth", "status"})
+	if err == nil {
+		t.Fatal("expected status to fail after logout")
+	}
+	if !strings.Contains(stdout, `"source": "none"`) || !strings.Contains(stderr, "authentication_failed") {
+		t.Errorf("unexpected status after logout: %s\nstderr=%s", stdout, stderr)
+	}
+
+	_, stderr, err = runCLICommand(t, []string{"--config", config, "auth", "logout"})
+	if err == nil || !strings.Contains(stderr, "not_found") {
+		t.Errorf("expected not_found on second logout, got err=%v stderr=%s", err, stderr)
+	}
+}
+
+func TestAuthLogin_RequiresTerminalWithoutTokenStdin(t *testing.T) {
+	config := writeProfileConfig(t, "keyring: file\ncredentials_file: "+filepath.Join(t.TempDir(), "c.json")+"\n")
+	withStdin(t, "")
+
+	_, stderr, err := runCLICommand(t, []string{"--config", config, "auth", "login"})
+	if err == nil {
+		t.Fatal("expected error when stdin is not a terminal")
+	}
+	if !strings.Contains(stderr, "not a terminal") || !strings.Contains(stderr, "--token-stdin") {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+}
+
+func TestAuthStatus_ReportsEnvSource(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	t.Setenv("FM_TOKEN", "env-token")
+	config := writeProfileConfig(t, "keyring: off\nsession_url: "+server.server.URL+"/session\n")
+
+	stdout, stderr, err := runCLICommand(t, []string{"--config", config, "auth", "status"})
+	if err != nil {
+		t.Fatalf("status failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"source": "env"`) || strings.Contains(stdout, "env-token") {
+		t.Errorf("unexpected status output: %s", stdout)
+	}
+}
+
+// newOAuthStubServer serves a device authorization endpoint that is approved
+// on the first poll, issuing an access token valid for expiresIn seconds,
+// and a token endpoint that refreshes to "refreshed-N" and rotates the
+// refresh token to "rotated-N".
+func newOAuthStubServer(t *testing.T, expiresIn int) (*httptest.Server, *int) {
+	t.Helper()
+	refreshes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch {
+		case r.URL.Path == "/device":
+			writeJSON(w, map[string]any{
+				"device_code":      "dev-1",
+				"user_code":        "WDJB-MJHT",
+				"verification_uri": "https://auth.example.com/device",
+				"expires_in":       60,
+			})
+		case r.URL.Path == "/token" && r.PostForm.Get("grant_type") == "refresh_token":
+			refreshes++
+			writeJSON(w, map[string]any{
+				"access_token":  fmt.Sprintf("refreshed-%d", refreshes),
+				"refresh_token": fmt.Sprintf("rotated-%d", refreshes),
+				"expires_in":    3600,
+			})
+		case r.URL.Path == "/token":
+			writeJSON(w, map[string]any{
+				"access_token":  "oauth-access",
+				"refresh_token": "oauth-refresh",
+				"expires_in":    expiresIn,
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &refreshes
+}
+
+func TestAuthOAuth_DeviceFlowStoresAndRefreshesTokens(t *testing.T) {
+	jmapServer := newJMAPMockServer(t, nil, nil, nil)
+	oauthServer, refreshes := newOAuthStubServer(t, 3600)
+	t.Setenv("FM_TOKEN", "")
+	credsPath := filepath.Join(t.TempDir(), "credentials.json")
+	config := writeProfileConfig(t, "keyring: file\ncredentials_file: "+credsPath+
+		"\nsession_url: "+jmapServer.server.URL+"/session"+
+		"\noauth_device_url: "+oauthServer.URL+"/device"+
+		"\noauth_token_url: "+oauthServer.URL+"/token"+
+		"\noauth_client_id: fm-test\n")
+
+	stdout, stderr, err := runCLICommand(t, []string{"--config", config, "auth", "oauth"})
+	if err != nil {
+		t.Fatalf("auth oauth failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stderr, "WDJB-MJHT") || !strings.Contains(stderr, "https://auth.example.com/device") {
+		t.Errorf("expected the user code and URL on stderr, got: %s", stderr)
+	}
+	var result types.AuthResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Action != "oauth" || result.Username != "test@example.com" || strings.Contains(stdout, "oauth-access") {
+		t.Errorf("unexpected result: %s", stdout)
+	}
+
+	store := &credentials.FileStore{Path: credsPath}
+	stored, err := store.Get("default")
+	if err != nil {
+		t.Fatalf("reading stored token: %v", err)
+	}
+	tok, ok := oauth.Decode(stored)
+	if !ok || tok.AccessToken != "oauth-access" || tok.RefreshToken != "oauth-refresh" {
+		t.Fatalf("unexpected stored token: %s", stored)
+	}
+
+	stdout, stderr, err = runCLICommand(t, []string{"--config", config, "auth", "status"})
+	if err != nil {
+		t.Fatalf("status failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"source": "oauth"`) {
+		t.Errorf("expected oauth source, got: %s", stdout)
+	}
+
+	// An expired access token is refreshed before use and stored again.
+	tok.Expiry = time.Now().Add(-time.Hour)
+	encoded, _ := tok.Encode()
+	if err := store.Set("default", encoded); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, err := runCLICommand(t, []string{"--config", config, "session"}); err != nil {
+		t.Fatalf("session failed: %v\nstderr=%s", err, stderr)
+	}
+	if *refreshes != 1 {
+		t.Errorf("expected 1 refresh, got %d", *refreshes)
+	}
+	stored, _ = store.Get("default")
+	if tok, _ := oauth.Decode(stored); tok.AccessToken != "refreshed-1" || tok.RefreshToken != "rotated-1" {
+		t.Errorf("refreshed token not stored: %s", stored)
+	}
+}
+
+func TestAuthOAuth_StoresTokenRefreshedWhileVerifying(t *testing.T) {
+	jmapServer := newJMAPMockServer(t, nil, nil, nil)
+	// The device flow's access token is already inside the expiry skew, so
+	// verifying the sign-in refreshes it and rotates the refresh token.
+	oauthServer, refreshes := newOAuthStubServer(t, 1)
+	t.Setenv("FM_TOKEN", "")
+	credsPath := filepath.Join(t.TempDir(), "credentials.json")
+	config := writeProfileConfig(t, "keyring: file\ncredentials_file: "+credsPath+
+		"\nsession_url: "+jmapServer.server.URL+"/session"+
+		"\noauth_device_url: "+oauthServer.URL+"/device"+
+		"\noauth_token_url: "+oauthServer.URL+"/token"+
+		"\noauth_client_id: fm-test\n")
+
+	if _, stderr, err := runCLICommand(t, []string{"--config", config, "auth", "oauth"}); err != nil {
+		t.Fatalf("auth oauth failed: %v\nstderr=%s", err, stderr)
+	}
+	if *refreshes != 1 {
+		t.Fatalf("expected 1 refresh while verifying, got %d", *refreshes)
+	}
+
+	stored, err := (&credentials.FileStore{Path: credsPath}).Get("default")
+	if err != nil {
+		t.Fatalf("reading stored token: %v", err)
+	}
+	if tok, _ := oauth.Decode(stored); tok.AccessToken != "refreshed-1" || tok.RefreshToken != "rotated-1" {
+		t.Errorf("expected the refreshed, rotated token stored, got %s", stored)
+	}
+}
+
+func TestAuthOAuth_RequiresConfiguration(t *testing.T) {
+	config := writeProfileConfig(t, "keyring: file\ncredentials_file: "+filepath.Join(t.TempDir(), "c.json")+"\noauth_client_id: fm-test\n")
+
+	_, stderr, err := runCLICommand(t, []string{"--config", config, "auth", "oauth"})
+	if err == nil {
+		t.Fatal("expected an error without OAuth endpoints")
+	}
+	if !strings.Contains(stderr, "config_error") || !strings.Contains(stderr, "oauth_device_url, oauth_token_url") {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+var budgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Show the remaining mutation budget",
+	Long: `Show how many more emails fm may change before the mutation budget runs
+out. The budget caps the emails changed by archive, move, snooze, spam, flag,
+unflag, mark-read, duplicates --move, and draft, and is set in the config
+file (or FM_BUDGET_* variables):
+  budget_per_run: 200    # in one fm command
+  budget_per_hour: 500   # in the trailing hour
+  budget_per_day: 2000   # in the trailing day
+
+A limit of 0 (the default) means no limit. Hourly and daily use is kept per
+profile in budget_file (default ~/.local/state/fm/budget.json). Changes past
+the budget are refused with forbidden_operation before anything is sent.
+
+This command reads only local state and does not contact the server.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tracker, err := budgetTracker()
+		if err != nil {
+			return exitError("config_error", err.Error(), "")
+		}
+		windows, err := tracker.Status()
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, types.BudgetResult{
+			Profile:   tracker.Account,
+			StateFile: tracker.Path,
+			Enabled:   tracker.Limits.Enabled(),
+			Windows:   windows,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(budgetCmd)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestBudget_RefusesChangesPastTheLimit(t *testing.T) {
+	server := newJMAPMockServer(t, nil,
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "One"},
+			{"id": "M2", "threadId": "T2", "subject": "Two"},
+			{"id": "M3", "threadId": "T3", "subject": "Three"},
+		},
+		nil,
+	)
+	t.Setenv("FM_BUDGET_FILE", filepath.Join(t.TempDir(), "budget.json"))
+	t.Setenv("FM_BUDGET_PER_RUN", "2")
+	t.Setenv("FM_BUDGET_PER_HOUR", "5")
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "mark-read", "M1", "M2", "M3"))
+	if err == nil {
+		t.Fatal("expected mark-read past the budget to fail")
+	}
+	if !strings.Contains(stderr, `"error": "forbidden_operation"`) || !strings.Contains(stderr, "fm budget") {
+		t.Errorf("expected forbidden_operation with a budget hint, got: %s", stderr)
+	}
+	var result types.MoveResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout)
+	}
+	if len(result.MarkedAsRead) != 2 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "mutation budget") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	stdout, stderr, err = runCLICommand(t, []string{"budget", "--format", "json"})
+	if err != nil {
+		t.Fatalf("budget failed: %v\nstderr=%s", err, stderr)
+	}
+	var status types.BudgetResult
+	if err := json.Unmarshal([]byte(stdout), &status); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout)
+	}
+	if !status.Enabled || status.Profile != "default" || len(status.Windows) != 3 {
+		t.Fatalf("unexpected budget: %+v", status)
+	}
+	if hour := status.Windows[1]; hour.Window != "hour" || hour.Used != 2 || hour.Remaining != 3 || hour.ResetsAt == nil {
+		t.Errorf("unexpected hour window: %+v", hour)
+	}
+	if day := status.Windows[2]; day.Limit != 0 || day.Used != 2 {
+		t.Errorf("unexpected day window: %+v", day)
+	}
+}
+
+func TestBudget_RejectsNegativeLimits(t *testing.T) {
+	t.Setenv("FM_BUDGET_PER_DAY", "-1")
+
+	_, stderr, err := runCLICommand(t, []string{"budget"})
+	if err == nil || !strings.Contains(stderr, "config_error") {
+		t.Errorf("expected config_error, got err=%v stderr=%s", err, stderr)
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+var correspondentsCmd = &cobra.Command{
+	Use:   "correspondents",
+	Short: "Show who you correspond with and who is owed a reply",
+	Long: `Join the Inbox (or --mailbox) with the Sent mailbox by thread and report,
+per correspondent: messages received from them, messages sent to them, last
+contact, and the median time you took to reply. Threads whose latest message
+is from them and has had no reply for --older-than are listed as unanswered.
+
+Mail with List-Id or List-Unsubscribe headers (newsletters and mailing lists)
+is ignored. Correspondents owed the most replies are listed first.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		limit, _ := cmd.Flags().GetInt("limit")
+		owed, _ := cmd.Flags().GetBool("owed")
+
+		if limit < 0 {
+			return exitError("general_error", "--limit must not be negative", "")
+		}
+		olderThanStr, _ := cmd.Flags().GetString("older-than")
+		olderThan, err := parseAge(olderThanStr)
+		if err != nil {
+			return exitError("general_error", "invalid --older-than: "+err.Error(),
+				"Use a number of days or weeks (e.g. 3d, 2w) or a duration (e.g. 36h)")
+		}
+
+		now := time.Now().UTC()
+		after := now.AddDate(0, 0, -90)
+		if s, _ := cmd.Flags().GetString("after"); strings.TrimSpace(s) != "" {
+			t, err := parseDate(strings.TrimSpace(s))
+			if err != nil {
+				return exitError("general_error", "invalid --after date: "+err.Error(),
+					"Use RFC 3339 format (e.g. 2026-01-15T00:00:00Z) or a bare date (e.g. 2026-01-15)")
+			}
+			after = t
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		mailboxID, err := c.ResolveMailboxID(mailboxName)
+		if err != nil {
+			return mailboxError(err, "not_found")
+		}
+
+		result, err := c.Correspondents(client.CorrespondentsOptions{
+			MailboxID: string(mailboxID),
+			After:     after,
+			OlderThan: olderThan,
+			Now:       now,
+			OwedOnly:  owed,
+			Limit:     limit,
+		})
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				return exitError("not_found", err.Error(), "")
+			}
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	correspondentsCmd.Flags().StringP("mailbox", "m", "inbox", "mailbox of received mail, joined with Sent")
+	correspondentsCmd.Flags().String("after", "", "only mail received at or after this date (default 90 days ago)")
+	correspondentsCmd.Flags().String("older-than", "3d", "age after which a thread without a reply is unanswered")
+	correspondentsCmd.Flags().Bool("owed", false, "only correspondents with unanswered threads")
+	correspondentsCmd.Flags().IntP("limit", "l", 20, "number of correspondents to show (0 for all)")
+	rootCmd.AddCommand(correspondentsCmd)
+}
@@ -18,11 +18,20 @@ var draftCmd = &cobra.Command{
 	Short: "Create a draft email in the Drafts mailbox",
 	Long: `Create a draft email for later review and sending from Fastmail.
 
-Supports four composition modes:
+Supports five composition modes:
   - New message: provide --to, --subject, and --body/--body-stdin
   - Reply: provide --reply-to <email-id> and --body/--body-stdin
   - Reply-all: provide --reply-all <email-id> and --body/--body-stdin
   - Forward: provide --forward <email-id>, --to, and --body/--body-stdin
+  - Edit: provide --edit <draft-id> and any fields to change
+
+Editing creates the revised draft and moves the previous one out of Drafts
+into the "Draft Revisions" mailbox; nothing is deleted. Only emails with the
+$draft keyword can be edited. Fields that are not given are carried over,
+and attachments are always kept. --html and --markdown need a new body.
+
+Use --markdown to write the body in Markdown: it is converted to sanitized
+HTML and the draft carries both the HTML and a plain-text rendition.
 
 The draft is placed in the Drafts mailbox with $draft and $seen keywords.
 It is NOT sent. Review and send from Fastmail.`,
@@ -33,13 +42,21 @@ It is NOT sent. Review and send from Fastmail.`,
 			return exitError("general_error", err.Error(), "")
 		}
 
-		body, err := readDraftBody(cmd)
+		body, err := readDraftBody(cmd, mode == client.DraftModeEdit)
 		if err != nil {
 			return exitError("general_error", err.Error(), "")
 		}
 
 		subject, _ := cmd.Flags().GetString("subject")
 		html, _ := cmd.Flags().GetBool("html")
+		markdown, _ := cmd.Flags().GetBool("markdown")
+		if html && markdown {
+			return exitError("general_error", "--html and --markdown are mutually exclusive", "")
+		}
+		if mode == client.DraftModeEdit && body == "" && (html || markdown) {
+			return exitError("general_error", "--html and --markdown apply to a new body",
+				"Provide --body or --body-stdin to change the draft's format")
+		}
 
 		to, err := parseAddressFlag(cmd, "to")
 		if err != nil {
@@ -66,6 +83,8 @@ It is NOT sent. Review and send from Fastmail.`,
 			originalID, _ = cmd.Flags().GetString("reply-all")
 		case client.DraftModeForward:
 			originalID, _ = cmd.Flags().GetString("forward")
+		case client.DraftModeEdit:
+			originalID, _ = cmd.Flags().GetString("edit")
 		}
 
 		c, err := newClient()
@@ -82,11 +101,16 @@ It is NOT sent. Review and send from Fastmail.`,
 			Subject:    subject,
 			Body:       body,
 			HTML:       html,
+			Markdown:   markdown,
 			OriginalID: originalID,
 		})
 		if err != nil {
-			if _, ok := err.(*client.ErrForbidden); ok {
-				return exitError("forbidden_operation", err.Error(), "")
+			if code := clientErrorCode(err, ""); code != "" {
+				hint := ""
+				if c.BudgetExceeded() {
+					hint = "Run 'fm budget' to see the remaining allowance"
+				}
+				return exitError(code, err.Error(), hint)
 			}
 			if strings.Contains(err.Error(), "not found") {
 				return exitError("not_found", err.Error(), "")
@@ -94,7 +118,17 @@ It is NOT sent. Review and send from Fastmail.`,
 			return exitError("jmap_error", err.Error(), "")
 		}
 
-		return formatter().Format(os.Stdout, result)
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if result.ArchiveError != "" {
+			return exitError("partial_failure",
+				"revised draft created but the previous draft could not be archived: "+result.ArchiveError,
+				"The previous draft is still in Drafts; move it manually with 'fm move'")
+		}
+
+		return nil
 	},
 }
 
@@ -108,7 +142,9 @@ func init() {
 	draftCmd.Flags().String("reply-to", "", "email ID to reply to")
 	draftCmd.Flags().String("reply-all", "", "email ID to reply-all to")
 	draftCmd.Flags().String("forward", "", "email ID to forward")
+	draftCmd.Flags().String("edit", "", "draft ID to revise (previous draft is archived, not deleted)")
 	draftCmd.Flags().Bool("html", false, "treat body as HTML")
+	draftCmd.Flags().Bool("markdown", false, "render body from Markdown into HTML plus plain text")
 
 	rootCmd.AddCommand(draftCmd)
 }
@@ -118,6 +154,7 @@ func determineDraftMode(cmd *cobra.Command) (client.DraftMode, error) {
 	replyTo, _ := cmd.Flags().GetString("reply-to")
 	replyAll, _ := cmd.Flags().GetString("reply-all")
 	forward, _ := cmd.Flags().GetString("forward")
+	edit, _ := cmd.Flags().GetString("edit")
 
 	count := 0
 	if replyTo != "" {
@@ -129,8 +166,11 @@ func determineDraftMode(cmd *cobra.Command) (client.DraftMode, error) {
 	if forward != "" {
 		count++
 	}
+	if edit != "" {
+		count++
+	}
 	if count > 1 {
-		return "", fmt.Errorf("--reply-to, --reply-all, and --forward are mutually exclusive")
+		return "", fmt.Errorf("--reply-to, --reply-all, --forward, and --edit are mutually exclusive")
 	}
 
 	switch {
@@ -140,20 +180,24 @@ func determineDraftMode(cmd *cobra.Command) (client.DraftMode, error) {
 		return client.DraftModeReplyAll, nil
 	case forward != "":
 		return client.DraftModeForward, nil
+	case edit != "":
+		return client.DraftModeEdit, nil
 	default:
 		return client.DraftModeNew, nil
 	}
 }
 
 // readDraftBody reads the body from either --body or --body-stdin.
-func readDraftBody(cmd *cobra.Command) (string, error) {
+// When optional is true (editing a draft), neither flag is required and an
+// empty body means the existing body is kept.
+func readDraftBody(cmd *cobra.Command, optional bool) (string, error) {
 	bodyStr, _ := cmd.Flags().GetString("body")
 	bodyStdin, _ := cmd.Flags().GetBool("body-stdin")
 
 	if bodyStr != "" && bodyStdin {
 		return "", fmt.Errorf("--body and --body-stdin are mutually exclusive")
 	}
-	if bodyStr == "" && !bodyStdin {
+	if bodyStr == "" && !bodyStdin && !optional {
 		return "", fmt.Errorf("either --body or --body-stdin is required")
 	}
 
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var draftsCmd = &cobra.Command{
+	Use:   "drafts",
+	Short: "List existing drafts",
+	Long: `Inspect drafts saved in the Drafts mailbox.
+
+Use 'drafts list' to find a draft ID, then 'fm draft --edit <id>' to revise
+it without creating a duplicate.`,
+}
+
+var draftsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List drafts in the Drafts mailbox",
+	Long: `List emails in the Drafts mailbox that carry the $draft keyword, newest
+first.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetUint64("limit")
+		if limit == 0 {
+			return exitError("general_error", "--limit must be at least 1", "")
+		}
+		offset, _ := cmd.Flags().GetInt64("offset")
+		if offset < 0 {
+			return exitError("general_error", "--offset must be non-negative", "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		result, err := c.ListDrafts(limit, offset)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	draftsListCmd.Flags().Uint64P("limit", "l", 25, "maximum number of results")
+	draftsListCmd.Flags().Int64P("offset", "o", 0, "pagination offset")
+	draftsCmd.AddCommand(draftsListCmd)
+	rootCmd.AddCommand(draftsCmd)
+}
@@ -13,6 +13,12 @@ func dryRunPreview(c *client.Client, ids []string, operation string, dest *types
 		return exitError("jmap_error", err.Error(), "")
 	}
 
+	destination := ""
+	if dest != nil {
+		destination = dest.ID
+	}
+	auditDryRun(ids, destination)
+
 	result := types.DryRunResult{
 		Operation:   operation,
 		Count:       len(summaries),
@@ -207,9 +207,51 @@ func resetCommandFlags(cmd *cobra.Command) {
 	}
 }
 
+// commandFlags holds the flags registered on a command itself.
+type commandFlags struct {
+	local, persistent []*pflag.Flag
+}
+
+var (
+	registeredFlagsOnce sync.Once
+	registeredFlags     = make(map[*cobra.Command]commandFlags)
+)
+
+// recordCommandFlags records the flags registered on cmd and its
+// subcommands, before any run merges in their parents' persistent flags.
+func recordCommandFlags(cmd *cobra.Command) {
+	var fl commandFlags
+	cmd.Flags().VisitAll(func(f *pflag.Flag) { fl.local = append(fl.local, f) })
+	cmd.PersistentFlags().VisitAll(func(f *pflag.Flag) { fl.persistent = append(fl.persistent, f) })
+	registeredFlags[cmd] = fl
+	for _, sub := range cmd.Commands() {
+		recordCommandFlags(sub)
+	}
+}
+
+// restoreCommandFlags undoes the merge cobra makes when it runs a command,
+// which adds every inherited global flag to the command's own flag set, so
+// a test that runs a command leaves cmd.Flags() as it was registered.
+func restoreCommandFlags(cmd *cobra.Command) {
+	if fl, ok := registeredFlags[cmd]; ok {
+		cmd.ResetFlags()
+		for _, f := range fl.persistent {
+			cmd.PersistentFlags().AddFlag(f)
+		}
+		for _, f := range fl.local {
+			cmd.Flags().AddFlag(f)
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		restoreCommandFlags(sub)
+	}
+}
+
 func runCLICommand(t *testing.T, args []string) (stdout string, stderr string, err error) {
 	t.Helper()
 
+	registeredFlagsOnce.Do(func() { recordCommandFlags(rootCmd) })
+	defer restoreCommandFlags(rootCmd)
 	resetCommandFlags(rootCmd)
 
 	oldStdout := os.Stdout
@@ -525,3 +567,59 @@ func TestMoveWithFilters_StillRequiresToFlag(t *testing.T) {
 		t.Fatalf("expected 'required flag' message, got: %s", stderr)
 	}
 }
+
+func TestVacationSetDryRun_DoesNotContactServer(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL,
+		"vacation", "set", "--dry-run", "--subject", "Away", "--body", "Back soon.")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+
+	if !strings.Contains(stdout, `"operation": "set"`) {
+		t.Fatalf("expected dry-run operation in stdout, got: %s", stdout)
+	}
+	if !strings.Contains(stdout, `"is_enabled": true`) {
+		t.Fatalf("expected previewed configuration in stdout, got: %s", stdout)
+	}
+	if server.count("VacationResponse/set") != 0 {
+		t.Fatalf("expected VacationResponse/set not to be called, got %d", server.count("VacationResponse/set"))
+	}
+}
+
+func TestReadOnly_RefusesMutationWithoutCallingServer(t *testing.T) {
+	server := newJMAPMockServer(t, nil, []map[string]any{{"id": "M1", "threadId": "T1"}}, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "--read-only", "mark-read", "M1")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err == nil {
+		t.Fatalf("expected read-only mark-read to fail\nstdout=%s", stdout)
+	}
+	if !strings.Contains(stderr, "forbidden_operation") {
+		t.Errorf("expected forbidden_operation, got stderr=%s", stderr)
+	}
+	if got := server.count("Email/set"); got != 0 {
+		t.Errorf("expected no Email/set calls, got %d", got)
+	}
+
+	// Previews still work.
+	args = commandArgsForServer(t, server.server.URL, "--read-only", "mark-read", "M1", "--dry-run")
+	if _, stderr, err := runCLICommand(t, args); err != nil {
+		t.Errorf("expected dry run to work in read-only mode: %v\nstderr=%s", err, stderr)
+	}
+}
+
+func TestReadOnly_EnvSettingReportedBySession(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	t.Setenv("FM_READ_ONLY", "true")
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "session"))
+	if err != nil {
+		t.Fatalf("session failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"read_only": true`) {
+		t.Errorf("expected read_only in session output, got: %s", stdout)
+	}
+}
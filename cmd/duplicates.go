@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var duplicatesCmd = &cobra.Command{
+	Use:   "duplicates",
+	Short: "Find duplicate copies of emails across mailboxes",
+	Long: `Find emails that are separate copies of the same message, such as those left
+by imports and forwarding loops, across the account (or one mailbox), and
+report them in groups. Copies match on Message-ID; emails without one match
+on size, sent time, sender, and subject. Emails only in Trash are ignored.
+
+In each group the earliest-received copy is kept. With --move, every other
+copy in the groups shown is moved to the top-level Duplicates folder for
+review, which is created on first use; add --limit 0 to move them all.
+Nothing is ever deleted. Preview the move with --move --dry-run first.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		limit, _ := cmd.Flags().GetInt("limit")
+		move, _ := cmd.Flags().GetBool("move")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if limit < 0 {
+			return exitError("general_error", "--limit must not be negative", "")
+		}
+		if dryRun && !move {
+			return exitError("general_error", "--dry-run only applies with --move",
+				"Run without --move to report duplicates, or add --move to preview moving them")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		opts := client.DuplicateOptions{Limit: limit}
+		if strings.TrimSpace(mailboxName) != "" {
+			mailboxID, err := c.ResolveMailboxID(strings.TrimSpace(mailboxName))
+			if err != nil {
+				return mailboxError(err, "not_found")
+			}
+			opts.MailboxID = string(mailboxID)
+		}
+
+		result, err := c.FindDuplicates(opts)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		if !move {
+			return formatter().Format(os.Stdout, result)
+		}
+
+		if dryRun {
+			dest := &types.DestinationInfo{Name: client.DuplicatesMailboxName, Path: client.DuplicatesMailboxName}
+			if mb, err := c.TopLevelMailbox(client.DuplicatesMailboxName); err == nil {
+				dest = c.Destination(mb)
+			}
+			return dryRunPreview(c, result.DuplicateIDs, "duplicates", dest)
+		}
+
+		if len(result.DuplicateIDs) > 0 {
+			reviewMB, err := c.EnsureMailbox(client.DuplicatesMailboxName)
+			if err != nil {
+				return exitError(clientErrorCode(err, "jmap_error"), err.Error(), "")
+			}
+
+			succeeded, errs := c.MoveEmails(result.DuplicateIDs, reviewMB.ID)
+			result.Moved = succeeded
+			result.Failed = len(errs)
+			result.Errors = errs
+			result.Destination = c.Destination(reviewMB)
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if result.Failed > 0 {
+			return batchFailure(c, "one or more duplicates failed to move")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	duplicatesCmd.Flags().StringP("mailbox", "m", "", "only look in one mailbox (default: the whole account)")
+	duplicatesCmd.Flags().IntP("limit", "l", 20, "number of groups to show, most copies first (0 for all)")
+	duplicatesCmd.Flags().Bool("move", false, "move all but one copy of each email in the groups shown to the Duplicates folder")
+	duplicatesCmd.Flags().BoolP("dry-run", "n", false, "with --move, preview the emails that would move without moving them")
+	rootCmd.AddCommand(duplicatesCmd)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var duplicateMailboxes = []map[string]any{
+	{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+	{"id": "mb-archive", "name": "Archive", "role": "archive"},
+	{"id": "mb-dup", "name": "Duplicates"},
+}
+
+var duplicateEmails = []map[string]any{
+	{
+		"id":         "M1",
+		"messageId":  []string{"a@example.com"},
+		"mailboxIds": map[string]bool{"mb-archive": true},
+		"from":       []map[string]any{{"email": "billing@example.com"}},
+		"subject":    "Invoice",
+		"receivedAt": "2026-03-01T09:00:00Z",
+		"size":       2048,
+	},
+	{
+		"id":         "M2",
+		"messageId":  []string{"a@example.com"},
+		"mailboxIds": map[string]bool{"mb-inbox": true},
+		"from":       []map[string]any{{"email": "billing@example.com"}},
+		"subject":    "Invoice",
+		"receivedAt": "2026-03-02T09:00:00Z",
+		"size":       2048,
+	},
+}
+
+func TestDuplicates_ReportsGroupsWithoutMoving(t *testing.T) {
+	server := newJMAPMockServer(t, duplicateMailboxes, duplicateEmails, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "duplicates")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	for _, want := range []string{`"group_count": 1`, `"matched_by": "message_id"`, `"duplicate_ids": [`, `"M2"`} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %s in stdout, got: %s", want, stdout)
+		}
+	}
+	if server.count("Email/set") != 0 {
+		t.Errorf("expected Email/set not to be called, got %d", server.count("Email/set"))
+	}
+}
+
+func TestDuplicates_MoveDryRunDoesNotMove(t *testing.T) {
+	server := newJMAPMockServer(t, duplicateMailboxes, duplicateEmails, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "duplicates", "--move", "--dry-run")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"operation": "duplicates"`) || !strings.Contains(stdout, `"path": "Duplicates"`) {
+		t.Errorf("expected duplicates dry-run preview, got: %s", stdout)
+	}
+	if server.count("Email/set") != 0 {
+		t.Errorf("expected Email/set not to be called, got %d", server.count("Email/set"))
+	}
+}
+
+func TestDuplicates_MoveMovesExtraCopies(t *testing.T) {
+	t.Setenv("FM_BUDGET_FILE", filepath.Join(t.TempDir(), "budget.json"))
+	server := newJMAPMockServer(t, duplicateMailboxes, duplicateEmails, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "duplicates", "--move")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"moved": [`) || !strings.Contains(stdout, `"id": "mb-dup"`) {
+		t.Errorf("expected M2 moved to Duplicates, got: %s", stdout)
+	}
+	if server.count("Email/set") != 1 {
+		t.Errorf("expected Email/set once, got %d", server.count("Email/set"))
+	}
+}
+
+func TestDuplicates_DryRunRequiresMove(t *testing.T) {
+	args := commandArgsForServer(t, "http://127.0.0.1:0", "duplicates", "--dry-run")
+	_, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected ErrSilent, got: %v", err)
+	}
+	if !strings.Contains(stderr, "--dry-run only applies with --move") {
+		t.Errorf("expected --move hint, got: %s", stderr)
+	}
+}
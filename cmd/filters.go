@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -118,7 +120,7 @@ func parseFilterOptions(cmd *cobra.Command, c *client.Client) (client.SearchOpti
 		mailboxName = strings.TrimSpace(mailboxName)
 		mailboxID, err := c.ResolveMailboxID(mailboxName)
 		if err != nil {
-			return client.SearchOptions{}, exitError("not_found", err.Error(), "")
+			return client.SearchOptions{}, mailboxError(err, "not_found")
 		}
 		opts.MailboxID = string(mailboxID)
 	}
@@ -187,3 +189,23 @@ func parseDate(s string) (time.Time, error) {
 	}
 	return time.Time{}, err
 }
+
+// parseAge parses an age such as "3d", "2w", or "36h". Days and weeks are
+// 24 and 168 hours; other units are those of time.ParseDuration.
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			days, err := strconv.ParseFloat(n, 64)
+			if err != nil || days < 0 {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(days * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -100,3 +101,24 @@ func TestParseFilterOptions_RecipientToFilterStillWorks(t *testing.T) {
 		t.Fatalf("expected To=bob@example.com, got %q", opts.To)
 	}
 }
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"3d":   72 * time.Hour,
+		"2w":   14 * 24 * time.Hour,
+		"1.5d": 36 * time.Hour,
+		"36h":  36 * time.Hour,
+		"90m":  90 * time.Minute,
+	}
+	for in, want := range tests {
+		got, err := parseAge(in)
+		if err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "3", "soon", "-2d", "xd"} {
+		if _, err := parseAge(in); err == nil {
+			t.Errorf("parseAge(%q) succeeded", in)
+		}
+	}
+}
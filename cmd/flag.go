@@ -68,7 +68,7 @@ var flagCmd = &cobra.Command{
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to flag", "")
+			return batchFailure(c, "one or more emails failed to flag")
 		}
 
 		return nil
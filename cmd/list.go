@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/cboone/fm/internal/classify"
 	"github.com/cboone/fm/internal/client"
 )
 
@@ -35,6 +36,10 @@ var listCmd = &cobra.Command{
 		if err != nil {
 			return exitError("general_error", err.Error(), "Supported sort fields: receivedAt, sentAt, from, subject")
 		}
+		category, err := categoryFlag(cmd)
+		if err != nil {
+			return err
+		}
 
 		c, err := newClient()
 		if err != nil {
@@ -51,9 +56,10 @@ var listCmd = &cobra.Command{
 			UnflaggedOnly:   unflagged,
 			SortField:       sortField,
 			SortAsc:         sortAsc,
+			Category:        category,
 		})
 		if err != nil {
-			return exitError("jmap_error", err.Error(), "")
+			return mailboxError(err, "jmap_error")
 		}
 
 		return formatter().Format(os.Stdout, result)
@@ -68,9 +74,24 @@ func init() {
 	listCmd.Flags().BoolP("flagged", "f", false, "only show flagged messages")
 	listCmd.Flags().Bool("unflagged", false, "only show unflagged messages")
 	listCmd.Flags().StringP("sort", "s", "receivedAt desc", "sort order (receivedAt, sentAt, from, subject) with asc/desc")
+	listCmd.Flags().String("category", "", "only senders classified as newsletter, transactional, mailing_list, notification, or personal")
 	rootCmd.AddCommand(listCmd)
 }
 
+// categoryFlag returns the classify category named by --category, or ""
+// when it is unset.
+func categoryFlag(cmd *cobra.Command) (string, error) {
+	s, _ := cmd.Flags().GetString("category")
+	if strings.TrimSpace(s) == "" {
+		return "", nil
+	}
+	category, err := classify.ParseCategory(s)
+	if err != nil {
+		return "", exitError("general_error", err.Error(), "")
+	}
+	return category, nil
+}
+
 var validSortFields = map[string]string{
 	"receivedat": "receivedAt",
 	"sentat":     "sentAt",
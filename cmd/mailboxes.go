@@ -9,6 +9,12 @@ import (
 var mailboxesCmd = &cobra.Command{
 	Use:   "mailboxes",
 	Short: "List all mailboxes (folders/labels) in the account",
+	Long: `List all mailboxes (folders/labels) in the account with their full paths,
+such as "Receipts/2025". Commands that take a mailbox accept its ID, its
+full path, or its name when no other mailbox shares it.
+
+With --tree, mailboxes are nested under their parents and each one also
+shows the counts of everything below it.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c, err := newClient()
 		if err != nil {
@@ -17,6 +23,19 @@ var mailboxesCmd = &cobra.Command{
 		}
 
 		rolesOnly, _ := cmd.Flags().GetBool("roles-only")
+		tree, _ := cmd.Flags().GetBool("tree")
+		if rolesOnly && tree {
+			return exitError("general_error", "--roles-only and --tree cannot be used together", "")
+		}
+
+		if tree {
+			nodes, err := c.MailboxTree()
+			if err != nil {
+				return exitError("jmap_error", err.Error(), "")
+			}
+			return formatter().Format(os.Stdout, nodes)
+		}
+
 		mailboxes, err := c.ListMailboxes(rolesOnly)
 		if err != nil {
 			return exitError("jmap_error", err.Error(), "")
@@ -28,5 +47,6 @@ var mailboxesCmd = &cobra.Command{
 
 func init() {
 	mailboxesCmd.Flags().Bool("roles-only", false, "only show mailboxes with a defined role")
+	mailboxesCmd.Flags().Bool("tree", false, "show mailboxes nested under their parents with subtree counts")
 	rootCmd.AddCommand(mailboxesCmd)
 }
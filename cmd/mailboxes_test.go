@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var nestedMailboxes = []map[string]any{
+	{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+	{"id": "mb-receipts", "name": "Receipts", "totalEmails": 10},
+	{"id": "mb-r2025", "name": "2025", "parentId": "mb-receipts", "totalEmails": 30, "unreadEmails": 2},
+	{"id": "mb-taxes", "name": "Taxes"},
+	{"id": "mb-t2025", "name": "2025", "parentId": "mb-taxes", "totalEmails": 5},
+}
+
+var dryRunEmail = map[string]any{
+	"id":         "M1",
+	"threadId":   "T1",
+	"subject":    "Receipt",
+	"receivedAt": "2026-02-14T10:30:00Z",
+	"keywords":   map[string]bool{},
+}
+
+func TestMailboxes_IncludesPaths(t *testing.T) {
+	server := newJMAPMockServer(t, nestedMailboxes, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "mailboxes")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	for _, want := range []string{`"path": "Receipts/2025"`, `"path": "Taxes/2025"`} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %s in stdout, got: %s", want, stdout)
+		}
+	}
+}
+
+func TestMailboxes_Tree(t *testing.T) {
+	server := newJMAPMockServer(t, nestedMailboxes, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "mailboxes", "--tree")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"subtree_total_emails": 40`) {
+		t.Errorf("expected Receipts subtree total of 40, got: %s", stdout)
+	}
+	if !strings.Contains(stdout, `"children": [`) {
+		t.Errorf("expected nested children, got: %s", stdout)
+	}
+}
+
+func TestMailboxes_TreeAndRolesOnlyConflict(t *testing.T) {
+	server := newJMAPMockServer(t, nestedMailboxes, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "mailboxes", "--tree", "--roles-only")
+	_, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected ErrSilent, got: %v", err)
+	}
+	if !strings.Contains(stderr, "cannot be used together") {
+		t.Errorf("expected conflict error, got: %s", stderr)
+	}
+}
+
+func TestMove_ToFullPath(t *testing.T) {
+	server := newJMAPMockServer(t, nestedMailboxes, []map[string]any{dryRunEmail}, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "move", "--dry-run", "M1", "--to", "Taxes/2025")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"id": "mb-t2025"`) || !strings.Contains(stdout, `"path": "Taxes/2025"`) {
+		t.Errorf("expected Taxes/2025 destination, got: %s", stdout)
+	}
+}
+
+func TestMove_AmbiguousNameListsCandidates(t *testing.T) {
+	server := newJMAPMockServer(t, nestedMailboxes, []map[string]any{dryRunEmail}, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "move", "--dry-run", "M1", "--to", "2025")
+	_, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected ErrSilent, got: %v", err)
+	}
+	if !strings.Contains(stderr, `"error": "ambiguous_mailbox"`) {
+		t.Errorf("expected ambiguous_mailbox error, got: %s", stderr)
+	}
+	for _, want := range []string{"Receipts/2025", "Taxes/2025"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("expected candidate %s in error, got: %s", want, stderr)
+		}
+	}
+	if server.count("Email/get") != 0 {
+		t.Errorf("expected Email/get not to run, got %d", server.count("Email/get"))
+	}
+}
+
+func TestMailboxFlag_AmbiguousName(t *testing.T) {
+	for _, command := range [][]string{
+		{"list", "--mailbox", "2025"},
+		{"search", "--mailbox", "2025"},
+		{"stats", "--mailbox", "2025"},
+	} {
+		t.Run(command[0], func(t *testing.T) {
+			server := newJMAPMockServer(t, nestedMailboxes, []map[string]any{dryRunEmail}, nil)
+
+			args := commandArgsForServer(t, server.server.URL, command...)
+			_, stderr, err := runCLICommand(t, args)
+			if !errors.Is(err, ErrSilent) {
+				t.Fatalf("expected ErrSilent, got: %v", err)
+			}
+			if !strings.Contains(stderr, `"error": "ambiguous_mailbox"`) {
+				t.Errorf("expected ambiguous_mailbox error, got: %s", stderr)
+			}
+		})
+	}
+}
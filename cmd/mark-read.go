@@ -48,7 +48,7 @@ var markReadCmd = &cobra.Command{
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to mark as read", "")
+			return batchFailure(c, "one or more emails failed to mark as read")
 		}
 
 		return nil
@@ -12,7 +12,7 @@ import (
 var moveCmd = &cobra.Command{
 	Use:   "move [email-id...] --to <mailbox>",
 	Short: "Move emails to a specified mailbox",
-	Long: `Move one or more emails to a target mailbox (by name or ID).
+	Long: `Move one or more emails to a target mailbox (by full path, name, or ID).
 Moving to Trash or Deleted Items is not permitted.`,
 	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,7 +34,7 @@ Moving to Trash or Deleted Items is not permitted.`,
 
 		targetMB, err := c.GetMailboxByNameOrID(target)
 		if err != nil {
-			return exitError("not_found", err.Error(), "")
+			return mailboxError(err, "not_found")
 		}
 
 		// Safety check: refuse to move to trash.
@@ -50,24 +50,18 @@ Moving to Trash or Deleted Items is not permitted.`,
 
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		if dryRun {
-			return dryRunPreview(c, ids, "move", &types.DestinationInfo{
-				ID:   string(targetMB.ID),
-				Name: targetMB.Name,
-			})
+			return dryRunPreview(c, ids, "move", c.Destination(targetMB))
 		}
 
 		succeeded, errors := c.MoveEmails(ids, targetMB.ID)
 
 		result := types.MoveResult{
-			Matched:   len(ids),
-			Processed: len(succeeded) + len(errors),
-			Failed:    len(errors),
-			Moved:     succeeded,
-			Errors:    errors,
-			Destination: &types.DestinationInfo{
-				ID:   string(targetMB.ID),
-				Name: targetMB.Name,
-			},
+			Matched:     len(ids),
+			Processed:   len(succeeded) + len(errors),
+			Failed:      len(errors),
+			Moved:       succeeded,
+			Errors:      errors,
+			Destination: c.Destination(targetMB),
 		}
 
 		if err := formatter().Format(os.Stdout, result); err != nil {
@@ -75,7 +69,7 @@ Moving to Trash or Deleted Items is not permitted.`,
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to move", "")
+			return batchFailure(c, "one or more emails failed to move")
 		}
 
 		return nil
@@ -83,7 +77,7 @@ Moving to Trash or Deleted Items is not permitted.`,
 }
 
 func init() {
-	moveCmd.Flags().String("to", "", "target mailbox name or ID (required)")
+	moveCmd.Flags().String("to", "", "target mailbox path, name, or ID (required)")
 	moveCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
 	addFilterFlags(moveCmd)
 	rootCmd.AddCommand(moveCmd)
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage named configuration profiles",
+	Long: `Named profiles let one config file hold settings for several accounts:
+
+  profile: personal            # used when --profile is not given
+  profiles:
+    personal:
+      token_command: pass show fastmail/personal
+    team:
+      token_command: pass show fastmail/team
+      account_id: u1234abcd
+      format: text
+
+Select a profile with --profile or FM_PROFILE. A profile's settings replace
+the top-level settings of the same name; flags and environment variables
+still take precedence. A profile with its own token, token_command, keyring,
+or credentials_file never falls back to the top-level token or
+token_command.`,
+}
+
+var profilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured profiles",
+	Long: `List the profiles defined in the config file, marking the active one.
+Tokens are never shown; only whether the profile uses token or
+token_command.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return formatter().Format(os.Stdout, listProfiles())
+	},
+}
+
+func init() {
+	profilesCmd.AddCommand(profilesListCmd)
+	rootCmd.AddCommand(profilesCmd)
+}
+
+// applyProfile merges the named profile's settings over the top-level config
+// file settings. Flags and environment variables keep their precedence
+// because viper consults them before the config file.
+func applyProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	key := strings.ToLower(name)
+	settings := configuredProfiles()[key]
+	if settings == nil {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+
+	overrides := make(map[string]any)
+	for k, v := range settings.AllSettings() {
+		if k == "profile" || k == "profiles" {
+			continue
+		}
+		overrides[k] = v
+	}
+	// A top-level token belongs to another account. A profile that picks
+	// its own token source never falls back to it; flag and environment
+	// tokens still win, since viper reads them before the config file.
+	if profileSetsTokenSource(settings) {
+		for _, k := range []string{"token", "token_command"} {
+			if _, ok := overrides[k]; !ok {
+				overrides[k] = ""
+			}
+		}
+	} else {
+		profileInheritsToken = true
+	}
+	if err := viper.MergeConfigMap(overrides); err != nil {
+		return fmt.Errorf("applying profile %q: %w", name, err)
+	}
+
+	activeProfile = key
+	return nil
+}
+
+// profileSetsTokenSource reports whether a profile chooses where its token
+// comes from: a token, a token_command, or its own credential store.
+func profileSetsTokenSource(settings *viper.Viper) bool {
+	for _, k := range []string{"token", "token_command", "keyring", "credentials_file"} {
+		if settings.IsSet(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// configuredProfiles returns each profile's settings keyed by lowercased
+// profile name (viper lowercases keys). Entries that are not maps are skipped.
+func configuredProfiles() map[string]*viper.Viper {
+	profiles := make(map[string]*viper.Viper)
+	for name := range viper.GetStringMap("profiles") {
+		if settings := viper.Sub("profiles." + name); settings != nil {
+			profiles[name] = settings
+		}
+	}
+	return profiles
+}
+
+// listProfiles describes the configured profiles without their tokens.
+func listProfiles() types.ProfileListResult {
+	result := types.ProfileListResult{
+		Active:   activeProfile,
+		Profiles: []types.ProfileInfo{},
+	}
+	for name, settings := range configuredProfiles() {
+		info := types.ProfileInfo{
+			Name:        name,
+			Active:      name == activeProfile,
+			TokenSource: "none",
+			SessionURL:  settings.GetString("session_url"),
+			AccountID:   settings.GetString("account_id"),
+			Format:      settings.GetString("format"),
+		}
+		switch {
+		case settings.GetString("token") != "":
+			info.TokenSource = "token"
+		case settings.GetString("token_command") != "":
+			info.TokenSource = "token_command"
+		}
+		result.Profiles = append(result.Profiles, info)
+	}
+	sort.Slice(result.Profiles, func(i, j int) bool {
+		return result.Profiles[i].Name < result.Profiles[j].Name
+	})
+	return result
+}
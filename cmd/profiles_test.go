@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/credentials"
+	"github.com/cboone/fm/internal/types"
+)
+
+func writeProfileConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestProfile_AppliesSettingsAndReportsName(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	config := writeProfileConfig(t, `
+session_url: http://127.0.0.1:1/unused
+profiles:
+  team:
+    token_command: printf team-token
+    session_url: `+server.server.URL+`/session
+    account_id: A1
+`)
+
+	stdout, stderr, err := runCLICommand(t, []string{"--config", config, "--profile", "Team", "session"})
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+
+	var info types.SessionInfo
+	if err := json.Unmarshal([]byte(stdout), &info); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if info.Profile != "team" {
+		t.Errorf("profile = %q, want %q", info.Profile, "team")
+	}
+	if info.Username != "test@example.com" {
+		t.Errorf("username = %q, want session from profile's server", info.Username)
+	}
+}
+
+func TestProfile_DefaultFromConfigAndFlagsTakePrecedence(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	config := writeProfileConfig(t, `
+profile: personal
+profiles:
+  personal:
+    token: personal-token
+    session_url: http://127.0.0.1:1/unreachable
+`)
+
+	args := []string{"--config", config, "--session-url", server.server.URL + "/session", "session"}
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected --session-url to override the profile, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"profile": "personal"`) {
+		t.Errorf("expected default profile in output, got: %s", stdout)
+	}
+}
+
+func TestProfile_UnknownProfile(t *testing.T) {
+	config := writeProfileConfig(t, "profiles:\n  team:\n    token: x\n")
+
+	_, stderr, err := runCLICommand(t, []string{"--config", config, "--profile", "nope", "session"})
+	if err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+	if !strings.Contains(stderr, "config_error") || !strings.Contains(stderr, `unknown profile \"nope\"`) {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+}
+
+func TestProfilesList_HidesTokens(t *testing.T) {
+	config := writeProfileConfig(t, `
+profiles:
+  personal:
+    token: secret-personal-token
+  team:
+    token_command: pass show team
+    account_id: u123
+    format: text
+`)
+
+	stdout, stderr, err := runCLICommand(t, []string{"--config", config, "--profile", "team", "--format", "json", "profiles", "list"})
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if strings.Contains(stdout, "secret-personal-token") || strings.Contains(stdout, "pass show team") {
+		t.Fatalf("token material leaked into output: %s", stdout)
+	}
+
+	var result types.ProfileListResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Active != "team" || len(result.Profiles) != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	personal, team := result.Profiles[0], result.Profiles[1]
+	if personal.Name != "personal" || personal.TokenSource != "token" || personal.Active {
+		t.Errorf("unexpected personal profile: %+v", personal)
+	}
+	if team.Name != "team" || team.TokenSource != "token_command" || !team.Active || team.AccountID != "u123" {
+		t.Errorf("unexpected team profile: %+v", team)
+	}
+}
+
+func TestResolveToken_TokenCommandFailure(t *testing.T) {
+	config := writeProfileConfig(t, "token_command: echo nope >&2; exit 1\n")
+
+	_, stderr, err := runCLICommand(t, []string{"--config", config, "session"})
+	if err == nil {
+		t.Fatal("expected error when token_command fails")
+	}
+	if !strings.Contains(stderr, "token_command failed: nope") {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+}
+
+func TestProfile_TokenCommandBeatsTopLevelToken(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	t.Setenv("FM_TOKEN", "")
+	config := writeProfileConfig(t, `
+keyring: off
+token: personal-token
+session_url: `+server.server.URL+`/session
+profiles:
+  team:
+    token_command: echo team-token
+`)
+
+	stdout, stderr, err := runCLICommand(t, []string{"--config", config, "--profile", "team", "auth", "status"})
+	if err != nil {
+		t.Fatalf("status failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"source": "token_command"`) {
+		t.Errorf("expected the profile's token_command to be used, got: %s", stdout)
+	}
+
+	stdout, stderr, err = runCLICommand(t, []string{"--config", config, "--profile", "team", "profiles", "list"})
+	if err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"token_source": "token_command"`) {
+		t.Errorf("expected token_command source for team, got: %s", stdout)
+	}
+
+	t.Setenv("FM_TOKEN", "env-token")
+	stdout, stderr, err = runCLICommand(t, []string{"--config", config, "--profile", "team", "auth", "status"})
+	if err != nil {
+		t.Fatalf("status failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"source": "env"`) {
+		t.Errorf("expected env token to override the profile, got: %s", stdout)
+	}
+}
+
+func TestProfile_StoredTokenBeatsInheritedTopLevelToken(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	t.Setenv("FM_TOKEN", "")
+	credsPath := filepath.Join(t.TempDir(), "credentials.json")
+	store, err := credentials.Open("file", credsPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	if err := store.Set("team", "team-token"); err != nil {
+		t.Fatalf("store token: %v", err)
+	}
+	config := writeProfileConfig(t, `
+keyring: file
+credentials_file: `+credsPath+`
+token: personal-token
+session_url: `+server.server.URL+`/session
+profiles:
+  team:
+    account_id: A1
+`)
+
+	stdout, stderr, err := runCLICommand(t, []string{"--config", config, "--profile", "team", "auth", "status"})
+	if err != nil {
+		t.Fatalf("status failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"source": "keyring"`) {
+		t.Errorf("expected the profile's stored token to be used, got: %s", stdout)
+	}
+}
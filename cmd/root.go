@@ -4,23 +4,42 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/cboone/fm/internal/audit"
+	"github.com/cboone/fm/internal/budget"
 	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/credentials"
+	"github.com/cboone/fm/internal/oauth"
 	"github.com/cboone/fm/internal/output"
+	"github.com/cboone/fm/internal/snooze"
+	"github.com/cboone/fm/internal/types"
+	"github.com/cboone/fm/internal/unsubscribe"
 )
 
 // ErrSilent is returned by exitError to indicate the error has already been printed.
 var ErrSilent = errors.New("error already printed")
 
 var (
-	cfgFile       string
-	initConfigErr error
-	version       = "dev"
-	rootCmd       = &cobra.Command{
+	cfgFile        string
+	initConfigErr  error
+	initProfileErr error
+	activeProfile  string
+	// profileInheritsToken is set when the active profile has no token
+	// source of its own, so a stored token for the profile must be tried
+	// before the top-level token and token_command.
+	profileInheritsToken bool
+	auditOperation       string
+	auditCommand         string
+	version              = "dev"
+	rootCmd              = &cobra.Command{
 		Use:   "fm",
 		Short: "Fastmail Mail -- a safe, read-oriented CLI for Fastmail email via JMAP",
 		Long: `fm is a command-line tool for reading, searching, triaging, and drafting Fastmail
@@ -47,12 +66,16 @@ func init() {
 	rootCmd.PersistentFlags().String("session-url", "https://api.fastmail.com/jmap/session", "Fastmail session endpoint")
 	rootCmd.PersistentFlags().String("format", "json", "output format: json or text")
 	rootCmd.PersistentFlags().String("account-id", "", "Fastmail account ID (auto-detected if blank)")
+	rootCmd.PersistentFlags().String("profile", "", "named profile from the config file")
+	rootCmd.PersistentFlags().Bool("read-only", false, "refuse every change to the mailbox, sieve scripts, and settings")
 
 	for _, bind := range []struct{ key, flag string }{
 		{"token", "token"},
 		{"session_url", "session-url"},
 		{"format", "format"},
 		{"account_id", "account-id"},
+		{"profile", "profile"},
+		{"read_only", "read-only"},
 	} {
 		if err := viper.BindPFlag(bind.key, rootCmd.PersistentFlags().Lookup(bind.flag)); err != nil {
 			panic(fmt.Sprintf("failed to bind flag %q: %v", bind.flag, err))
@@ -63,6 +86,22 @@ func init() {
 		if initConfigErr != nil {
 			return exitError("config_error", "failed to read config: "+initConfigErr.Error(), configErrorHint())
 		}
+		if initProfileErr != nil {
+			return exitError("config_error", initProfileErr.Error(),
+				"Run 'fm profiles list' to see the configured profiles")
+		}
+		auditOperation = strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")
+		auditCommand = commandLine(cmd, args)
+		if log := auditLog(); log != nil {
+			if err := log.Check(); err != nil {
+				return exitError("config_error", "audit log: "+err.Error(),
+					"Fix the audit_log path in the config file or FM_AUDIT_LOG")
+			}
+		}
+		if _, err := budgetTracker(); err != nil {
+			return exitError("config_error", err.Error(),
+				"Fix the budget settings in the config file or FM_BUDGET_* variables")
+		}
 		format := viper.GetString("format")
 		if format != "json" && format != "text" {
 			return exitError("general_error",
@@ -75,6 +114,9 @@ func init() {
 
 func initConfig() {
 	initConfigErr = nil
+	initProfileErr = nil
+	activeProfile = ""
+	profileInheritsToken = false
 
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
@@ -93,14 +135,18 @@ func initConfig() {
 
 	viper.SetDefault("session_url", "https://api.fastmail.com/jmap/session")
 	viper.SetDefault("format", "json")
+	viper.SetDefault("keyring", "auto")
+	viper.SetDefault("oauth_scope", "urn:ietf:params:jmap:core urn:ietf:params:jmap:mail")
 
 	if err := viper.ReadInConfig(); err != nil {
 		var notFound viper.ConfigFileNotFoundError
-		if errors.As(err, &notFound) {
+		if !errors.As(err, &notFound) {
+			initConfigErr = err
 			return
 		}
-		initConfigErr = err
 	}
+
+	initProfileErr = applyProfile(viper.GetString("profile"))
 }
 
 func configErrorHint() string {
@@ -112,14 +158,326 @@ func configErrorHint() string {
 
 // newClient creates an authenticated JMAP client from the current config.
 func newClient() (*client.Client, error) {
-	token := viper.GetString("token")
-	if token == "" {
-		return nil, fmt.Errorf("no token configured; set FM_TOKEN, --token, or token in config file")
+	tokens, _, err := resolveToken()
+	if err != nil {
+		return nil, err
 	}
 	sessionURL := viper.GetString("session_url")
 	accountID := viper.GetString("account_id")
 
-	return client.New(sessionURL, token, accountID)
+	c, err := client.NewWithTokenSource(sessionURL, tokens, accountID)
+	if err != nil {
+		return nil, err
+	}
+	c.SetReadOnly(viper.GetBool("read_only"))
+	if log := auditLog(); log != nil {
+		c.SetAuditLog(log)
+	}
+	if tracker, err := budgetTracker(); err != nil {
+		return nil, err
+	} else if tracker.Limits.Enabled() {
+		c.SetBudget(tracker)
+	}
+	return c, nil
+}
+
+// auditLog returns the audit log named by the audit_log setting, or nil
+// when auditing is off.
+func auditLog() *audit.Log {
+	path := auditLogPath()
+	if path == "" {
+		return nil
+	}
+	return &audit.Log{
+		Path:      path,
+		Profile:   activeProfile,
+		Command:   auditCommand,
+		Operation: auditOperation,
+		Warnings:  os.Stderr,
+	}
+}
+
+// auditLogPath returns the audit_log setting with a leading ~/ expanded to
+// the home directory.
+func auditLogPath() string {
+	path := viper.GetString("audit_log")
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// commandLine rebuilds the command line from the parsed command, flags, and
+// arguments for the audit log. The --token value is redacted.
+func commandLine(cmd *cobra.Command, args []string) string {
+	parts := []string{cmd.CommandPath()}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		value := f.Value.String()
+		switch {
+		case f.Name == "token":
+			value = "[redacted]"
+		case f.Value.Type() == "bool" && value == "true":
+			parts = append(parts, "--"+f.Name)
+			return
+		}
+		parts = append(parts, "--"+f.Name+"="+shellQuote(value))
+	})
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes s when it holds spaces or quotes.
+func shellQuote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n'\"\\") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// auditDryRun records a dry run of the current command in the audit log,
+// when one is configured.
+func auditDryRun(ids []string, destination string) {
+	if log := auditLog(); log != nil {
+		log.Record(types.AuditEntry{IDs: ids, Destination: destination, DryRun: true})
+	}
+}
+
+// budgetTracker returns the mutation budget for the active profile from the
+// budget_per_run, budget_per_hour, and budget_per_day settings. Its state is
+// kept in budget_file, by default ~/.local/state/fm/budget.json.
+func budgetTracker() (*budget.Tracker, error) {
+	limits := budget.Limits{
+		PerRun:  viper.GetInt("budget_per_run"),
+		PerHour: viper.GetInt("budget_per_hour"),
+		PerDay:  viper.GetInt("budget_per_day"),
+	}
+	if limits.PerRun < 0 || limits.PerHour < 0 || limits.PerDay < 0 {
+		return nil, fmt.Errorf("budget limits must not be negative")
+	}
+	path, err := stateFilePath("budget_file", "budget.json")
+	if err != nil {
+		return nil, err
+	}
+	return &budget.Tracker{
+		Path:     path,
+		Account:  credentialAccount(),
+		Limits:   limits,
+		Warnings: os.Stderr,
+	}, nil
+}
+
+// unsubscribeState returns the record of past unsubscribes for the active
+// profile, kept in unsubscribe_file, by default
+// ~/.local/state/fm/unsubscribe.json.
+func unsubscribeState() (*unsubscribe.State, error) {
+	path, err := stateFilePath("unsubscribe_file", "unsubscribe.json")
+	if err != nil {
+		return nil, err
+	}
+	return &unsubscribe.State{Path: path, Account: credentialAccount()}, nil
+}
+
+// snoozeState returns the record of snoozed emails for the active profile,
+// kept in snooze_file, by default ~/.local/state/fm/snooze.json.
+func snoozeState() (*snooze.State, error) {
+	path, err := stateFilePath("snooze_file", "snooze.json")
+	if err != nil {
+		return nil, err
+	}
+	return &snooze.State{Path: path, Account: credentialAccount()}, nil
+}
+
+// stateFilePath returns the path in setting with a leading ~/ expanded, or
+// name in ~/.local/state/fm when the setting is empty.
+func stateFilePath(setting, name string) (string, error) {
+	path := viper.GetString(setting)
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("locating %s: %w", name, err)
+		}
+		return filepath.Join(home, ".local", "state", "fm", name), nil
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	return path, nil
+}
+
+// resolveToken returns the token source and where it came from: the token
+// setting (flag, env, or config), token_command, or the credential store
+// written by fm auth login or fm auth oauth, in that order. When the active
+// profile has no token source of its own, its stored token comes right
+// after flag and env tokens. Tokens stored by fm auth oauth are refreshed
+// as needed and reported as "oauth".
+func resolveToken() (tokens client.TokenSource, source string, err error) {
+	token := viper.GetString("token")
+	switch {
+	case token != "" && rootCmd.PersistentFlags().Changed("token"):
+		return client.StaticToken(token), "flag", nil
+	case token != "" && os.Getenv("FM_TOKEN") != "":
+		return client.StaticToken(token), "env", nil
+	}
+
+	// A token stored for the active profile beats top-level settings the
+	// profile merely inherits, which belong to another account.
+	if profileInheritsToken {
+		if tokens, source, ok, err := storedToken(); err != nil || ok {
+			return tokens, source, err
+		}
+	}
+
+	if token != "" {
+		return client.StaticToken(token), "config", nil
+	}
+
+	if command := viper.GetString("token_command"); command != "" {
+		token, err := runTokenCommand(command)
+		if err != nil {
+			return nil, "", err
+		}
+		return client.StaticToken(token), "token_command", nil
+	}
+
+	if tokens, source, ok, err := storedToken(); err != nil || ok {
+		return tokens, source, err
+	}
+
+	return nil, "", fmt.Errorf("no token configured; set FM_TOKEN, --token, token, or token_command in config file, or run 'fm auth login'")
+}
+
+// storedToken returns the token stored for the active profile by fm auth
+// login or fm auth oauth, if there is one.
+func storedToken() (client.TokenSource, string, bool, error) {
+	store, err := credentialStore()
+	if err != nil || store == nil {
+		return nil, "", false, err
+	}
+	account := credentialAccount()
+	token, err := store.Get(account)
+	if errors.Is(err, credentials.ErrNotFound) {
+		return nil, "", false, nil
+	}
+	if err != nil {
+		return nil, "", false, fmt.Errorf("reading stored token: %w", err)
+	}
+	if tok, ok := oauth.Decode(token); ok {
+		return oauthTokenSource(store, account, tok), "oauth", true, nil
+	}
+	return client.StaticToken(token), "keyring", true, nil
+}
+
+// runTokenCommand runs command with sh -c and returns its trimmed stdout.
+func runTokenCommand(command string) (string, error) {
+	var stderr strings.Builder
+	c := exec.Command("sh", "-c", command)
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("token_command failed: %s", msg)
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("token_command printed no token")
+	}
+	return token, nil
+}
+
+// credentialStore opens the store selected by the keyring setting, or
+// returns nil when it is off.
+func credentialStore() (credentials.Store, error) {
+	backend := viper.GetString("keyring")
+	if backend == "off" {
+		return nil, nil
+	}
+	path := viper.GetString("credentials_file")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("locating credentials file: %w", err)
+		}
+		path = filepath.Join(home, ".config", "fm", "credentials.json")
+	}
+	return credentials.Open(backend, path)
+}
+
+// credentialAccount is the name stored tokens are filed under: the active
+// profile, or "default".
+func credentialAccount() string {
+	if activeProfile != "" {
+		return activeProfile
+	}
+	return "default"
+}
+
+// accountArgs returns the global flags that select the account in use, for
+// fm commands proposed to run later: --config and --profile when in effect,
+// and --session-url and --account-id when given on the command line. The
+// token is never included.
+func accountArgs() []string {
+	var args []string
+	if cfgFile != "" {
+		args = append(args, "--config", cfgFile)
+	}
+	if activeProfile != "" {
+		args = append(args, "--profile", activeProfile)
+	}
+	for _, name := range []string{"session-url", "account-id"} {
+		if f := rootCmd.PersistentFlags().Lookup(name); f.Changed {
+			args = append(args, "--"+name, f.Value.String())
+		}
+	}
+	return args
+}
+
+// clientErrorCode returns forbidden_operation when err is a guardrail
+// refusal, such as a change attempted in read-only mode, and fallback
+// otherwise.
+func clientErrorCode(err error, fallback string) string {
+	var forbidden *client.ErrForbidden
+	if errors.As(err, &forbidden) {
+		return "forbidden_operation"
+	}
+	return fallback
+}
+
+// mailboxError is the error for a mailbox that could not be resolved. A
+// name shared by several mailboxes is reported as ambiguous_mailbox, and
+// any other failure with fallback.
+func mailboxError(err error, fallback string) error {
+	var ambiguous *client.ErrAmbiguousMailbox
+	if errors.As(err, &ambiguous) {
+		return exitError("ambiguous_mailbox", err.Error(),
+			"Pass one of the listed paths or IDs instead of the bare name")
+	}
+	return exitError(fallback, err.Error(), "")
+}
+
+// batchFailure is the error for a batch command where some items failed.
+// In read-only mode every item is refused, and once the mutation budget is
+// used up the rest are, so both are reported as forbidden_operation instead
+// of partial_failure.
+func batchFailure(c *client.Client, message string) error {
+	if c.ReadOnly() {
+		return exitError("forbidden_operation", "read-only mode is enabled; nothing was changed",
+			"Run without --read-only (or FM_READ_ONLY) to make changes")
+	}
+	if c.BudgetExceeded() {
+		return exitError("forbidden_operation", message+"; the mutation budget is used up",
+			"Run 'fm budget' to see the remaining allowance")
+	}
+	return exitError("partial_failure", message, "")
 }
 
 // formatter returns the configured output formatter.
@@ -48,6 +48,10 @@ If omitted, only the provided flags/filters are used for matching.`,
 		}
 		opts.SortField = sortField
 		opts.SortAsc = sortAsc
+		opts.Category, err = categoryFlag(cmd)
+		if err != nil {
+			return err
+		}
 
 		mailboxName, _ := cmd.Flags().GetString("mailbox")
 
@@ -78,7 +82,7 @@ If omitted, only the provided flags/filters are used for matching.`,
 		if mailboxName != "" {
 			mailboxID, err := c.ResolveMailboxID(mailboxName)
 			if err != nil {
-				return exitError("not_found", err.Error(), "")
+				return mailboxError(err, "not_found")
 			}
 			opts.MailboxID = string(mailboxID)
 		}
@@ -106,5 +110,6 @@ func init() {
 	searchCmd.Flags().String("before", "", "emails received before this date (RFC 3339 or YYYY-MM-DD)")
 	searchCmd.Flags().String("after", "", "emails received after this date (RFC 3339 or YYYY-MM-DD)")
 	searchCmd.Flags().Bool("has-attachment", false, "only emails with attachments")
+	searchCmd.Flags().String("category", "", "only senders classified as newsletter, transactional, mailing_list, notification, or personal")
 	rootCmd.AddCommand(searchCmd)
 }
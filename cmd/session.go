@@ -17,6 +17,7 @@ var sessionCmd = &cobra.Command{
 		}
 
 		info := c.SessionInfo()
+		info.Profile = activeProfile
 		return formatter().Format(os.Stdout, info)
 	},
 }
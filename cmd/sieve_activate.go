@@ -23,6 +23,7 @@ deactivates any currently active script.`,
 				Operation: "activate",
 				Script:    args[0],
 			}
+			auditDryRun([]string{args[0]}, "")
 			return formatter().Format(os.Stdout, result)
 		}
 
@@ -34,7 +35,7 @@ deactivates any currently active script.`,
 
 		result, err := c.ActivateSieveScript(args[0])
 		if err != nil {
-			return exitError("jmap_error", err.Error(), "")
+			return exitError(clientErrorCode(err, "jmap_error"), err.Error(), "")
 		}
 
 		return formatter().Format(os.Stdout, result)
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/sieve"
+)
+
+var sieveAddRuleCmd = &cobra.Command{
+	Use:   "add-rule <script-id> [flags]",
+	Short: "Append a generated rule to an existing sieve script",
+	Long: `Generate a rule from template flags and append it to an existing sieve
+script. The flags are the same as for 'fm sieve create':
+  fm sieve add-rule S1 --from-domain "shop.example" \
+    --action fileinto --fileinto Receipts
+  fm sieve add-rule S1 --list-id "announce.example.org" --mark-read -n
+
+Extensions the new rule needs are added to the script's require list. If
+the script has a top-level stop, the rule is inserted before it so that it
+can still run. The merged script is checked locally and must parse without
+errors before it is stored. Use --dry-run to preview the change as a
+unified diff.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hasTemplate := false
+		for _, name := range sieveTemplateFlags {
+			if cmd.Flags().Changed(name) {
+				hasTemplate = true
+				break
+			}
+		}
+		if !hasTemplate {
+			return exitError("general_error", "provide template flags (conditions + --action) for the new rule", "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		current, err := c.GetSieveScript(args[0])
+		if err != nil {
+			if errors.Is(err, client.ErrNotFound) || strings.Contains(err.Error(), "not found") {
+				return exitError("not_found", err.Error(), "")
+			}
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		merged, err := client.AppendSieveRule(current.Content, parseSieveTemplateOptions(cmd))
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		if diags := sieve.Check(merged, sieve.LintOptions{}); sieve.HasErrors(diags) {
+			result := sieveValidateResult(merged, diags)
+			return exitError("general_error", "merged script is not valid: "+result.Error,
+				"Run 'fm sieve validate --local' on the script for details")
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		return replaceSieveScript(c, current, merged, "add-rule", dryRun)
+	},
+}
+
+func init() {
+	addSieveTemplateFlags(sieveAddRuleCmd)
+	sieveAddRuleCmd.Flags().BoolP("dry-run", "n", false, "show a diff of the change without updating")
+	sieveCmd.AddCommand(sieveAddRuleCmd)
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// sieveManifestFile is the name of the metadata file in a backup directory.
+const sieveManifestFile = "manifest.json"
+
+// sieveManifest is the metadata written alongside backed-up script files.
+type sieveManifest struct {
+	BackedUpAt time.Time                 `json:"backed_up_at"`
+	Scripts    []types.SieveBackupScript `json:"scripts"`
+}
+
+var sieveBackupCmd = &cobra.Command{
+	Use:   "backup <dir>",
+	Short: "Save all sieve scripts to a directory",
+	Long: `Write every sieve script to <dir>, one .sieve file per script, plus a
+manifest.json recording each script's ID, name, file, and which script is
+active. The directory is created if needed; existing files with the same
+names are overwritten.
+
+Restore the backup with 'fm sieve restore <dir>'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		list, err := c.ListSieveScripts()
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		scripts := make([]types.SieveScriptDetail, 0, len(list.Scripts))
+		for _, info := range list.Scripts {
+			detail, err := c.GetSieveScript(info.ID)
+			if err != nil {
+				return exitError("jmap_error", err.Error(), "")
+			}
+			scripts = append(scripts, detail)
+		}
+
+		result, err := writeSieveBackup(args[0], scripts, time.Now().UTC())
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	sieveCmd.AddCommand(sieveBackupCmd)
+}
+
+// writeSieveBackup writes each script's content and the manifest to dir.
+func writeSieveBackup(dir string, scripts []types.SieveScriptDetail, now time.Time) (types.SieveBackupResult, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return types.SieveBackupResult{}, fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	manifest := sieveManifest{BackedUpAt: now, Scripts: []types.SieveBackupScript{}}
+	used := map[string]bool{sieveManifestFile: true}
+	for _, s := range scripts {
+		file := sieveBackupFileName(s.Name)
+		if used[strings.ToLower(file)] {
+			file = sieveBackupFileName(s.Name + "-" + s.ID)
+		}
+		used[strings.ToLower(file)] = true
+
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(s.Content), 0o644); err != nil {
+			return types.SieveBackupResult{}, fmt.Errorf("writing %s: %w", file, err)
+		}
+		manifest.Scripts = append(manifest.Scripts, types.SieveBackupScript{
+			ID:       s.ID,
+			Name:     s.Name,
+			File:     file,
+			IsActive: s.IsActive,
+		})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return types.SieveBackupResult{}, fmt.Errorf("encoding manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, sieveManifestFile), append(data, '\n'), 0o644); err != nil {
+		return types.SieveBackupResult{}, fmt.Errorf("writing %s: %w", sieveManifestFile, err)
+	}
+
+	return types.SieveBackupResult{
+		Directory: dir,
+		Total:     len(manifest.Scripts),
+		Scripts:   manifest.Scripts,
+	}, nil
+}
+
+// readSieveBackup reads the manifest in dir and checks that every script file
+// it lists is present.
+func readSieveBackup(dir string) (sieveManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, sieveManifestFile))
+	if err != nil {
+		return sieveManifest{}, fmt.Errorf("reading backup manifest: %w", err)
+	}
+
+	var manifest sieveManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return sieveManifest{}, fmt.Errorf("parsing %s: %w", filepath.Join(dir, sieveManifestFile), err)
+	}
+
+	for _, s := range manifest.Scripts {
+		if s.Name == "" || s.File == "" {
+			return sieveManifest{}, fmt.Errorf("parsing %s: script entry without name or file", filepath.Join(dir, sieveManifestFile))
+		}
+		if filepath.Base(s.File) != s.File {
+			return sieveManifest{}, fmt.Errorf("parsing %s: script file %q must be inside the backup directory", filepath.Join(dir, sieveManifestFile), s.File)
+		}
+		if _, err := os.Stat(filepath.Join(dir, s.File)); err != nil {
+			return sieveManifest{}, fmt.Errorf("script %q: %w", s.Name, err)
+		}
+	}
+
+	return manifest, nil
+}
+
+// sieveBackupFileName turns a script name into a safe file name ending in
+// .sieve.
+func sieveBackupFileName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	base := strings.Trim(b.String(), ".")
+	if base == "" {
+		base = "script"
+	}
+	return base + ".sieve"
+}
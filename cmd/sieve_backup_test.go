@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestWriteSieveBackup_RoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backup")
+	scripts := []types.SieveScriptDetail{
+		{ID: "S1", Name: "Main filters", IsActive: true, Content: "keep;\n"},
+		{ID: "S2", Name: "main/filters", Content: "discard;\n"},
+		{ID: "S3", Name: "../escape", Content: "stop;\n"},
+	}
+
+	result, err := writeSieveBackup(dir, scripts, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("writeSieveBackup() error: %v", err)
+	}
+	if result.Total != 3 {
+		t.Fatalf("Total = %d, want 3", result.Total)
+	}
+
+	wantFiles := []string{"Main_filters.sieve", "main_filters-S2.sieve", "_escape.sieve"}
+	for i, want := range wantFiles {
+		if result.Scripts[i].File != want {
+			t.Errorf("script %d file = %q, want %q", i, result.Scripts[i].File, want)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Main_filters.sieve"))
+	if err != nil || string(data) != "keep;\n" {
+		t.Errorf("script file = %q, %v; want content", data, err)
+	}
+
+	manifest, err := readSieveBackup(dir)
+	if err != nil {
+		t.Fatalf("readSieveBackup() error: %v", err)
+	}
+	if len(manifest.Scripts) != 3 || !manifest.Scripts[0].IsActive || manifest.Scripts[1].IsActive {
+		t.Errorf("manifest scripts = %+v, want active flag preserved", manifest.Scripts)
+	}
+	if manifest.Scripts[2].Name != "../escape" {
+		t.Errorf("manifest name = %q, want original name", manifest.Scripts[2].Name)
+	}
+}
+
+func TestReadSieveBackup_RejectsBadManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     string
+	}{
+		{"missing file", `{"scripts":[{"name":"A","file":"A.sieve"}]}`, `script "A"`},
+		{"path outside directory", `{"scripts":[{"name":"A","file":"../A.sieve"}]}`, "inside the backup directory"},
+		{"no name", `{"scripts":[{"file":"A.sieve"}]}`, "without name or file"},
+		{"invalid json", `{`, "parsing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, sieveManifestFile), []byte(tt.manifest), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := readSieveBackup(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("readSieveBackup() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSieveBackupFileName(t *testing.T) {
+	tests := map[string]string{
+		"Filters":     "Filters.sieve",
+		"Block spam!": "Block_spam_.sieve",
+		"...":         "script.sieve",
+		"":            "script.sieve",
+		"v1.2":        "v1.2.sieve",
+	}
+	for name, want := range tests {
+		if got := sieveBackupFileName(name); got != want {
+			t.Errorf("sieveBackupFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/sieve"
 	"github.com/cboone/fm/internal/types"
 )
 
@@ -21,6 +22,16 @@ Two modes are supported:
 Template mode (generate a script from flags):
   fm sieve create --name "Block sender" --from "spam@example.com" --action junk
   fm sieve create --name "Block domain" --from-domain "example.com" --action junk
+  fm sieve create --name "Receipts" --from-domain "shop.example" \
+    --subject-contains "receipt" --action fileinto --fileinto Receipts --mark-read
+
+Conditions (--from, --from-domain, --subject-contains, --header, --to, --cc,
+--size-over, --list-id) can be combined; all must match unless --match any
+is given. --subject-contains, --header, --to, --cc, and --addflag may be
+repeated. Flag actions (--mark-read, --addflag) may be used with or without
+--action. The required extensions are declared automatically. A rule that
+files, keeps, or discards mail ends with stop; one that only sets flags, or
+files with --copy, lets the rules after it run.
 
 Stdin mode (provide raw sieve content):
   echo 'keep;' | fm sieve create --name "Custom" --script-stdin
@@ -50,8 +61,10 @@ script deactivates the currently active one.`,
 				Script:    name,
 				Content:   content,
 			}
+			auditDryRun(nil, "")
 
-			// Optionally validate server-side during dry run.
+			// Validate server-side during dry run, falling back to the
+			// local parser when the server cannot be reached.
 			c, clientErr := newClient()
 			if clientErr == nil {
 				valResult, valErr := c.ValidateSieveScript(content)
@@ -59,6 +72,10 @@ script deactivates the currently active one.`,
 					result.Valid = &valResult.Valid
 				}
 			}
+			if result.Valid == nil {
+				valid := !sieve.HasErrors(sieve.Check(content, sieve.LintOptions{}))
+				result.Valid = &valid
+			}
 
 			return formatter().Format(os.Stdout, result)
 		}
@@ -71,7 +88,7 @@ script deactivates the currently active one.`,
 
 		result, err := c.CreateSieveScript(name, content, activate)
 		if err != nil {
-			return exitError("jmap_error", err.Error(), "")
+			return exitError(clientErrorCode(err, "jmap_error"), err.Error(), "")
 		}
 
 		return formatter().Format(os.Stdout, result)
@@ -80,31 +97,57 @@ script deactivates the currently active one.`,
 
 func init() {
 	sieveCreateCmd.Flags().String("name", "", "name for the new script (required)")
-	sieveCreateCmd.Flags().String("from", "", "match sender email address (template mode)")
-	sieveCreateCmd.Flags().String("from-domain", "", "match sender domain (template mode)")
-	sieveCreateCmd.Flags().String("action", "", "action: junk, discard, keep, or fileinto (template mode)")
-	sieveCreateCmd.Flags().String("fileinto", "", "target mailbox for fileinto action (template mode)")
+	addSieveTemplateFlags(sieveCreateCmd)
 	sieveCreateCmd.Flags().Bool("script-stdin", false, "read raw sieve script from stdin")
 	sieveCreateCmd.Flags().Bool("activate", false, "activate the script immediately after creation")
 	sieveCreateCmd.Flags().BoolP("dry-run", "n", false, "preview the generated script without creating it")
 	sieveCmd.AddCommand(sieveCreateCmd)
 }
 
+// addSieveTemplateFlags registers the template-mode flags shared by sieve
+// create and sieve add-rule.
+func addSieveTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().String("from", "", "match sender email address (template mode)")
+	cmd.Flags().String("from-domain", "", "match sender domain (template mode)")
+	cmd.Flags().StringArray("subject-contains", nil, "match subject substring (template mode, repeatable)")
+	cmd.Flags().StringArray("header", nil, "match header with wildcards, as \"Name: pattern\" (template mode, repeatable)")
+	cmd.Flags().StringSlice("to", nil, "match To address (template mode)")
+	cmd.Flags().StringSlice("cc", nil, "match Cc address (template mode)")
+	cmd.Flags().String("size-over", "", "match messages larger than a size such as 500K or 2M (template mode)")
+	cmd.Flags().String("list-id", "", "match List-Id substring (template mode)")
+	cmd.Flags().String("match", "all", "combine conditions: all or any (template mode)")
+	cmd.Flags().String("action", "", "action: junk, discard, keep, or fileinto (template mode)")
+	cmd.Flags().String("fileinto", "", "target mailbox for fileinto action (template mode)")
+	cmd.Flags().Bool("copy", false, "file a copy with :copy and keep the original (template mode)")
+	cmd.Flags().StringSlice("addflag", nil, "add an IMAP flag or keyword (template mode)")
+	cmd.Flags().Bool("mark-read", false, "mark matching messages as read (template mode)")
+}
+
+// sieveTemplateFlags lists the flags that select template mode.
+var sieveTemplateFlags = []string{
+	"from", "from-domain", "subject-contains", "header", "to", "cc",
+	"size-over", "list-id", "match", "action", "fileinto", "copy",
+	"addflag", "mark-read",
+}
+
 // resolveSieveCreateContent determines the script content from either template
 // flags or stdin.
 func resolveSieveCreateContent(cmd *cobra.Command) (string, error) {
 	scriptStdin, _ := cmd.Flags().GetBool("script-stdin")
-	from, _ := cmd.Flags().GetString("from")
-	fromDomain, _ := cmd.Flags().GetString("from-domain")
-	action, _ := cmd.Flags().GetString("action")
 
-	hasTemplate := from != "" || fromDomain != "" || action != ""
+	hasTemplate := false
+	for _, name := range sieveTemplateFlags {
+		if cmd.Flags().Changed(name) {
+			hasTemplate = true
+			break
+		}
+	}
 
 	if hasTemplate && scriptStdin {
-		return "", fmt.Errorf("template flags (--from, --from-domain, --action) and --script-stdin are mutually exclusive")
+		return "", fmt.Errorf("template flags (--from, --from-domain, --action, ...) and --script-stdin are mutually exclusive")
 	}
 	if !hasTemplate && !scriptStdin {
-		return "", fmt.Errorf("provide either template flags (--from/--from-domain + --action) or --script-stdin")
+		return "", fmt.Errorf("provide either template flags (conditions + --action) or --script-stdin")
 	}
 
 	if scriptStdin {
@@ -115,11 +158,25 @@ func resolveSieveCreateContent(cmd *cobra.Command) (string, error) {
 		return string(data), nil
 	}
 
-	fileinto, _ := cmd.Flags().GetString("fileinto")
-	return client.GenerateSieveScript(client.SieveTemplateOptions{
-		From:       from,
-		FromDomain: fromDomain,
-		Action:     action,
-		FileInto:   fileinto,
-	})
+	return client.GenerateSieveScript(parseSieveTemplateOptions(cmd))
+}
+
+// parseSieveTemplateOptions reads the template-mode flags.
+func parseSieveTemplateOptions(cmd *cobra.Command) client.SieveTemplateOptions {
+	var opts client.SieveTemplateOptions
+	opts.From, _ = cmd.Flags().GetString("from")
+	opts.FromDomain, _ = cmd.Flags().GetString("from-domain")
+	opts.SubjectContains, _ = cmd.Flags().GetStringArray("subject-contains")
+	opts.Headers, _ = cmd.Flags().GetStringArray("header")
+	opts.To, _ = cmd.Flags().GetStringSlice("to")
+	opts.Cc, _ = cmd.Flags().GetStringSlice("cc")
+	opts.SizeOver, _ = cmd.Flags().GetString("size-over")
+	opts.ListID, _ = cmd.Flags().GetString("list-id")
+	opts.Match, _ = cmd.Flags().GetString("match")
+	opts.Action, _ = cmd.Flags().GetString("action")
+	opts.FileInto, _ = cmd.Flags().GetString("fileinto")
+	opts.Copy, _ = cmd.Flags().GetBool("copy")
+	opts.AddFlags, _ = cmd.Flags().GetStringSlice("addflag")
+	opts.MarkRead, _ = cmd.Flags().GetBool("mark-read")
+	return opts
 }
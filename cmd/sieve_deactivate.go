@@ -18,6 +18,7 @@ var sieveDeactivateCmd = &cobra.Command{
 			result := types.SieveDryRunResult{
 				Operation: "deactivate",
 			}
+			auditDryRun(nil, "")
 			return formatter().Format(os.Stdout, result)
 		}
 
@@ -29,7 +30,7 @@ var sieveDeactivateCmd = &cobra.Command{
 
 		result, err := c.DeactivateSieveScript()
 		if err != nil {
-			return exitError("jmap_error", err.Error(), "")
+			return exitError(clientErrorCode(err, "jmap_error"), err.Error(), "")
 		}
 
 		return formatter().Format(os.Stdout, result)
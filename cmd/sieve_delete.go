@@ -23,6 +23,7 @@ Active scripts cannot be deleted. Use 'sieve deactivate' first.`,
 				Operation: "delete",
 				Script:    args[0],
 			}
+			auditDryRun([]string{args[0]}, "")
 			return formatter().Format(os.Stdout, result)
 		}
 
@@ -38,7 +39,7 @@ Active scripts cannot be deleted. Use 'sieve deactivate' first.`,
 				return exitError("forbidden_operation", err.Error(),
 					"Use 'fm sieve deactivate' before deleting")
 			}
-			return exitError("jmap_error", err.Error(), "")
+			return exitError(clientErrorCode(err, "jmap_error"), err.Error(), "")
 		}
 
 		return formatter().Format(os.Stdout, result)
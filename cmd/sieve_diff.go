@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+var sieveDiffCmd = &cobra.Command{
+	Use:   "diff <script-id> <file>",
+	Short: "Compare a sieve script on the server with a local file",
+	Long: `Show a unified diff from the script stored on the server to a local
+file. Use "-" as the file to read from stdin:
+  fm sieve diff S1 filters.sieve
+  fm sieve diff S1 backup/Filters.sieve --format json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, file := args[0], args[1]
+
+		var data []byte
+		var err error
+		if file == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return exitError("general_error", fmt.Sprintf("reading %s: %v", file, err), "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		result, err := c.DiffSieveScript(id, string(data), file)
+		if err != nil {
+			if errors.Is(err, client.ErrNotFound) || strings.Contains(err.Error(), "not found") {
+				return exitError("not_found", err.Error(), "")
+			}
+			return exitError("jmap_error", err.Error(), "")
+		}
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	sieveCmd.AddCommand(sieveDiffCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+var sieveRestoreCmd = &cobra.Command{
+	Use:   "restore <dir>",
+	Short: "Restore sieve scripts from a backup directory",
+	Long: `Restore the sieve scripts saved by 'fm sieve backup <dir>'.
+
+Scripts are matched to the server by name. Scripts missing on the server are
+recreated, scripts whose content differs are updated, and identical scripts
+are left alone. The script that was active at backup time is activated again
+if it is not active now. Scripts on the server that are not in the backup are
+never touched.
+
+Use --dry-run to see what would change, with a diff for each update.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := args[0]
+		manifest, err := readSieveBackup(dir)
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		var entries []client.SieveRestoreEntry
+		var readErrs []string
+		for _, entry := range manifest.Scripts {
+			data, err := os.ReadFile(filepath.Join(dir, entry.File))
+			if err != nil {
+				readErrs = append(readErrs, fmt.Sprintf("%s: %v", entry.Name, err))
+				continue
+			}
+			entries = append(entries, client.SieveRestoreEntry{
+				Name:     entry.Name,
+				File:     entry.File,
+				Content:  string(data),
+				IsActive: entry.IsActive,
+			})
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		result, err := c.RestoreSieveScripts(entries, dryRun)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		result.Directory = dir
+		result.Errors = append(readErrs, result.Errors...)
+
+		if dryRun {
+			var ids []string
+			for _, item := range result.Scripts {
+				switch {
+				case item.Action == "unchanged":
+				case item.ID != "":
+					ids = append(ids, item.ID)
+				default:
+					ids = append(ids, item.Name)
+				}
+			}
+			auditDryRun(ids, "")
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+		if len(result.Errors) > 0 {
+			return batchFailure(c, "one or more scripts could not be restored")
+		}
+		return nil
+	},
+}
+
+func init() {
+	sieveRestoreCmd.Flags().BoolP("dry-run", "n", false, "show what would be restored without making changes")
+	sieveCmd.AddCommand(sieveRestoreCmd)
+}
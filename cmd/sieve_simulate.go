@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/sieve"
+)
+
+var sieveTestCmd = &cobra.Command{
+	Use:   "test [<script-id>]",
+	Short: "Simulate a sieve script against existing mail",
+	Long: `Evaluate a sieve script locally against the headers of existing
+messages and report what it would have done. Nothing is changed.
+
+The script is either a stored script (by ID) or read with --script-stdin:
+  fm sieve test S1 --mailbox inbox --limit 500
+  fm sieve test --script-stdin < filters.sieve
+
+Each message lists the rule lines that matched and the resulting actions;
+the summary counts messages by action and destination mailbox. Tests that
+need the message body or envelope cannot be evaluated and count as false.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scriptStdin, _ := cmd.Flags().GetBool("script-stdin")
+		if len(args) == 1 && scriptStdin {
+			return exitError("general_error", "<script-id> and --script-stdin are mutually exclusive", "")
+		}
+		if len(args) == 0 && !scriptStdin {
+			return exitError("general_error", "either <script-id> or --script-stdin is required", "")
+		}
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		limit, _ := cmd.Flags().GetUint64("limit")
+		if limit == 0 {
+			return exitError("general_error", "--limit must be at least 1", "")
+		}
+
+		var content, source string
+		if scriptStdin {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return exitError("general_error", fmt.Sprintf("reading script from stdin: %v", err), "")
+			}
+			content, source = string(data), "stdin"
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		if !scriptStdin {
+			detail, err := c.GetSieveScript(args[0])
+			if err != nil {
+				if strings.Contains(err.Error(), "not found") {
+					return exitError("not_found", err.Error(), "")
+				}
+				return exitError("jmap_error", err.Error(), "")
+			}
+			content, source = detail.Content, detail.ID
+		}
+
+		script, err := sieve.Parse(content)
+		if err != nil {
+			return exitError("general_error", fmt.Sprintf("invalid sieve script: %v", err),
+				"Run 'fm sieve validate --local' for details")
+		}
+
+		result, err := c.SimulateSieveScript(script, client.SieveTestOptions{
+			MailboxNameOrID: mailboxName,
+			Limit:           limit,
+		})
+		if err != nil {
+			return mailboxError(err, "jmap_error")
+		}
+		result.Script = source
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	sieveTestCmd.Flags().Bool("script-stdin", false, "read sieve script from stdin")
+	sieveTestCmd.Flags().StringP("mailbox", "m", "inbox", "mailbox name or ID to test against")
+	sieveTestCmd.Flags().Uint64P("limit", "l", 500, "number of most recent messages to evaluate")
+	sieveCmd.AddCommand(sieveTestCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+var sieveSuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Propose sieve rules from where mail is already filed",
+	Long: `Look at where each sender's recent mail sits and propose a rule for
+senders whose mail is almost all in one mailbox, for example every message
+from a shop's domain filed in Receipts. Nothing is created.
+
+A domain rule is proposed when enough of the domain's mail is in one mailbox;
+otherwise individual addresses are considered. Mailboxes without a role become
+fileinto rules and Junk becomes a junk rule; Inbox, Archive, and other role
+mailboxes are never targets. Trash, Sent, and Drafts are not scanned.
+
+The output ends with a script holding every proposed rule, ready to create:
+  fm sieve suggest --format json | jq -r .content > suggested.sieve
+  fm sieve create --name "Suggested" --script-stdin < suggested.sieve`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetUint64("limit")
+		minCount, _ := cmd.Flags().GetInt("min-count")
+		minShare, _ := cmd.Flags().GetFloat64("min-share")
+		if limit == 0 {
+			return exitError("general_error", "--limit must be at least 1", "")
+		}
+		if minCount < 1 {
+			return exitError("general_error", "--min-count must be at least 1", "")
+		}
+		if minShare <= 0 || minShare > 1 {
+			return exitError("general_error", "--min-share must be greater than 0 and at most 1", "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		result, err := c.SuggestSieveRules(client.SieveSuggestOptions{
+			Limit:    limit,
+			MinCount: minCount,
+			MinShare: minShare,
+		})
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	sieveSuggestCmd.Flags().Uint64P("limit", "l", 2000, "number of most recent messages to scan")
+	sieveSuggestCmd.Flags().Int("min-count", 5, "messages a sender needs in one mailbox")
+	sieveSuggestCmd.Flags().Float64("min-share", 0.9, "fraction of a sender's messages that must be in that mailbox")
+	sieveCmd.AddCommand(sieveSuggestCmd)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/sieve"
+	"github.com/cboone/fm/internal/types"
+)
+
+var sieveUpdateCmd = &cobra.Command{
+	Use:   "update <script-id>",
+	Short: "Replace the content of a sieve script",
+	Long: `Replace the content of an existing sieve script, keeping its ID, name,
+and active state.
+
+Provide the new content via --script or --script-stdin:
+  fm sieve update S1 --script-stdin < filters.sieve
+  fm sieve update S1 --script-stdin -n < filters.sieve
+
+The new content is validated before it is stored. The update only succeeds
+if the script has not changed on the server since it was read; pass
+--if-in-state with the state from 'fm sieve show --format json' to guard
+against changes made since an earlier read. With --dry-run, a unified diff
+of the change is shown instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		content, err := readSieveContent(cmd)
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		current, err := c.GetSieveScript(args[0])
+		if err != nil {
+			if errors.Is(err, client.ErrNotFound) || strings.Contains(err.Error(), "not found") {
+				return exitError("not_found", err.Error(), "")
+			}
+			return exitError("jmap_error", err.Error(), "")
+		}
+		if state, _ := cmd.Flags().GetString("if-in-state"); state != "" {
+			current.State = state
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		return replaceSieveScript(c, current, content, "update", dryRun)
+	},
+}
+
+func init() {
+	sieveUpdateCmd.Flags().String("script", "", "new sieve script content as a string")
+	sieveUpdateCmd.Flags().Bool("script-stdin", false, "read new sieve script from stdin")
+	sieveUpdateCmd.Flags().String("if-in-state", "", "only update if the sieve state still matches")
+	sieveUpdateCmd.Flags().BoolP("dry-run", "n", false, "preview the change as a diff")
+	sieveCmd.AddCommand(sieveUpdateCmd)
+}
+
+// replaceSieveScript validates content and stores it as the new content of
+// current, or previews the change as a diff when dryRun is set. Validation
+// uses the server when possible and the local parser otherwise; invalid
+// content is never stored.
+func replaceSieveScript(c *client.Client, current types.SieveScriptDetail, content, operation string, dryRun bool) error {
+	validation, err := c.ValidateSieveScript(content)
+	if err != nil {
+		validation = sieveValidateResult(content, sieve.Check(content, sieve.LintOptions{}))
+	}
+
+	if dryRun {
+		auditDryRun([]string{current.ID}, "")
+		diff := sieve.UnifiedDiff(current.Content, content, "a/"+current.ID, "b/"+current.ID)
+		result := types.SieveDryRunResult{
+			Operation: operation,
+			Script:    current.ID,
+			Content:   content,
+			Diff:      diff,
+			Valid:     &validation.Valid,
+		}
+		return formatter().Format(os.Stdout, result)
+	}
+
+	if !validation.Valid {
+		return exitError("general_error", "script is not valid: "+validation.Error,
+			"Run 'fm sieve validate --local' for details")
+	}
+
+	result, err := c.UpdateSieveScript(current, content)
+	if err != nil {
+		switch {
+		case errors.Is(err, client.ErrStateMismatch):
+			return exitError("state_mismatch", err.Error(),
+				"Run 'fm sieve show' to review the current script and try again")
+		case errors.Is(err, client.ErrNotFound):
+			return exitError("not_found", err.Error(), "")
+		}
+		return exitError(clientErrorCode(err, "jmap_error"), err.Error(), "")
+	}
+
+	return formatter().Format(os.Stdout, result)
+}
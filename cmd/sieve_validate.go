@@ -6,6 +6,9 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/sieve"
+	"github.com/cboone/fm/internal/types"
 )
 
 var sieveValidateCmd = &cobra.Command{
@@ -13,7 +16,13 @@ var sieveValidateCmd = &cobra.Command{
 	Short: "Validate sieve script syntax without storing it",
 	Long: `Validate sieve script syntax on the server without creating a script.
 
-Provide the script content via --script or --script-stdin.`,
+Provide the script content via --script or --script-stdin.
+
+With --local, the script is parsed and linted on this machine instead, so
+validation works offline. Local validation reports every problem with its
+line and column, and also warns about rules after stop, extensions used
+without require, discard, and fileinto targets that are not existing
+mailboxes (checked only when the server is reachable).`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		content, err := readSieveContent(cmd)
@@ -21,6 +30,11 @@ Provide the script content via --script or --script-stdin.`,
 			return exitError("general_error", err.Error(), "")
 		}
 
+		local, _ := cmd.Flags().GetBool("local")
+		if local {
+			return formatter().Format(os.Stdout, localSieveValidation(content))
+		}
+
 		c, err := newClient()
 		if err != nil {
 			return exitError("authentication_failed", err.Error(),
@@ -29,6 +43,11 @@ Provide the script content via --script or --script-stdin.`,
 
 		result, err := c.ValidateSieveScript(content)
 		if err != nil {
+			// Server validation uploads the script, which read-only mode
+			// refuses; validate locally instead.
+			if c.ReadOnly() && clientErrorCode(err, "") == "forbidden_operation" {
+				return formatter().Format(os.Stdout, localSieveValidation(content))
+			}
 			return exitError("jmap_error", err.Error(), "")
 		}
 
@@ -39,6 +58,7 @@ Provide the script content via --script or --script-stdin.`,
 func init() {
 	sieveValidateCmd.Flags().String("script", "", "sieve script content as a string")
 	sieveValidateCmd.Flags().Bool("script-stdin", false, "read sieve script from stdin")
+	sieveValidateCmd.Flags().Bool("local", false, "parse and lint locally instead of asking the server")
 	sieveCmd.AddCommand(sieveValidateCmd)
 }
 
@@ -64,3 +84,70 @@ func readSieveContent(cmd *cobra.Command) (string, error) {
 
 	return script, nil
 }
+
+// localSieveValidation checks a script with the local parser and linter.
+// Mailbox names are fetched when a client can be created so fileinto targets
+// can be checked; otherwise that check is skipped with a note.
+func localSieveValidation(content string) types.SieveValidateResult {
+	opts := sieve.LintOptions{}
+	var note *sieve.Diagnostic
+
+	names, err := sieveMailboxNames()
+	if err != nil {
+		note = &sieve.Diagnostic{
+			Severity: sieve.SeverityWarning,
+			Message:  fmt.Sprintf("fileinto targets not checked against mailboxes: %v", err),
+		}
+	} else {
+		opts.Mailboxes = names
+	}
+
+	diags := sieve.Check(content, opts)
+	if note != nil {
+		diags = append(diags, *note)
+	}
+	return sieveValidateResult(content, diags)
+}
+
+// sieveMailboxNames returns the full paths of all mailboxes in the account.
+func sieveMailboxNames() ([]string, error) {
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(mailboxes))
+	for i, mb := range mailboxes {
+		names[i] = c.MailboxPath(mb)
+	}
+	return names, nil
+}
+
+// sieveValidateResult converts local diagnostics into a validation result.
+// The script is valid when there are no errors; the first error is repeated
+// in Error for parity with server-side validation.
+func sieveValidateResult(content string, diags []sieve.Diagnostic) types.SieveValidateResult {
+	result := types.SieveValidateResult{
+		Valid:   !sieve.HasErrors(diags),
+		Content: content,
+	}
+	for _, d := range diags {
+		result.Diagnostics = append(result.Diagnostics, types.SieveDiagnostic{
+			Severity: string(d.Severity),
+			Line:     d.Pos.Line,
+			Column:   d.Pos.Column,
+			Message:  d.Message,
+		})
+		if d.Severity == sieve.SeverityError && result.Error == "" {
+			if d.Pos.Line > 0 {
+				result.Error = fmt.Sprintf("line %d, column %d: %s", d.Pos.Line, d.Pos.Column, d.Message)
+			} else {
+				result.Error = d.Message
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/sieve"
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestSieveValidateLocal_ChecksMailboxesWithoutServerValidation(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-finance", "name": "Finance"},
+			{"id": "mb-receipts", "name": "Receipts", "parentId": "mb-finance"},
+		},
+		nil,
+		nil,
+	)
+
+	script := "require \"fileinto\";\nif true {\n    fileinto \"Receipts\";\n    fileinto \"Finance/Receipts\";\n    stop;\n}\n"
+	args := commandArgsForServer(t, server.server.URL, "sieve", "validate", "--local", "--script", script)
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+
+	var result types.SieveValidateResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if !result.Valid {
+		t.Fatalf("expected script to be valid, got: %+v", result)
+	}
+	if len(result.Diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic, got: %+v", result.Diagnostics)
+	}
+	d := result.Diagnostics[0]
+	if d.Severity != "warning" || d.Line != 3 || d.Column != 14 || !strings.Contains(d.Message, `did you mean "Finance/Receipts"?`) {
+		t.Errorf("unexpected diagnostic: %+v", d)
+	}
+	if server.count("SieveScript/validate") != 0 {
+		t.Errorf("expected no server-side validation, got %d calls", server.count("SieveScript/validate"))
+	}
+}
+
+func TestSieveValidateResult_ReportsFirstError(t *testing.T) {
+	content := "keep;\nfrobnicate;\nbogus;\n"
+	result := sieveValidateResult(content, sieve.Check(content, sieve.LintOptions{}))
+	if result.Valid {
+		t.Fatal("expected invalid result")
+	}
+	if result.Error != "line 2, column 1: unknown command \"frobnicate\"" {
+		t.Errorf("unexpected error: %q", result.Error)
+	}
+	if len(result.Diagnostics) != 2 {
+		t.Errorf("expected both errors as diagnostics, got: %+v", result.Diagnostics)
+	}
+}
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var snoozeCmd = &cobra.Command{
+	Use:   "snooze [email-id...] --until <time>",
+	Short: "Move emails to Snoozed until a given time",
+	Long: `Move one or more emails to the Snoozed mailbox until a given time, when
+'fm snooze wake' returns them to the Inbox as unread. The Snoozed mailbox is
+created on first use.
+
+--until takes an RFC 3339 time, a local date and time (2026-11-01T09:00 or
+"2026-11-01 09:00"), a local date (the start of that day), or a delay from
+now such as 4h, 3d, or 2w.
+
+The return time is kept per profile in snooze_file (default
+~/.local/state/fm/snooze.json) and in a snoozed-until-<time> keyword on each
+email, so emails snoozed from another machine still wake. Run
+'fm snooze wake' from cron to return emails when they are due.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateIDsOrFilters(cmd, args); err != nil {
+			return err
+		}
+
+		untilFlag, _ := cmd.Flags().GetString("until")
+		if untilFlag == "" {
+			return exitError("general_error", "required flag \"until\" not set",
+				"Specify when the emails return with --until (e.g. 2026-11-01T09:00 or 3d)")
+		}
+		now := time.Now()
+		until, err := parseSnoozeTime(untilFlag, now)
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+		if !until.After(now) {
+			return exitError("general_error", fmt.Sprintf("--until %s is not in the future", untilFlag), "")
+		}
+		until = until.UTC()
+
+		state, err := snoozeState()
+		if err != nil {
+			return exitError("config_error", err.Error(), "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		ids, err := resolveEmailIDs(cmd, args, c)
+		if err != nil {
+			return err
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun {
+			dest := &types.DestinationInfo{Name: client.SnoozedMailboxName, Path: client.SnoozedMailboxName}
+			if mb, err := c.TopLevelMailbox(client.SnoozedMailboxName); err == nil {
+				dest = c.Destination(mb)
+			}
+			return dryRunPreview(c, ids, "snooze", dest)
+		}
+
+		snoozedMB, err := c.EnsureMailbox(client.SnoozedMailboxName)
+		if err != nil {
+			return exitError(clientErrorCode(err, "jmap_error"), err.Error(), "")
+		}
+
+		succeeded, errs, err := c.SnoozeEmails(ids, snoozedMB, until)
+		if err != nil {
+			return exitError(clientErrorCode(err, "jmap_error"), err.Error(),
+				"Deletion is not permitted by this tool")
+		}
+
+		entries := make(map[string]types.SnoozeEntry, len(succeeded))
+		for _, id := range succeeded {
+			entries[id] = types.SnoozeEntry{Until: until, SnoozedAt: now.UTC()}
+		}
+		if err := state.Update(entries, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: snooze state: %v\n", err)
+		}
+
+		result := types.MoveResult{
+			Matched:     len(ids),
+			Processed:   len(succeeded) + len(errs),
+			Failed:      len(errs),
+			Snoozed:     succeeded,
+			Until:       &until,
+			Errors:      errs,
+			Destination: c.Destination(snoozedMB),
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if len(errs) > 0 {
+			return batchFailure(c, "one or more emails failed to snooze")
+		}
+
+		return nil
+	},
+}
+
+var snoozeWakeCmd = &cobra.Command{
+	Use:   "wake",
+	Short: "Return snoozed emails that are due to the Inbox",
+	Long: `Move every snoozed email whose time has come from the Snoozed mailbox back
+to the Inbox and mark it unread. The return time comes from the local snooze
+state, or from the email's snoozed-until keyword when the state has no
+record of it. Emails in Snoozed with neither are left alone.
+
+Records of emails no longer in Snoozed are dropped from the state. Nothing
+due is not an error, so this is safe to run from cron:
+  */15 * * * * fm snooze wake --format text`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := snoozeState()
+		if err != nil {
+			return exitError("config_error", err.Error(), "")
+		}
+		entries, err := state.Entries()
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		inbox, err := c.GetMailboxByRole(mailbox.RoleInbox)
+		if err != nil {
+			return exitError("not_found", err.Error(), "")
+		}
+		dest := c.Destination(inbox)
+
+		var snoozed []client.SnoozedEmail
+		snoozedMB, err := c.TopLevelMailbox(client.SnoozedMailboxName)
+		switch {
+		case err == nil:
+			snoozed, err = c.SnoozedEmails(snoozedMB.ID)
+			if err != nil {
+				return exitError("jmap_error", err.Error(), "")
+			}
+		case !errors.Is(err, client.ErrNotFound):
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		now := time.Now()
+		due, pending, next := dueSnoozed(snoozed, entries, now)
+
+		inSnoozed := make(map[string]bool, len(snoozed))
+		for _, e := range snoozed {
+			inSnoozed[e.ID] = true
+		}
+		var stale []string
+		for id := range entries {
+			if !inSnoozed[id] {
+				stale = append(stale, id)
+			}
+		}
+		sort.Strings(stale)
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun {
+			ids := make([]string, len(due))
+			for i, e := range due {
+				ids[i] = e.ID
+			}
+			return dryRunPreview(c, ids, "wake", dest)
+		}
+
+		succeeded, errs := []string{}, []string{}
+		if len(due) > 0 {
+			succeeded, errs, err = c.WakeEmails(due, inbox)
+			if err != nil {
+				return exitError(clientErrorCode(err, "jmap_error"), err.Error(), "")
+			}
+		}
+		if forget := append(stale, succeeded...); len(forget) > 0 {
+			if err := state.Update(nil, forget); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: snooze state: %v\n", err)
+			}
+		}
+
+		result := types.SnoozeWakeResult{
+			Due:         len(due),
+			Processed:   len(succeeded) + len(errs),
+			Failed:      len(errs),
+			Woken:       succeeded,
+			Destination: dest,
+			Pending:     pending,
+			NextWake:    next,
+			Errors:      errs,
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if len(errs) > 0 {
+			return batchFailure(c, "one or more emails failed to wake")
+		}
+
+		return nil
+	},
+}
+
+// dueSnoozed splits the emails in Snoozed into those due at now and the
+// count still waiting, with the earliest time one of those is due. The
+// email's keyword is the source of truth, since it travels with the email
+// and is updated on every snooze; the local state's return time is used
+// only for emails without one. Emails with neither are not snoozed by fm
+// and are skipped.
+func dueSnoozed(snoozed []client.SnoozedEmail, entries map[string]types.SnoozeEntry, now time.Time) (due []client.SnoozedEmail, pending int, next *time.Time) {
+	for _, e := range snoozed {
+		var until time.Time
+		if e.Until != nil {
+			until = *e.Until
+		} else if entry, ok := entries[e.ID]; ok {
+			until = entry.Until
+		} else {
+			continue
+		}
+		if !until.After(now) {
+			due = append(due, e)
+			continue
+		}
+		pending++
+		if next == nil || until.Before(*next) {
+			t := until
+			next = &t
+		}
+	}
+	return due, pending, next
+}
+
+// parseSnoozeTime parses --until: an RFC 3339 time, a local date and time,
+// a local date (its start), or a delay from now such as "3d".
+func parseSnoozeTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if d, err := parseAge(s); err == nil {
+		return now.Add(d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --until %q: use RFC 3339, YYYY-MM-DDTHH:MM, YYYY-MM-DD, or a delay such as 3d", s)
+}
+
+func init() {
+	snoozeCmd.Flags().String("until", "", "when the emails return to the Inbox (required)")
+	snoozeCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addFilterFlags(snoozeCmd)
+
+	snoozeWakeCmd.Flags().BoolP("dry-run", "n", false, "preview the emails that are due without moving them")
+
+	snoozeCmd.AddCommand(snoozeWakeCmd)
+	rootCmd.AddCommand(snoozeCmd)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/snooze"
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestParseSnoozeTime(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, loc)
+	tests := map[string]time.Time{
+		"2026-11-01T09:00":          time.Date(2026, 11, 1, 9, 0, 0, 0, loc),
+		"2026-11-01 09:00":          time.Date(2026, 11, 1, 9, 0, 0, 0, loc),
+		"2026-11-01":                time.Date(2026, 11, 1, 0, 0, 0, 0, loc),
+		"2026-11-01T09:00:00Z":      time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC),
+		"3d":                        now.Add(72 * time.Hour),
+		"4h":                        now.Add(4 * time.Hour),
+		"2026-11-01T09:00:00+01:00": time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC),
+	}
+	for in, want := range tests {
+		got, err := parseSnoozeTime(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseSnoozeTime(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := parseSnoozeTime("next week", now); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestDueSnoozed(t *testing.T) {
+	now := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	past, future, later := now.Add(-time.Minute), now.Add(time.Hour), now.Add(48*time.Hour)
+	snoozed := []client.SnoozedEmail{
+		{ID: "M1", Until: &future}, // keyword pending, stale state says due
+		{ID: "M2", Until: &past},   // keyword only, due
+		{ID: "M3", Until: &later},  // keyword only, pending
+		{ID: "M4"},                 // not snoozed by fm
+		{ID: "M5", Until: &past},   // keyword due, stale state says pending
+		{ID: "M6"},                 // keyword lost, state says due
+	}
+	entries := map[string]types.SnoozeEntry{"M1": {Until: now}, "M5": {Until: later}, "M6": {Until: past}}
+
+	due, pending, next := dueSnoozed(snoozed, entries, now)
+	if len(due) != 3 || due[0].ID != "M2" || due[1].ID != "M5" || due[2].ID != "M6" {
+		t.Errorf("unexpected due emails: %+v", due)
+	}
+	if pending != 2 || next == nil || !next.Equal(future) {
+		t.Errorf("pending = %d, next = %v", pending, next)
+	}
+}
+
+func TestSnooze_MovesAndRecordsState(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-snoozed", "name": "Snoozed"},
+		},
+		[]map[string]any{{"id": "M1", "threadId": "T1", "subject": "Later"}},
+		nil,
+	)
+	path := filepath.Join(t.TempDir(), "snooze.json")
+	t.Setenv("FM_SNOOZE_FILE", path)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "snooze", "--until", "2099-11-01T09:00:00Z", "M1"))
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	var result types.MoveResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout)
+	}
+	want := time.Date(2099, 11, 1, 9, 0, 0, 0, time.UTC)
+	if len(result.Snoozed) != 1 || result.Until == nil || !result.Until.Equal(want) || result.Destination.ID != "mb-snoozed" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	entries, err := (&snooze.State{Path: path, Account: "default"}).Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !entries["M1"].Until.Equal(want) {
+		t.Errorf("expected M1 in the snooze state, got %+v", entries)
+	}
+}
+
+func TestSnooze_RejectsPastTime(t *testing.T) {
+	t.Setenv("FM_SNOOZE_FILE", filepath.Join(t.TempDir(), "snooze.json"))
+	_, _, err := runCLICommand(t, []string{"snooze", "--until", "2001-01-01", "M1"})
+	if err == nil {
+		t.Fatal("expected an error for a time in the past")
+	}
+}
+
+func TestSnoozeWake_ReturnsDueEmails(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-snoozed", "name": "Snoozed"},
+		},
+		[]map[string]any{
+			{"id": "M1", "keywords": map[string]bool{"$seen": true, "snoozed-until-20200101t0900z": true}},
+			{"id": "M2", "keywords": map[string]bool{"$seen": true}},
+		},
+		nil,
+	)
+	path := filepath.Join(t.TempDir(), "snooze.json")
+	t.Setenv("FM_SNOOZE_FILE", path)
+	state := &snooze.State{Path: path, Account: "default"}
+	future := time.Now().Add(time.Hour).UTC()
+	if err := state.Update(map[string]types.SnoozeEntry{
+		"M2": {Until: future},
+		"M9": {Until: time.Now().UTC()}, // no longer in Snoozed
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "snooze", "wake"))
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	var result types.SnoozeWakeResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout)
+	}
+	if result.Due != 1 || len(result.Woken) != 1 || result.Woken[0] != "M1" || result.Destination.ID != "mb-inbox" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result.Pending != 1 || result.NextWake == nil || !result.NextWake.Equal(future) {
+		t.Errorf("expected M2 to stay snoozed, got %+v", result)
+	}
+	if server.count("Email/set") != 1 {
+		t.Errorf("expected one Email/set, got %d", server.count("Email/set"))
+	}
+
+	entries, err := state.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries["M2"].Until.IsZero() {
+		t.Errorf("expected only M2 left in the state, got %+v", entries)
+	}
+}
@@ -36,24 +36,18 @@ var spamCmd = &cobra.Command{
 
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		if dryRun {
-			return dryRunPreview(c, ids, "spam", &types.DestinationInfo{
-				ID:   string(junkMB.ID),
-				Name: junkMB.Name,
-			})
+			return dryRunPreview(c, ids, "spam", c.Destination(junkMB))
 		}
 
 		succeeded, errors := c.MarkAsSpam(ids, junkMB.ID)
 
 		result := types.MoveResult{
-			Matched:    len(ids),
-			Processed:  len(succeeded) + len(errors),
-			Failed:     len(errors),
-			MarkedSpam: succeeded,
-			Errors:     errors,
-			Destination: &types.DestinationInfo{
-				ID:   string(junkMB.ID),
-				Name: junkMB.Name,
-			},
+			Matched:     len(ids),
+			Processed:   len(succeeded) + len(errors),
+			Failed:      len(errors),
+			MarkedSpam:  succeeded,
+			Errors:      errors,
+			Destination: c.Destination(junkMB),
 		}
 
 		if err := formatter().Format(os.Stdout, result); err != nil {
@@ -61,7 +55,7 @@ var spamCmd = &cobra.Command{
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to mark as spam", "")
+			return batchFailure(c, "one or more emails failed to mark as spam")
 		}
 
 		return nil
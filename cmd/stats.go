@@ -33,7 +33,7 @@ sorted by volume descending.`,
 
 		mailboxID, err := c.ResolveMailboxID(mailboxName)
 		if err != nil {
-			return exitError("not_found", err.Error(), "")
+			return mailboxError(err, "not_found")
 		}
 
 		result, err := c.AggregateEmailsBySender(client.StatsOptions{
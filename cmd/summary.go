@@ -13,7 +13,13 @@ var summaryCmd = &cobra.Command{
 	Short: "Show inbox triage summary with sender and domain aggregation",
 	Args:  cobra.NoArgs,
 	Long: `Aggregate emails by sender and domain, count unread messages, and optionally
-detect newsletters. Provides a single-pass triage overview of a mailbox.`,
+classify senders. Provides a single-pass triage overview of a mailbox.
+
+With --newsletters, every sender is scored from List-Id, List-Unsubscribe,
+Precedence, and email service provider headers, the local part of its
+address, and how much it sent, and classified as newsletter, transactional,
+mailing_list, notification, or personal. Top senders show their category
+and score, newsletters are listed, and messages are counted per category.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		mailboxName, _ := cmd.Flags().GetString("mailbox")
 		unread, _ := cmd.Flags().GetBool("unread")
@@ -38,7 +44,7 @@ detect newsletters. Provides a single-pass triage overview of a mailbox.`,
 
 		mailboxID, err := c.ResolveMailboxID(mailboxName)
 		if err != nil {
-			return exitError("not_found", err.Error(), "")
+			return mailboxError(err, "not_found")
 		}
 
 		result, err := c.AggregateSummary(client.SummaryOptions{
@@ -65,6 +71,6 @@ func init() {
 	summaryCmd.Flags().Bool("unflagged", false, "only count unflagged messages")
 	summaryCmd.Flags().IntP("limit", "l", 10, "number of top senders/domains to show")
 	summaryCmd.Flags().Bool("subjects", false, "include sample subjects per sender")
-	summaryCmd.Flags().Bool("newsletters", false, "detect newsletters via List-Id/List-Unsubscribe headers")
+	summaryCmd.Flags().Bool("newsletters", false, "classify senders and list newsletters")
 	rootCmd.AddCommand(summaryCmd)
 }
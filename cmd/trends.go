@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+// defaultTrendBuckets is how many buckets fm trends covers when --after is
+// not given.
+var defaultTrendBuckets = map[string]int{
+	client.TrendByDay:   30,
+	client.TrendByWeek:  12,
+	client.TrendByMonth: 12,
+}
+
+var trendsCmd = &cobra.Command{
+	Use:   "trends",
+	Short: "Show mailbox volume over time, optionally per sender or domain",
+	Long: `Count the emails received in each day, week, or month of a date range,
+by receivedAt. Weeks start on Monday; buckets are in UTC. Empty buckets are
+included, so falling volume shows as zeros.
+
+With --group, counts are also broken down per sender, per sender domain, or
+per newsletter sender (senders of mail with List-Id or List-Unsubscribe
+headers). Each series has a slope, the least-squares trend in emails per
+bucket, so senders that are ramping up sort out at a glance.
+
+Without --after, the range covers the last 30 days, 12 weeks, or 12 months,
+including the current one.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		by, _ := cmd.Flags().GetString("by")
+		group, _ := cmd.Flags().GetString("group")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		if _, ok := defaultTrendBuckets[by]; !ok {
+			return exitError("general_error", fmt.Sprintf("unsupported --by value: %q", by),
+				"supported values: day, week, month")
+		}
+		switch group {
+		case client.TrendGroupNone, client.TrendGroupSender, client.TrendGroupDomain, client.TrendGroupNewsletter:
+		default:
+			return exitError("general_error", fmt.Sprintf("unsupported --group value: %q", group),
+				"supported values: sender, domain, newsletter")
+		}
+		if limit < 0 {
+			return exitError("general_error", "--limit must not be negative", "")
+		}
+
+		before := time.Now().UTC()
+		if s, _ := cmd.Flags().GetString("before"); strings.TrimSpace(s) != "" {
+			t, err := parseDate(strings.TrimSpace(s))
+			if err != nil {
+				return exitError("general_error", "invalid --before date: "+err.Error(),
+					"Use RFC 3339 format (e.g. 2026-01-15T00:00:00Z) or a bare date (e.g. 2026-01-15)")
+			}
+			before = t
+		}
+		after := client.BucketStart(before, by)
+		switch by {
+		case client.TrendByDay:
+			after = after.AddDate(0, 0, 1-defaultTrendBuckets[by])
+		case client.TrendByWeek:
+			after = after.AddDate(0, 0, 7*(1-defaultTrendBuckets[by]))
+		case client.TrendByMonth:
+			after = after.AddDate(0, 1-defaultTrendBuckets[by], 0)
+		}
+		if s, _ := cmd.Flags().GetString("after"); strings.TrimSpace(s) != "" {
+			t, err := parseDate(strings.TrimSpace(s))
+			if err != nil {
+				return exitError("general_error", "invalid --after date: "+err.Error(),
+					"Use RFC 3339 format (e.g. 2026-01-15T00:00:00Z) or a bare date (e.g. 2026-01-15)")
+			}
+			after = t
+		}
+		if !after.Before(before) {
+			return exitError("general_error", "--after must be earlier than --before", "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		mailboxID, err := c.ResolveMailboxID(mailboxName)
+		if err != nil {
+			return mailboxError(err, "not_found")
+		}
+
+		result, err := c.EmailTrends(client.TrendsOptions{
+			MailboxID: string(mailboxID),
+			By:        by,
+			Group:     group,
+			After:     after,
+			Before:    before,
+			Limit:     limit,
+		})
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	trendsCmd.Flags().StringP("mailbox", "m", "inbox", "mailbox name or ID")
+	trendsCmd.Flags().String("by", "week", "bucket size: day, week, or month")
+	trendsCmd.Flags().String("group", "", "break counts down by sender, domain, or newsletter")
+	trendsCmd.Flags().String("after", "", "count emails received at or after this date (RFC 3339 or YYYY-MM-DD)")
+	trendsCmd.Flags().String("before", "", "count emails received before this date (default now)")
+	trendsCmd.Flags().IntP("limit", "l", 10, "number of groups to show, by volume (0 for all)")
+	rootCmd.AddCommand(trendsCmd)
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrendsCmd_RejectsBadFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"trends", "--by", "year"},
+		{"trends", "--group", "subject"},
+		{"trends", "--after", "2026-03-01", "--before", "2026-02-01"},
+		{"trends", "--after", "last week"},
+	} {
+		_, stderr, err := runCLICommand(t, args)
+		if err == nil || !strings.Contains(stderr, "general_error") {
+			t.Errorf("%v: expected general_error, got err=%v stderr=%s", args, err, stderr)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+var triageCmd = &cobra.Command{
+	Use:   "triage",
+	Short: "Plan inbox triage",
+	Long: `Plan inbox triage. Subcommands classify mail and propose fm commands for
+review; they never change anything themselves.`,
+}
+
+var triageSuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Classify unread mail and propose fm commands to handle it",
+	Long: `Classify each unread message in the Inbox (or --mailbox) as personal,
+time_sensitive, spam_like, promotional, or transactional, with the rules that
+put it there, and propose the fm commands that would handle each group.
+Nothing is changed.
+
+  personal        sender has no list or bulk-mail signals: flag for review,
+                  and draft a reply by hand
+  time_sensitive  personal or account mail whose subject or preview mentions
+                  a deadline, renewal, reminder, or similar: flag orange
+  spam_like       $junk or $phishing keyword, a spam verdict header, failed
+                  SPF/DKIM/DMARC, or a spoofed display name: mark read, spam
+  promotional     newsletters and mailing lists: mark read, archive
+  transactional   receipts, notifications, and other automated mail: mark
+                  read, archive
+
+The output ends with a shell script holding every command, ready to review
+and run. The commands carry the --config, --profile, --session-url, and
+--account-id in effect, so they act on the same account:
+  fm triage suggest --format json | jq -r .content > triage.sh
+  $EDITOR triage.sh && sh triage.sh`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit < 0 {
+			return exitError("general_error", "--limit must not be negative", "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		mailboxID, err := c.ResolveMailboxID(mailboxName)
+		if err != nil {
+			return mailboxError(err, "not_found")
+		}
+
+		plan, err := c.SuggestTriage(client.TriageOptions{
+			MailboxID:   string(mailboxID),
+			Limit:       limit,
+			Now:         time.Now().UTC(),
+			AccountArgs: accountArgs(),
+		})
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, plan)
+	},
+}
+
+func init() {
+	triageSuggestCmd.Flags().StringP("mailbox", "m", "inbox", "mailbox to triage")
+	triageSuggestCmd.Flags().IntP("limit", "l", 200, "number of most recent unread messages to plan for (0 for all)")
+
+	triageCmd.AddCommand(triageSuggestCmd)
+	rootCmd.AddCommand(triageCmd)
+}
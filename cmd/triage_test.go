@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTriageSuggest_CommandsKeepAccountFlags(t *testing.T) {
+	server := newJMAPMockServer(t, nestedMailboxes, []map[string]any{dryRunEmail}, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "triage", "suggest")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	want := "--session-url " + server.server.URL + "/session --account-id A1 M1"
+	if !strings.Contains(stdout, want) || !strings.Contains(stdout, `"command_line": "fm `) {
+		t.Errorf("expected account flags in each command, got: %s", stdout)
+	}
+	if !strings.Contains(stdout, "--config ") || strings.Contains(stdout, "test-token") {
+		t.Errorf("expected --config kept and the token left out, got: %s", stdout)
+	}
+}
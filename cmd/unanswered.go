@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+var unansweredCmd = &cobra.Command{
+	Use:   "unanswered",
+	Short: "List threads still waiting for your reply",
+	Long: `List threads in the Inbox (or --mailbox) whose latest message is inbound
+and has had no reply for at least --older-than. A thread counts as answered
+when its latest message is in the Sent mailbox, is from one of your own
+addresses, or carries the $answered keyword (set by most clients when you
+reply). Drafts are ignored.
+
+Mail with List-Id or List-Unsubscribe headers (newsletters and mailing lists)
+is skipped. The latest message of each thread is shown, longest waiting
+first, with the number of messages in the thread and when you last replied.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		if limit < 0 {
+			return exitError("general_error", "--limit must not be negative", "")
+		}
+		olderThanStr, _ := cmd.Flags().GetString("older-than")
+		olderThan, err := parseAge(olderThanStr)
+		if err != nil {
+			return exitError("general_error", "invalid --older-than: "+err.Error(),
+				"Use a number of days or weeks (e.g. 3d, 2w) or a duration (e.g. 36h)")
+		}
+
+		opts := client.UnansweredOptions{
+			OlderThan: olderThan,
+			Now:       time.Now().UTC(),
+			Limit:     limit,
+		}
+		if s, _ := cmd.Flags().GetString("after"); strings.TrimSpace(s) != "" {
+			t, err := parseDate(strings.TrimSpace(s))
+			if err != nil {
+				return exitError("general_error", "invalid --after date: "+err.Error(),
+					"Use RFC 3339 format (e.g. 2026-01-15T00:00:00Z) or a bare date (e.g. 2026-01-15)")
+			}
+			opts.After = &t
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		mailboxID, err := c.ResolveMailboxID(mailboxName)
+		if err != nil {
+			return mailboxError(err, "not_found")
+		}
+		opts.MailboxID = string(mailboxID)
+
+		result, err := c.Unanswered(opts)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				return exitError("not_found", err.Error(), "")
+			}
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	unansweredCmd.Flags().StringP("mailbox", "m", "inbox", "mailbox to check for threads awaiting a reply")
+	unansweredCmd.Flags().String("older-than", "3d", "only threads whose latest message is at least this old")
+	unansweredCmd.Flags().String("after", "", "only threads with a message received at or after this date")
+	unansweredCmd.Flags().IntP("limit", "l", 50, "number of threads to show (0 for all)")
+	rootCmd.AddCommand(unansweredCmd)
+}
@@ -55,7 +55,7 @@ var unflagCmd = &cobra.Command{
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to unflag", "")
+			return batchFailure(c, "one or more emails failed to unflag")
 		}
 
 		return nil
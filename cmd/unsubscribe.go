@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var unsubscribeCmd = &cobra.Command{
+	Use:   "unsubscribe <email-id>",
+	Short: "Unsubscribe from the mailing list an email came from",
+	Long: `Unsubscribe from the mailing list an email came from, using its
+List-Unsubscribe header.
+
+When the email also has "List-Unsubscribe-Post: List-Unsubscribe=One-Click",
+fm sends the RFC 8058 one-click POST to the https: URL. The request times out
+after 15 seconds and follows only redirects to other https: URLs. Otherwise,
+when the header offers a mailto: address, fm creates a draft to it in the
+Drafts mailbox; the draft is NOT sent. Review and send it from Fastmail.
+Plain http: links and web pages without one-click support are not used.
+
+Each outcome is kept per profile in unsubscribe_file (default
+~/.local/state/fm/unsubscribe.json), keyed by the List-Id header or, without
+one, the sender address. A list that was already unsubscribed from is
+reported as already_unsubscribed and not contacted again; use --force to
+unsubscribe again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+
+		state, err := unsubscribeState()
+		if err != nil {
+			return exitError("config_error", err.Error(), "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		plan, err := c.PlanUnsubscribe(args[0])
+		switch {
+		case errors.Is(err, client.ErrNotFound):
+			return exitError("not_found", err.Error(), "")
+		case errors.Is(err, client.ErrNoUnsubscribe):
+			return exitError("general_error", err.Error(),
+				"Use the unsubscribe link in the message, or filter the sender with 'fm sieve add-rule'")
+		case err != nil:
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		previous, found, err := state.Lookup(plan.List)
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+		if found {
+			plan.Previous = &previous
+			if previous.OK && !force {
+				plan.Status = "already_unsubscribed"
+				return formatter().Format(os.Stdout, plan)
+			}
+		}
+
+		if dryRun {
+			plan.DryRun = true
+			auditDryRun([]string{plan.EmailID}, plan.Target)
+			return formatter().Format(os.Stdout, plan)
+		}
+
+		result, err := c.Unsubscribe(plan)
+		var forbidden *client.ErrForbidden
+		if !errors.As(err, &forbidden) {
+			record := types.UnsubscribeRecord{
+				Time:       time.Now().UTC(),
+				EmailID:    result.EmailID,
+				Method:     result.Method,
+				Target:     result.Target,
+				OK:         err == nil,
+				HTTPStatus: result.HTTPStatus,
+			}
+			if result.Draft != nil {
+				record.DraftID = result.Draft.ID
+			}
+			if err != nil {
+				record.Error = err.Error()
+			}
+			if serr := state.Save(result.List, record); serr != nil {
+				fmt.Fprintf(os.Stderr, "warning: unsubscribe state: %v\n", serr)
+			}
+		}
+		if err != nil {
+			if code := clientErrorCode(err, ""); code != "" {
+				hint := ""
+				if c.BudgetExceeded() {
+					hint = "Run 'fm budget' to see the remaining allowance"
+				}
+				return exitError(code, err.Error(), hint)
+			}
+			if result.Method == client.UnsubscribeOneClick {
+				return exitError("general_error", "unsubscribe request failed: "+err.Error(),
+					"Try again later, or use the unsubscribe link in the message")
+			}
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	unsubscribeCmd.Flags().BoolP("dry-run", "n", false, "show how fm would unsubscribe without doing it")
+	unsubscribeCmd.Flags().Bool("force", false, "unsubscribe again even if the list was already unsubscribed from")
+	rootCmd.AddCommand(unsubscribeCmd)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+	"github.com/cboone/fm/internal/unsubscribe"
+)
+
+func newsletterServer(t *testing.T) *jmapMockServer {
+	t.Helper()
+	return newJMAPMockServer(t, nil,
+		[]map[string]any{{
+			"id":      "M1",
+			"from":    []map[string]any{{"email": "news@shop.example"}},
+			"subject": "Weekly deals",
+			"headers": []map[string]any{
+				{"name": "List-Id", "value": "<deals.shop.example>"},
+				{"name": "List-Unsubscribe", "value": "<https://shop.example/u?id=1>"},
+				{"name": "List-Unsubscribe-Post", "value": "List-Unsubscribe=One-Click"},
+			},
+		}},
+		nil,
+	)
+}
+
+func TestUnsubscribe_DryRun(t *testing.T) {
+	server := newsletterServer(t)
+	t.Setenv("FM_UNSUBSCRIBE_FILE", filepath.Join(t.TempDir(), "unsubscribe.json"))
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "unsubscribe", "--dry-run", "M1"))
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	var result types.UnsubscribeResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout)
+	}
+	if !result.DryRun || result.Status != "would_unsubscribe" || result.Method != "one_click" ||
+		result.Target != "https://shop.example/u?id=1" || result.List != "deals.shop.example" {
+		t.Errorf("unexpected dry run: %+v", result)
+	}
+}
+
+func TestUnsubscribe_SkipsListAlreadyUnsubscribed(t *testing.T) {
+	server := newsletterServer(t)
+	path := filepath.Join(t.TempDir(), "unsubscribe.json")
+	t.Setenv("FM_UNSUBSCRIBE_FILE", path)
+	state := &unsubscribe.State{Path: path, Account: "default"}
+	if err := state.Save("deals.shop.example", types.UnsubscribeRecord{Time: time.Now().UTC(), EmailID: "M0", Method: "one_click", OK: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "unsubscribe", "M1"))
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	var result types.UnsubscribeResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout)
+	}
+	if result.Status != "already_unsubscribed" || result.Previous == nil || result.Previous.EmailID != "M0" {
+		t.Errorf("expected the earlier unsubscribe, got %+v", result)
+	}
+}
+
+func TestUnsubscribe_NoHeader(t *testing.T) {
+	server := newJMAPMockServer(t, nil, []map[string]any{{"id": "M1", "subject": "Hello"}}, nil)
+	t.Setenv("FM_UNSUBSCRIBE_FILE", filepath.Join(t.TempDir(), "unsubscribe.json"))
+
+	_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "unsubscribe", "M1"))
+	if err == nil {
+		t.Fatal("expected an error for an email without List-Unsubscribe")
+	}
+	if !strings.Contains(stderr, "no List-Unsubscribe header") {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+}
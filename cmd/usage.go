@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show where mailbox storage goes, and quota use",
+	Long: `Add up message and attachment sizes across the account (or one mailbox)
+by mailbox, sender, sender domain, year received, and attachment MIME type,
+and list the largest messages. An email in several mailboxes counts toward
+each of them.
+
+When the server supports JMAP quotas (urn:ietf:params:jmap:quota), the
+account's quotas are shown with their use and limits.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		limit, _ := cmd.Flags().GetInt("limit")
+		largest, _ := cmd.Flags().GetInt("largest")
+
+		if limit < 0 {
+			return exitError("general_error", "--limit must not be negative", "")
+		}
+		if largest < 0 {
+			return exitError("general_error", "--largest must not be negative", "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		opts := client.UsageOptions{Limit: limit, Largest: largest}
+		if strings.TrimSpace(mailboxName) != "" {
+			mailboxID, err := c.ResolveMailboxID(strings.TrimSpace(mailboxName))
+			if err != nil {
+				return mailboxError(err, "not_found")
+			}
+			opts.MailboxID = string(mailboxID)
+		}
+
+		result, err := c.StorageUsage(opts)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	usageCmd.Flags().StringP("mailbox", "m", "", "only count one mailbox (default: the whole account)")
+	usageCmd.Flags().IntP("limit", "l", 10, "entries to show per breakdown, by size (0 for all)")
+	usageCmd.Flags().Int("largest", 10, "number of largest messages to list")
+	rootCmd.AddCommand(usageCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestUsageCmd_AggregatesMockServer(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "Big", "size": 9000, "mailboxIds": map[string]bool{"mb-inbox": true},
+				"from": []map[string]string{{"email": "a@example.com"}}, "receivedAt": "2026-01-02T03:04:05Z"},
+			{"id": "M2", "threadId": "T2", "subject": "Small", "size": 100, "mailboxIds": map[string]bool{"mb-inbox": true},
+				"from": []map[string]string{{"email": "b@example.com"}}, "receivedAt": "2025-01-02T03:04:05Z"},
+		},
+		nil,
+	)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "usage", "--largest", "1"))
+	if err != nil {
+		t.Fatalf("usage failed: %v\nstderr=%s", err, stderr)
+	}
+	var result types.UsageResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout)
+	}
+	if result.Total != 2 || result.Size != 9100 || len(result.Largest) != 1 || result.Largest[0].ID != "M1" {
+		t.Errorf("unexpected usage: %+v", result)
+	}
+	if len(result.ByMailbox) != 1 || result.ByMailbox[0].Name != "Inbox" {
+		t.Errorf("unexpected mailbox breakdown: %+v", result.ByMailbox)
+	}
+}
+
+func TestUsageCmd_RejectsNegativeLimits(t *testing.T) {
+	for _, args := range [][]string{{"usage", "--limit", "-1"}, {"usage", "--largest", "-1"}} {
+		if _, _, err := runCLICommand(t, args); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var vacationCmd = &cobra.Command{
+	Use:   "vacation",
+	Short: "Manage the vacation auto-responder",
+	Long: `Manage the vacation (out-of-office) auto-responder on the server.
+
+Use 'vacation show' to see the current configuration, 'vacation set' to
+enable an auto-reply, and 'vacation disable' to turn it off.`,
+}
+
+func init() {
+	rootCmd.AddCommand(vacationCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+var vacationDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Disable the vacation auto-responder",
+	Long: `Disable the vacation auto-responder.
+
+The message, subject, and dates are kept on the server so the same reply
+can be re-enabled later with 'vacation set'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun {
+			result := types.VacationDryRunResult{
+				Operation: "disable",
+				Vacation:  types.VacationResponseInfo{IsEnabled: false},
+			}
+			auditDryRun([]string{"singleton"}, "")
+			return formatter().Format(os.Stdout, result)
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		result, err := c.DisableVacationResponse()
+		if err != nil {
+			return exitError(clientErrorCode(err, "jmap_error"), err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	vacationDisableCmd.Flags().BoolP("dry-run", "n", false, "preview without making changes")
+	vacationCmd.AddCommand(vacationDisableCmd)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var vacationSetCmd = &cobra.Command{
+	Use:   "set [flags]",
+	Short: "Enable the vacation auto-responder",
+	Long: `Enable the vacation auto-responder with the given message.
+
+The configuration is replaced as a whole: dates, subject, and bodies that
+are not given are cleared. Without --from-date the responder starts now;
+without --to-date it runs until disabled.
+
+  fm vacation set --subject "Out of office" --body "Back on Monday." \
+    --from-date 2026-12-20 --to-date 2027-01-04`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := parseVacationOptions(cmd)
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+		if err := client.ValidateVacationOptions(opts); err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun {
+			result := types.VacationDryRunResult{
+				Operation: "set",
+				Vacation:  client.VacationPreview(opts),
+			}
+			auditDryRun([]string{"singleton"}, "")
+			return formatter().Format(os.Stdout, result)
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your token in FM_TOKEN or config file")
+		}
+
+		result, err := c.SetVacationResponse(opts)
+		if err != nil {
+			return exitError(clientErrorCode(err, "jmap_error"), err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	vacationSetCmd.Flags().String("subject", "", "subject of the auto-reply (server default if blank)")
+	vacationSetCmd.Flags().String("body", "", "plain-text auto-reply body")
+	vacationSetCmd.Flags().Bool("body-stdin", false, "read plain-text body from stdin")
+	vacationSetCmd.Flags().String("html-body", "", "HTML auto-reply body")
+	vacationSetCmd.Flags().String("from-date", "", "start replying at this time (RFC 3339 or YYYY-MM-DD)")
+	vacationSetCmd.Flags().String("to-date", "", "stop replying at this time (RFC 3339 or YYYY-MM-DD)")
+	vacationSetCmd.Flags().BoolP("dry-run", "n", false, "preview the configuration without applying it")
+	vacationCmd.AddCommand(vacationSetCmd)
+}
+
+// parseVacationOptions reads the vacation set flags into VacationOptions.
+func parseVacationOptions(cmd *cobra.Command) (client.VacationOptions, error) {
+	var opts client.VacationOptions
+
+	opts.Subject, _ = cmd.Flags().GetString("subject")
+	opts.HTMLBody, _ = cmd.Flags().GetString("html-body")
+
+	body, _ := cmd.Flags().GetString("body")
+	bodyStdin, _ := cmd.Flags().GetBool("body-stdin")
+	if body != "" && bodyStdin {
+		return client.VacationOptions{}, fmt.Errorf("--body and --body-stdin are mutually exclusive")
+	}
+	if bodyStdin {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return client.VacationOptions{}, fmt.Errorf("reading body from stdin: %w", err)
+		}
+		body = string(data)
+	}
+	opts.TextBody = body
+
+	for _, f := range []struct {
+		flag string
+		dest **time.Time
+	}{
+		{"from-date", &opts.FromDate},
+		{"to-date", &opts.ToDate},
+	} {
+		value, _ := cmd.Flags().GetString(f.flag)
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		t, err := parseDate(value)
+		if err != nil {
+			return client.VacationOptions{}, fmt.Errorf("invalid --%s: %w", f.flag, err)
+		}
+		*f.dest = &t
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,182 @@
+// Package audit appends a JSON Lines record of every change fm makes, and
+// of every dry run of one, and reads the records back.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// Log appends entries to the file at Path. Profile, Command, and Operation
+// are filled in on entries that do not set them. Write failures are
+// reported to Warnings, when set, rather than failing the change that was
+// already made.
+type Log struct {
+	Path      string
+	Profile   string
+	Command   string
+	Operation string
+	Warnings  io.Writer
+}
+
+// Check creates the log file if needed and reports whether it can be
+// appended to, so a misconfigured path is caught before any change is made.
+func (l *Log) Check() error {
+	f, err := l.open()
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Record appends e, stamping it with the current time.
+func (l *Log) Record(e types.AuditEntry) {
+	if err := l.Append(e); err != nil && l.Warnings != nil {
+		fmt.Fprintf(l.Warnings, "warning: audit log: %v\n", err)
+	}
+}
+
+// Append writes e as one line. Each entry is written with a single write to
+// a file opened in append mode, so concurrent fm processes do not
+// interleave lines.
+func (l *Log) Append(e types.AuditEntry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Profile == "" {
+		e.Profile = l.Profile
+	}
+	if e.Command == "" {
+		e.Command = l.Command
+	}
+	if e.Operation == "" {
+		e.Operation = l.Operation
+	}
+	if e.IDs == nil {
+		e.IDs = []string{}
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := l.open()
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (l *Log) open() (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0o700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+}
+
+// Read returns every entry in the log at path, oldest first. A missing file
+// holds no entries.
+func Read(path string) ([]types.AuditEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []types.AuditEntry{}, nil
+		}
+		return nil, err
+	}
+
+	entries := []types.AuditEntry{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e types.AuditEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, n, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Filter selects audit entries. Zero fields match everything.
+type Filter struct {
+	ID         string // an object ID, or a destination
+	Operation  string // fm command or JMAP method, case-insensitive
+	Profile    string
+	Since      time.Time
+	Until      time.Time
+	FailedOnly bool // entries with an error or a failed ID
+}
+
+// Match reports whether e passes every condition in f.
+func (f Filter) Match(e types.AuditEntry) bool {
+	if f.Operation != "" && !strings.EqualFold(f.Operation, e.Operation) && !strings.EqualFold(f.Operation, e.Method) {
+		return false
+	}
+	if f.Profile != "" && !strings.EqualFold(f.Profile, e.Profile) {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	if f.ID != "" && !hasID(e, f.ID) {
+		return false
+	}
+	if f.FailedOnly && !Failed(e) {
+		return false
+	}
+	return true
+}
+
+func hasID(e types.AuditEntry, id string) bool {
+	if e.Destination == id {
+		return true
+	}
+	for _, v := range e.IDs {
+		if v == id {
+			return true
+		}
+	}
+	for _, r := range e.Results {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Failed reports whether the call failed as a whole or for any ID.
+func Failed(e types.AuditEntry) bool {
+	if e.Error != "" {
+		return true
+	}
+	for _, r := range e.Results {
+		if !r.OK {
+			return true
+		}
+	}
+	return false
+}
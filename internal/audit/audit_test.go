@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestLog_AppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	log := &Log{Path: path, Profile: "agent", Command: "fm archive M1", Operation: "archive"}
+
+	if err := log.Check(); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if err := log.Append(types.AuditEntry{Method: "Email/set", IDs: []string{"M1"},
+		Results: []types.AuditResult{{ID: "M1", OK: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Append(types.AuditEntry{Operation: "move", DryRun: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.Profile != "agent" || first.Command != "fm archive M1" || first.Operation != "archive" || first.Time.IsZero() {
+		t.Errorf("defaults not applied: %+v", first)
+	}
+	if entries[1].Operation != "move" || !entries[1].DryRun || entries[1].IDs == nil {
+		t.Errorf("unexpected second entry: %+v", entries[1])
+	}
+
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("expected 2 lines, got %d", lines)
+	}
+}
+
+func TestRead_MissingFileIsEmpty(t *testing.T) {
+	entries, err := Read(filepath.Join(t.TempDir(), "none.jsonl"))
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries, got %v, %v", entries, err)
+	}
+}
+
+func TestRead_ReportsBadLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	os.WriteFile(path, []byte("{\"operation\":\"archive\"}\nnot json\n"), 0o600)
+	if _, err := Read(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected a line 2 error, got %v", err)
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := types.AuditEntry{
+		Time:        at,
+		Profile:     "Agent",
+		Operation:   "move",
+		Method:      "Email/set",
+		IDs:         []string{"M1", "M2"},
+		Destination: "mb-receipts",
+		Results:     []types.AuditResult{{ID: "M1", OK: true}, {ID: "M2", Error: "locked"}},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"operation", Filter{Operation: "MOVE"}, true},
+		{"method", Filter{Operation: "email/set"}, true},
+		{"other operation", Filter{Operation: "archive"}, false},
+		{"profile", Filter{Profile: "agent"}, true},
+		{"id", Filter{ID: "M2"}, true},
+		{"destination", Filter{ID: "mb-receipts"}, true},
+		{"missing id", Filter{ID: "M3"}, false},
+		{"since", Filter{Since: at}, true},
+		{"until excludes the bound", Filter{Until: at}, false},
+		{"failed", Filter{FailedOnly: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(e); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	e.Results[1] = types.AuditResult{ID: "M2", OK: true}
+	if (Filter{FailedOnly: true}).Match(e) {
+		t.Error("expected a fully successful entry not to match --failed")
+	}
+}
@@ -0,0 +1,218 @@
+// Package budget caps how many emails fm changes in one run, in the last
+// hour, and in the last day. Hourly and daily use is kept in a local state
+// file so the caps hold across runs.
+package budget
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cboone/fm/internal/statefile"
+	"github.com/cboone/fm/internal/types"
+)
+
+// Limits are the most emails that may be changed in each window. Zero means
+// no limit.
+type Limits struct {
+	PerRun  int
+	PerHour int
+	PerDay  int
+}
+
+// Enabled reports whether any limit is set.
+func (l Limits) Enabled() bool {
+	return l.PerRun > 0 || l.PerHour > 0 || l.PerDay > 0
+}
+
+// ExceededError reports that a window has no allowance left.
+type ExceededError struct {
+	Window string // "run", "hour", or "day"
+	Limit  int
+	Used   int
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("mutation budget of %d emails per %s is used up (%d changed)", e.Limit, e.Window, e.Used)
+}
+
+// Tracker enforces Limits for one account. Use in the current run is kept
+// in memory; hourly and daily use is read from and appended to the state
+// file at Path. Counts are not locked across processes, so fm processes
+// running at the same moment can together exceed a limit by one batch.
+type Tracker struct {
+	Path     string
+	Account  string
+	Limits   Limits
+	Warnings io.Writer
+	Now      func() time.Time // defaults to time.Now
+
+	mu       sync.Mutex
+	runUsed  int
+	exceeded bool
+}
+
+// event is one change of Count emails.
+type event struct {
+	Time  time.Time `json:"time"`
+	Count int       `json:"count"`
+}
+
+type stateFile struct {
+	Accounts map[string][]event `json:"accounts"`
+}
+
+func (t *Tracker) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+// Allow returns how many of n emails may be changed now. When fewer than n
+// are allowed, the error is an *ExceededError for the window that ran out,
+// or the reason the state file could not be read, in which case nothing is
+// allowed.
+func (t *Tracker) Allow(n int) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	windows, err := t.windows()
+	if err != nil {
+		t.exceeded = true
+		return 0, err
+	}
+	allowed := n
+	var tightest *ExceededError
+	for _, w := range windows {
+		if w.Limit == 0 || w.Remaining >= allowed {
+			continue
+		}
+		allowed = w.Remaining
+		tightest = &ExceededError{Window: w.Window, Limit: w.Limit, Used: w.Used}
+	}
+	if tightest != nil {
+		t.exceeded = true
+		return allowed, tightest
+	}
+	return n, nil
+}
+
+// Record counts n changed emails against every window. A failure to update
+// the state file is reported to Warnings, when set, rather than failing the
+// change that was already made.
+func (t *Tracker) Record(n int) {
+	if n <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.runUsed += n
+	if err := t.append(event{Time: t.now().UTC(), Count: n}); err != nil && t.Warnings != nil {
+		fmt.Fprintf(t.Warnings, "warning: budget: %v\n", err)
+	}
+}
+
+// Exceeded reports whether Allow has refused any change in this run.
+func (t *Tracker) Exceeded() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exceeded
+}
+
+// Status returns the limit, use, and remaining allowance of each window.
+func (t *Tracker) Status() ([]types.BudgetWindow, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.windows()
+}
+
+func (t *Tracker) windows() ([]types.BudgetWindow, error) {
+	state, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	now := t.now()
+	events := state.Accounts[t.Account]
+
+	windows := []types.BudgetWindow{{Window: "run", Limit: t.Limits.PerRun, Used: t.runUsed}}
+	for _, spec := range []struct {
+		name   string
+		limit  int
+		length time.Duration
+	}{
+		{"hour", t.Limits.PerHour, time.Hour},
+		{"day", t.Limits.PerDay, 24 * time.Hour},
+	} {
+		w := types.BudgetWindow{Window: spec.name, Limit: spec.limit}
+		start := now.Add(-spec.length)
+		for _, e := range events {
+			if !e.Time.After(start) {
+				continue
+			}
+			if w.Used == 0 {
+				resets := e.Time.Add(spec.length)
+				w.ResetsAt = &resets
+			}
+			w.Used += e.Count
+		}
+		windows = append(windows, w)
+	}
+	for i := range windows {
+		if windows[i].Limit > 0 {
+			windows[i].Remaining = max(windows[i].Limit-windows[i].Used, 0)
+		}
+	}
+	return windows, nil
+}
+
+func (t *Tracker) load() (stateFile, error) {
+	state := stateFile{Accounts: map[string][]event{}}
+	raw, err := os.ReadFile(t.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("reading budget state: %w", err)
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return state, fmt.Errorf("parsing %s: %w", t.Path, err)
+	}
+	if state.Accounts == nil {
+		state.Accounts = map[string][]event{}
+	}
+	return state, nil
+}
+
+// append adds e to the state file, dropping events more than a day old.
+func (t *Tracker) append(e event) error {
+	state, err := t.load()
+	if err != nil {
+		return err
+	}
+	cutoff := e.Time.Add(-24 * time.Hour)
+	for account, events := range state.Accounts {
+		kept := events[:0]
+		for _, old := range events {
+			if old.Time.After(cutoff) {
+				kept = append(kept, old)
+			}
+		}
+		if len(kept) == 0 {
+			delete(state.Accounts, account)
+		} else {
+			state.Accounts[account] = kept
+		}
+	}
+	state.Accounts[t.Account] = append(state.Accounts[t.Account], e)
+
+	if err := statefile.WriteJSON(t.Path, state); err != nil {
+		return fmt.Errorf("writing budget state: %w", err)
+	}
+	return nil
+}
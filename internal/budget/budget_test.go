@@ -0,0 +1,126 @@
+package budget
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestTracker(t *testing.T, limits Limits, now *time.Time) *Tracker {
+	t.Helper()
+	return &Tracker{
+		Path:    filepath.Join(t.TempDir(), "state", "budget.json"),
+		Account: "agent",
+		Limits:  limits,
+		Now:     func() time.Time { return *now },
+	}
+}
+
+func TestTracker_PerRunLimit(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tr := newTestTracker(t, Limits{PerRun: 5}, &now)
+
+	if n, err := tr.Allow(3); n != 3 || err != nil {
+		t.Fatalf("Allow(3) = %d, %v", n, err)
+	}
+	tr.Record(3)
+
+	n, err := tr.Allow(4)
+	var exceeded *ExceededError
+	if n != 2 || !errors.As(err, &exceeded) || exceeded.Window != "run" || exceeded.Used != 3 {
+		t.Fatalf("Allow(4) = %d, %v", n, err)
+	}
+	if !tr.Exceeded() {
+		t.Error("expected Exceeded after a refusal")
+	}
+
+	// A new run starts with a fresh per-run allowance.
+	next := newTestTracker(t, Limits{PerRun: 5}, &now)
+	next.Path = tr.Path
+	if n, err := next.Allow(5); n != 5 || err != nil {
+		t.Errorf("new run Allow(5) = %d, %v", n, err)
+	}
+}
+
+func TestTracker_HourlyAndDailyWindows(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tr := newTestTracker(t, Limits{PerHour: 10, PerDay: 15}, &now)
+
+	tr.Record(8)
+	now = now.Add(30 * time.Minute)
+	if n, err := tr.Allow(5); n != 2 || err == nil {
+		t.Fatalf("Allow(5) within the hour = %d, %v", n, err)
+	}
+
+	// After the first change leaves the hourly window, the daily limit binds.
+	now = now.Add(45 * time.Minute)
+	n, err := tr.Allow(10)
+	var exceeded *ExceededError
+	if n != 7 || !errors.As(err, &exceeded) || exceeded.Window != "day" {
+		t.Fatalf("Allow(10) later = %d, %v", n, err)
+	}
+
+	windows, err := tr.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 3 {
+		t.Fatalf("expected 3 windows, got %+v", windows)
+	}
+	hour, day := windows[1], windows[2]
+	if hour.Used != 0 || hour.Remaining != 10 || hour.ResetsAt != nil {
+		t.Errorf("unexpected hour window: %+v", hour)
+	}
+	want := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	if day.Used != 8 || day.Remaining != 7 || day.ResetsAt == nil || !day.ResetsAt.Equal(want) {
+		t.Errorf("unexpected day window: %+v", day)
+	}
+	if windows[0].Limit != 0 || windows[0].Remaining != 0 {
+		t.Errorf("expected an unlimited run window, got %+v", windows[0])
+	}
+}
+
+func TestTracker_AccountsAndPruning(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tr := newTestTracker(t, Limits{PerDay: 10}, &now)
+	other := &Tracker{Path: tr.Path, Account: "personal", Limits: tr.Limits, Now: tr.Now}
+
+	other.Record(9)
+	if n, err := tr.Allow(10); n != 10 || err != nil {
+		t.Fatalf("use by another profile counted: %d, %v", n, err)
+	}
+
+	now = now.Add(25 * time.Hour)
+	tr.Record(1)
+	state, err := tr.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state.Accounts["personal"]; ok || len(state.Accounts["agent"]) != 1 {
+		t.Errorf("expected day-old events to be dropped, got %+v", state.Accounts)
+	}
+
+	info, err := os.Stat(tr.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestTracker_UnreadableStateAllowsNothing(t *testing.T) {
+	now := time.Now()
+	tr := newTestTracker(t, Limits{PerHour: 10}, &now)
+	if err := os.MkdirAll(filepath.Dir(tr.Path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tr.Path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := tr.Allow(1); n != 0 || err == nil {
+		t.Errorf("Allow(1) = %d, %v; want 0 and an error", n, err)
+	}
+}
@@ -0,0 +1,235 @@
+// Package classify sorts email senders into newsletter, transactional,
+// mailing list, notification, and personal mail. Each sender is scored
+// from the headers of its messages, the local part of its address, and
+// how many messages it sent, and every score comes with the reasons that
+// produced it.
+package classify
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// Categories, in the order ties between them are broken.
+const (
+	Newsletter    = "newsletter"
+	Transactional = "transactional"
+	MailingList   = "mailing_list"
+	Notification  = "notification"
+	Personal      = "personal"
+)
+
+// Categories lists every category.
+var Categories = []string{Newsletter, Transactional, MailingList, Notification, Personal}
+
+// ParseCategory returns the category named s, accepting "mailing-list" and
+// any letter case.
+func ParseCategory(s string) (string, error) {
+	c := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", "_")
+	if slices.Contains(Categories, c) {
+		return c, nil
+	}
+	return "", fmt.Errorf("unsupported category %q (use %s)", s, strings.Join(Categories, ", "))
+}
+
+// Header is one message header.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// weights are the points a signal adds to each category.
+type weights map[string]float64
+
+// signal is a header-based piece of evidence.
+type signal struct {
+	reason string
+	points weights
+}
+
+// headerSignals returns the signals in one message's headers.
+func headerSignals(headers []Header) []signal {
+	var out []signal
+	seen := make(map[string]bool)
+	add := func(s signal) {
+		if !seen[s.reason] {
+			seen[s.reason] = true
+			out = append(out, s)
+		}
+	}
+	for _, h := range headers {
+		name := strings.ToLower(h.Name)
+		value := strings.ToLower(strings.TrimSpace(h.Value))
+		switch {
+		case name == "list-id":
+			add(signal{"List-Id header", weights{MailingList: 2, Newsletter: 1}})
+		case name == "list-post":
+			add(signal{"List-Post header", weights{MailingList: 3}})
+		case name == "list-unsubscribe":
+			add(signal{"List-Unsubscribe header", weights{Newsletter: 2, MailingList: 1}})
+		case name == "list-unsubscribe-post":
+			add(signal{"one-click unsubscribe", weights{Newsletter: 1}})
+		case name == "precedence" && (value == "bulk" || value == "junk"):
+			add(signal{"Precedence: " + value, weights{Newsletter: 2, Notification: 1}})
+		case name == "precedence" && value == "list":
+			add(signal{"Precedence: list", weights{MailingList: 2}})
+		case name == "auto-submitted" && value != "" && value != "no":
+			add(signal{"Auto-Submitted: " + value, weights{Notification: 2, Transactional: 1}})
+		case strings.HasPrefix(name, "x-mailchimp") || name == "x-mc-user" ||
+			strings.HasPrefix(name, "x-campaign") || strings.HasPrefix(name, "x-klaviyo") ||
+			name == "x-mailjet-campaign":
+			add(signal{"marketing ESP header (" + h.Name + ")", weights{Newsletter: 3}})
+		case name == "x-sg-eid" || name == "x-pm-message-id" || strings.HasPrefix(name, "x-mailgun") ||
+			name == "x-ses-outgoing" || name == "x-mandrill-user":
+			add(signal{"transactional ESP header (" + h.Name + ")", weights{Transactional: 2, Notification: 1}})
+		case name == "x-mailman-version" || name == "x-google-group-id" || name == "mailing-list":
+			add(signal{"list server header (" + h.Name + ")", weights{MailingList: 3}})
+		}
+	}
+	return out
+}
+
+// localPartPatterns map words in an address's local part to categories.
+var localPartPatterns = []struct {
+	words  []string
+	points weights
+}{
+	{[]string{"noreply", "donotreply", "noreplies"}, weights{Notification: 2, Transactional: 1}},
+	{[]string{"notification", "notifications", "notify", "alert", "alerts"}, weights{Notification: 3}},
+	{[]string{"receipt", "receipts", "billing", "invoice", "invoices", "order", "orders",
+		"payment", "payments", "shipping", "account", "accounts", "security", "verify"}, weights{Transactional: 3}},
+	{[]string{"news", "newsletter", "newsletters", "marketing", "promo", "promotions",
+		"offers", "deals", "digest", "editor"}, weights{Newsletter: 2}},
+	{[]string{"request", "bounces", "owner"}, weights{MailingList: 2}},
+}
+
+// localPartSignal returns the signal in the local part of addr, if any.
+// "no-reply" and "do_not_reply" match "noreply" and "donotreply".
+func localPartSignal(addr string) (signal, bool) {
+	local, _, _ := strings.Cut(strings.ToLower(addr), "@")
+	words := strings.FieldsFunc(local, func(r rune) bool { return r < 'a' || r > 'z' })
+	joined := strings.Join(words, "")
+
+	sig := signal{reason: fmt.Sprintf("sender address %q", local), points: weights{}}
+	for _, p := range localPartPatterns {
+		for _, w := range p.words {
+			if slices.Contains(words, w) || joined == w {
+				for cat, n := range p.points {
+					sig.points[cat] += n
+				}
+				break
+			}
+		}
+	}
+	return sig, len(sig.points) > 0
+}
+
+// sender is the evidence gathered for one address.
+type sender struct {
+	messages int
+	flagged  int // messages with any header signal
+	signals  map[string]signal
+	counts   map[string]int // messages carrying each signal
+}
+
+// Classifier gathers messages and classifies their senders. Volume is
+// counted over the messages added, so add all of them before classifying.
+type Classifier struct {
+	senders map[string]*sender
+}
+
+// New returns an empty Classifier.
+func New() *Classifier {
+	return &Classifier{senders: make(map[string]*sender)}
+}
+
+// Add records one message from the address from.
+func (c *Classifier) Add(from string, headers []Header) {
+	key := strings.ToLower(from)
+	s, ok := c.senders[key]
+	if !ok {
+		s = &sender{signals: make(map[string]signal), counts: make(map[string]int)}
+		c.senders[key] = s
+	}
+	s.messages++
+	signals := headerSignals(headers)
+	if len(signals) > 0 {
+		s.flagged++
+	}
+	for _, sig := range signals {
+		s.signals[sig.reason] = sig
+		s.counts[sig.reason]++
+	}
+}
+
+// Volume thresholds: few messages look like a conversation, many look
+// automated.
+const (
+	personalVolume = 2
+	bulkVolume     = 10
+)
+
+// Classify returns the category of from, its score between 0 and 1, and
+// the reasons for it. Header signals count in proportion to the share of
+// the sender's messages that carry them, and the share without any count
+// toward personal unless the address itself looks automated.
+func (c *Classifier) Classify(from string) types.Classification {
+	key := strings.ToLower(from)
+	s := c.senders[key]
+	if s == nil {
+		s = &sender{messages: 1}
+	}
+
+	points := make(map[string]float64)
+	var reasons []string
+	for _, reason := range slices.Sorted(maps.Keys(s.signals)) {
+		share := float64(s.counts[reason]) / float64(s.messages)
+		for cat, p := range s.signals[reason].points {
+			points[cat] += p * share
+		}
+		reasons = append(reasons, reason)
+	}
+	sig, automated := localPartSignal(key)
+	if automated {
+		for cat, p := range sig.points {
+			points[cat] += p
+		}
+		reasons = append(reasons, sig.reason)
+	} else if plain := s.messages - s.flagged; plain > 0 {
+		points[Personal] += 2 * float64(plain) / float64(s.messages)
+		if plain == s.messages {
+			reasons = append(reasons, "no list or bulk-mail headers")
+		} else {
+			reasons = append(reasons, fmt.Sprintf("%d of %d messages without list or bulk-mail headers", plain, s.messages))
+		}
+	}
+	switch {
+	case s.messages <= personalVolume:
+		points[Personal]++
+		reasons = append(reasons, fmt.Sprintf("%d messages from this sender", s.messages))
+	case s.messages >= bulkVolume:
+		points[Newsletter]++
+		points[Notification]++
+		reasons = append(reasons, fmt.Sprintf("%d messages from this sender", s.messages))
+	}
+
+	best, total := Personal, 0.0
+	for _, cat := range Categories {
+		total += points[cat]
+	}
+	for i := len(Categories) - 1; i >= 0; i-- {
+		if points[Categories[i]] >= points[best] {
+			best = Categories[i]
+		}
+	}
+	return types.Classification{
+		Category: best,
+		Score:    math.Round(points[best]/(total+1)*100) / 100,
+		Reasons:  reasons,
+	}
+}
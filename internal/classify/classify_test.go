@@ -0,0 +1,99 @@
+package classify
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		messages int
+		headers  []Header
+		want     string
+		reason   string
+	}{
+		{
+			name: "marketing newsletter", from: "hello@shop.example", messages: 4,
+			headers: []Header{{"List-Unsubscribe", "<https://shop.example/u>"}, {"X-Mailchimp-Campaign", "abc"}, {"Precedence", "bulk"}},
+			want:    Newsletter, reason: "marketing ESP header (X-Mailchimp-Campaign)",
+		},
+		{
+			name: "receipt through SendGrid", from: "receipts@store.example", messages: 3,
+			headers: []Header{{"X-SG-EID", "xyz"}, {"List-Unsubscribe", "<mailto:u@store.example>"}},
+			want:    Transactional, reason: `sender address "receipts"`,
+		},
+		{
+			name: "discussion list", from: "dev@lists.example.org", messages: 6,
+			headers: []Header{{"List-Id", "<dev.lists.example.org>"}, {"List-Post", "<mailto:dev@lists.example.org>"}, {"Precedence", "list"}},
+			want:    MailingList, reason: "List-Post header",
+		},
+		{
+			name: "no-reply alerts", from: "no-reply@alerts.example", messages: 12,
+			headers: []Header{{"Auto-Submitted", "auto-generated"}},
+			want:    Notification, reason: "12 messages from this sender",
+		},
+		{
+			name: "a person", from: "Alice@Example.com", messages: 1,
+			want: Personal, reason: "no list or bulk-mail headers",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			for range tt.messages {
+				c.Add(tt.from, tt.headers)
+			}
+			got := c.Classify(strings.ToUpper(tt.from))
+			if got.Category != tt.want {
+				t.Errorf("category = %s, want %s (%+v)", got.Category, tt.want, got)
+			}
+			if got.Score <= 0 || got.Score >= 1 {
+				t.Errorf("score = %v, want between 0 and 1", got.Score)
+			}
+			if !slices.Contains(got.Reasons, tt.reason) {
+				t.Errorf("expected reason %q in %q", tt.reason, got.Reasons)
+			}
+		})
+	}
+}
+
+func TestClassify_SharedSignalsWeighByShare(t *testing.T) {
+	c := New()
+	c.Add("bob@example.com", []Header{{"List-Unsubscribe", "<mailto:u@example.com>"}})
+	for range 4 {
+		c.Add("bob@example.com", nil)
+	}
+	if got := c.Classify("bob@example.com"); got.Category != Personal {
+		t.Errorf("one list message in five should not make bob a newsletter: %+v", got)
+	}
+}
+
+func TestClassify_HighVolumeWithoutHeadersIsLessCertain(t *testing.T) {
+	c := New()
+	c.Add("carol@example.com", nil)
+	for range 15 {
+		c.Add("robot@example.com", nil)
+	}
+	person, robot := c.Classify("carol@example.com"), c.Classify("robot@example.com")
+	if person.Category != Personal || robot.Category != Personal {
+		t.Fatalf("expected both personal: %+v %+v", person, robot)
+	}
+	if robot.Score >= person.Score {
+		t.Errorf("expected a lower score for high volume: %v >= %v", robot.Score, person.Score)
+	}
+}
+
+func TestParseCategory(t *testing.T) {
+	for in, want := range map[string]string{"newsletter": Newsletter, "Mailing-List": MailingList, " personal ": Personal} {
+		got, err := ParseCategory(in)
+		if err != nil || got != want {
+			t.Errorf("ParseCategory(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseCategory("spam"); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+}
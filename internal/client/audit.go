@@ -0,0 +1,197 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+
+	"github.com/cboone/fm/internal/audit"
+	"github.com/cboone/fm/internal/types"
+)
+
+// SetAuditLog makes the client record every mutating method call in log,
+// including calls refused in read-only mode. A nil log turns auditing off.
+func (c *Client) SetAuditLog(log *audit.Log) {
+	c.audit = log
+}
+
+// isMutating reports whether a JMAP method changes server state: Email/set,
+// Mailbox/set, SieveScript/set, VacationResponse/set, Email/import,
+// Email/copy, and so on.
+func isMutating(method string) bool {
+	for _, suffix := range mutatingMethodSuffixes {
+		if strings.HasSuffix(method, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditSetArgs is the part of a /set, /copy, or /import request the audit
+// log records.
+type auditSetArgs struct {
+	Create                  map[string]json.RawMessage `json:"create"`
+	Update                  map[string]json.RawMessage `json:"update"`
+	Destroy                 []string                   `json:"destroy"`
+	Emails                  map[string]json.RawMessage `json:"emails"`
+	OnSuccessActivateScript string                     `json:"onSuccessActivateScript"`
+}
+
+// auditSetResponse is the part of a /set response the audit log records.
+type auditSetResponse struct {
+	Created      map[string]struct{ ID string } `json:"created"`
+	NotCreated   map[string]auditSetError       `json:"notCreated"`
+	Updated      map[string]json.RawMessage     `json:"updated"`
+	NotUpdated   map[string]auditSetError       `json:"notUpdated"`
+	NotDestroyed map[string]auditSetError       `json:"notDestroyed"`
+}
+
+type auditSetError struct {
+	Type        string  `json:"type"`
+	Description *string `json:"description"`
+}
+
+func (e auditSetError) String() string {
+	if e.Description != nil && *e.Description != "" {
+		return *e.Description
+	}
+	if e.Type != "" {
+		return e.Type
+	}
+	return "unknown error"
+}
+
+// auditRequest records each mutating call in req with its outcome from resp,
+// or with err when the request failed or was refused.
+func (c *Client) auditRequest(req *jmap.Request, resp *jmap.Response, err error) {
+	if c.audit == nil {
+		return
+	}
+	for _, call := range req.Calls {
+		if !isMutating(call.Name) {
+			continue
+		}
+		var result *jmap.Invocation
+		if resp != nil {
+			for _, inv := range resp.Responses {
+				if inv.CallID == call.CallID {
+					result = inv
+					break
+				}
+			}
+		}
+		c.audit.Record(auditEntry(call, result, err))
+	}
+}
+
+// auditEntry builds the log entry for one mutating call.
+func auditEntry(call *jmap.Invocation, result *jmap.Invocation, err error) types.AuditEntry {
+	entry := types.AuditEntry{Method: call.Name, IDs: []string{}}
+
+	var args auditSetArgs
+	if data, merr := json.Marshal(call.Args); merr == nil {
+		_ = json.Unmarshal(data, &args)
+	}
+
+	var updates, creates []string
+	for id, patch := range args.Update {
+		updates = append(updates, id)
+		if dest := patchDestination(patch); dest != "" {
+			entry.Destination = dest
+		}
+	}
+	for id, obj := range args.Create {
+		creates = append(creates, id)
+		if dest := patchDestination(obj); dest != "" {
+			entry.Destination = dest
+		}
+	}
+	for id := range args.Emails {
+		creates = append(creates, id)
+	}
+	sort.Strings(updates)
+	sort.Strings(creates)
+	entry.IDs = append(entry.IDs, updates...)
+	entry.IDs = append(entry.IDs, creates...)
+	entry.IDs = append(entry.IDs, args.Destroy...)
+	if args.OnSuccessActivateScript != "" {
+		entry.IDs = append(entry.IDs, args.OnSuccessActivateScript)
+	}
+
+	if err == nil && result == nil {
+		err = fmt.Errorf("no response from server")
+	}
+	if err == nil {
+		if merr, ok := result.Args.(*jmap.MethodError); ok {
+			err = merr
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		for _, id := range entry.IDs {
+			entry.Results = append(entry.Results, types.AuditResult{ID: id, Error: entry.Error})
+		}
+		return entry
+	}
+
+	var r auditSetResponse
+	if data, merr := json.Marshal(result.Args); merr == nil {
+		_ = json.Unmarshal(data, &r)
+	}
+	for _, id := range updates {
+		if _, ok := r.Updated[id]; ok {
+			entry.Results = append(entry.Results, types.AuditResult{ID: id, OK: true})
+		} else if setErr, ok := r.NotUpdated[id]; ok {
+			entry.Results = append(entry.Results, types.AuditResult{ID: id, Error: setErr.String()})
+		} else {
+			entry.Results = append(entry.Results, types.AuditResult{ID: id, Error: "no status returned by server"})
+		}
+	}
+	for _, id := range creates {
+		if created, ok := r.Created[id]; ok {
+			entry.Results = append(entry.Results, types.AuditResult{ID: created.ID, OK: true})
+		} else if setErr, ok := r.NotCreated[id]; ok {
+			entry.Results = append(entry.Results, types.AuditResult{ID: id, Error: setErr.String()})
+		} else {
+			entry.Results = append(entry.Results, types.AuditResult{ID: id, Error: "no status returned by server"})
+		}
+	}
+	for _, id := range args.Destroy {
+		if setErr, ok := r.NotDestroyed[id]; ok {
+			entry.Results = append(entry.Results, types.AuditResult{ID: id, Error: setErr.String()})
+		} else {
+			entry.Results = append(entry.Results, types.AuditResult{ID: id, OK: true})
+		}
+	}
+	if args.OnSuccessActivateScript != "" {
+		entry.Results = append(entry.Results, types.AuditResult{ID: args.OnSuccessActivateScript, OK: true})
+	}
+	return entry
+}
+
+// patchDestination returns the mailbox an Email/set patch or create moves
+// the message into: the single mailbox in a replaced mailboxIds, or the
+// mailbox added by a "mailboxIds/<id>": true patch.
+func patchDestination(raw json.RawMessage) string {
+	var patch map[string]json.RawMessage
+	if json.Unmarshal(raw, &patch) != nil {
+		return ""
+	}
+	if ids, ok := patch["mailboxIds"]; ok {
+		var set map[string]bool
+		if json.Unmarshal(ids, &set) == nil && len(set) == 1 {
+			for id := range set {
+				return id
+			}
+		}
+	}
+	for key, value := range patch {
+		if id, ok := strings.CutPrefix(key, "mailboxIds/"); ok && string(value) == "true" {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/audit"
+)
+
+func TestAudit_RecordsMoveWithPerIDResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	desc := "message is locked"
+	c := &Client{
+		accountID: "acct-1",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/set", CallID: "0", Args: &email.SetResponse{
+					Updated:    map[jmap.ID]*email.Email{"M1": {}},
+					NotUpdated: map[jmap.ID]*jmap.SetError{"M2": {Type: "forbidden", Description: &desc}},
+				}},
+			}}, nil
+		},
+	}
+	c.SetAuditLog(&audit.Log{Path: path, Profile: "agent", Command: "fm move M1 M2 --to Receipts", Operation: "move"})
+
+	c.MoveEmails([]string{"M1", "M2"}, "mb-receipts")
+
+	entries, err := audit.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Method != "Email/set" || e.Operation != "move" || e.Profile != "agent" || e.DryRun {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e.Destination != "mb-receipts" {
+		t.Errorf("destination = %q, want mb-receipts", e.Destination)
+	}
+	if len(e.IDs) != 2 || e.IDs[0] != "M1" || e.IDs[1] != "M2" {
+		t.Errorf("ids = %v", e.IDs)
+	}
+	if len(e.Results) != 2 || !e.Results[0].OK || e.Results[1].OK || e.Results[1].Error != desc {
+		t.Errorf("results = %+v", e.Results)
+	}
+}
+
+func TestAudit_SkipsReadsAndRecordsRefusals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	c := sieveTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		return &jmap.Response{}, nil
+	})
+	c.SetAuditLog(&audit.Log{Path: path, Operation: "sieve activate"})
+
+	req := &jmap.Request{}
+	req.Invoke(&email.Get{Account: "acct-1"})
+	if _, err := c.Do(req); err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetReadOnly(true)
+	if _, err := c.ActivateSieveScript("S1"); err == nil {
+		t.Fatal("expected activation to be refused")
+	}
+
+	entries, err := audit.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the refused call to be logged, got %d entries", len(entries))
+	}
+	e := entries[0]
+	if e.Method != "SieveScript/set" {
+		t.Errorf("method = %q", e.Method)
+	}
+	if e.Error == "" || len(e.IDs) != 1 || e.IDs[0] != "S1" || e.Results[0].OK {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
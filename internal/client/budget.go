@@ -0,0 +1,44 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/cboone/fm/internal/budget"
+)
+
+// SetBudget makes the client count every email it changes against b and
+// refuse changes, with ErrForbidden, once b has no allowance left. A nil
+// tracker turns the budget off.
+func (c *Client) SetBudget(b *budget.Tracker) {
+	c.budget = b
+}
+
+// BudgetExceeded reports whether any change in this run was refused because
+// the mutation budget was used up.
+func (c *Client) BudgetExceeded() bool {
+	return c.budget != nil && c.budget.Exceeded()
+}
+
+// takeBudget splits ids into those the budget allows to be changed now and
+// per-ID errors for the rest.
+func (c *Client) takeBudget(ids []string) (allowed []string, refused []string) {
+	if c.budget == nil {
+		return ids, nil
+	}
+	n, err := c.budget.Allow(len(ids))
+	if err == nil {
+		return ids, nil
+	}
+	forbidden := &ErrForbidden{Operation: "Email/set", Reason: err.Error()}
+	for _, id := range ids[n:] {
+		refused = append(refused, fmt.Sprintf("%s: %v", id, forbidden))
+	}
+	return ids[:n], refused
+}
+
+// spendBudget counts n changed emails against the budget.
+func (c *Client) spendBudget(n int) {
+	if c.budget != nil {
+		c.budget.Record(n)
+	}
+}
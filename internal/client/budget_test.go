@@ -0,0 +1,94 @@
+package client
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/budget"
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestBudget_RefusesEmailsPastTheLimit(t *testing.T) {
+	var sent []jmap.ID
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			setReq := req.Calls[0].Args.(*email.Set)
+			updated := make(map[jmap.ID]*email.Email, len(setReq.Update))
+			for id := range setReq.Update {
+				sent = append(sent, id)
+				updated[id] = &email.Email{}
+			}
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/set", CallID: "0", Args: &email.SetResponse{Updated: updated}},
+			}}, nil
+		},
+	}
+	tracker := &budget.Tracker{
+		Path:   filepath.Join(t.TempDir(), "budget.json"),
+		Limits: budget.Limits{PerRun: 3, PerHour: 4},
+	}
+	c.SetBudget(tracker)
+
+	succeeded, errs := c.MoveEmails([]string{"M1", "M2"}, "mb-archive")
+	if len(succeeded) != 2 || len(errs) != 0 || c.BudgetExceeded() {
+		t.Fatalf("first batch: succeeded=%v errs=%v", succeeded, errs)
+	}
+
+	succeeded, errs = c.MarkAsRead([]string{"M3", "M4", "M5"})
+	if len(succeeded) != 1 || succeeded[0] != "M3" {
+		t.Errorf("expected only M3 to be changed, got %v", succeeded)
+	}
+	if len(errs) != 2 || !strings.HasPrefix(errs[0], "M4: forbidden operation: Email/set: mutation budget of 3 emails per run") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if len(sent) != 3 {
+		t.Errorf("expected refused emails not to be sent, sent %v", sent)
+	}
+	if !c.BudgetExceeded() {
+		t.Error("expected BudgetExceeded after a refusal")
+	}
+
+	windows, err := tracker.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if windows[1].Used != 3 || windows[1].Remaining != 1 {
+		t.Errorf("unexpected hour window: %+v", windows[1])
+	}
+}
+
+func TestBudget_RefusesDraftWhenUsedUp(t *testing.T) {
+	calls := 0
+	c := testClientForDraft(func(req *jmap.Request) (*jmap.Response, error) {
+		calls++
+		return mockDraftCreateSuccess("M-draft")(req)
+	})
+	c.SetBudget(&budget.Tracker{
+		Path:   filepath.Join(t.TempDir(), "budget.json"),
+		Limits: budget.Limits{PerDay: 1},
+	})
+	opts := DraftOptions{
+		Mode:    DraftModeNew,
+		To:      []types.Address{{Email: "alice@example.com"}},
+		Subject: "Hello",
+		Body:    "Hi",
+	}
+
+	if _, err := c.CreateDraft(opts); err != nil {
+		t.Fatalf("first draft: %v", err)
+	}
+	_, err := c.CreateDraft(opts)
+	var forbidden *ErrForbidden
+	if !errors.As(err, &forbidden) || !strings.Contains(forbidden.Reason, "per day") {
+		t.Fatalf("expected a budget refusal, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the refused draft not to be sent, got %d calls", calls)
+	}
+}
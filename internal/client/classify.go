@@ -0,0 +1,119 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/searchsnippet"
+
+	"github.com/cboone/fm/internal/classify"
+	"github.com/cboone/fm/internal/types"
+)
+
+// classifyHeaders converts JMAP headers for the classify package.
+func classifyHeaders(headers []*email.Header) []classify.Header {
+	out := make([]classify.Header, 0, len(headers))
+	for _, h := range headers {
+		out = append(out, classify.Header{Name: h.Name, Value: h.Value})
+	}
+	return out
+}
+
+// senderAddress returns the lowercased address of the first From entry.
+func senderAddress(e *email.Email) string {
+	if len(e.From) == 0 {
+		return ""
+	}
+	return strings.ToLower(e.From[0].Email)
+}
+
+// listByCategory reads every email matching filter in the given order,
+// classifies their senders across the whole result, and returns the page
+// at offset of the emails whose sender is in category. Total counts the
+// emails in the category. When text is set, search snippets are fetched
+// for the page.
+func (c *Client) listByCategory(what string, filter email.Filter, order []*email.SortComparator, category string, offset int64, limit uint64, text string) (types.EmailListResult, error) {
+	classifier := classify.New()
+	var emails []*email.Email
+	properties := append(slices.Clone(summaryProperties), "headers")
+	_, err := c.scanEmailsSorted(what, filter, order, properties, nil, func(e *email.Email) {
+		classifier.Add(senderAddress(e), classifyHeaders(e.Headers))
+		emails = append(emails, e)
+	})
+	if err != nil {
+		return types.EmailListResult{}, err
+	}
+
+	classes := make(map[string]string)
+	var matched []*email.Email
+	for _, e := range emails {
+		addr := senderAddress(e)
+		cat, ok := classes[addr]
+		if !ok {
+			cat = classifier.Classify(addr).Category
+			classes[addr] = cat
+		}
+		if cat == category {
+			matched = append(matched, e)
+		}
+	}
+
+	result := types.EmailListResult{Total: uint64(len(matched)), Offset: offset, Emails: []types.EmailSummary{}}
+	if offset >= int64(len(matched)) {
+		return result, nil
+	}
+	page := matched[offset:]
+	if limit > 0 && uint64(len(page)) > limit {
+		page = page[:limit]
+	}
+	result.Emails = convertSummaries(page)
+	for i := range result.Emails {
+		result.Emails[i].Category = category
+	}
+
+	if text != "" && len(page) > 0 {
+		snippets, err := c.searchSnippets(filter, page)
+		if err == nil {
+			for i := range result.Emails {
+				result.Emails[i].Snippet = snippets[result.Emails[i].ID]
+			}
+		}
+	}
+	return result, nil
+}
+
+// searchSnippets returns the search snippet previews for emails, by ID.
+func (c *Client) searchSnippets(filter email.Filter, emails []*email.Email) (map[string]string, error) {
+	ids := make([]jmap.ID, len(emails))
+	for i, e := range emails {
+		ids[i] = e.ID
+	}
+	req := &jmap.Request{}
+	req.Invoke(&searchSnippetGet{searchsnippet.Get{
+		Account:  c.accountID,
+		Filter:   filter,
+		EmailIDs: ids,
+	}})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("search snippets: %w", err)
+	}
+	snippets := make(map[string]string)
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *searchsnippet.GetResponse:
+			for _, s := range r.List {
+				if s.Preview != "" {
+					snippets[string(s.Email)] = s.Preview
+				}
+			}
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("search snippets: %s", r.Error())
+		}
+	}
+	return snippets, nil
+}
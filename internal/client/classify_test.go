@@ -0,0 +1,134 @@
+package client
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+	"git.sr.ht/~rockorager/go-jmap/mail/searchsnippet"
+
+	"github.com/cboone/fm/internal/classify"
+)
+
+// categoryTestEmails are two newsletter issues, a receipt, and two personal
+// messages, newest first.
+func categoryTestEmails() []*email.Email {
+	news := []*mail.Address{{Email: "news@shop.example"}}
+	unsubscribe := []*email.Header{{Name: "List-Unsubscribe", Value: "<https://shop.example/u>"}, {Name: "X-Mailchimp-Campaign", Value: "c1"}}
+	return []*email.Email{
+		{ID: "N2", From: news, Subject: "Sale", Headers: unsubscribe},
+		{ID: "P2", From: []*mail.Address{{Email: "alice@example.com"}}, Subject: "Re: Lunch"},
+		{ID: "R1", From: []*mail.Address{{Email: "receipts@store.example"}}, Subject: "Your order", Headers: []*email.Header{{Name: "X-SG-EID", Value: "x"}}},
+		{ID: "N1", From: news, Subject: "New arrivals", Headers: unsubscribe},
+		{ID: "P1", From: []*mail.Address{{Email: "bob@example.org"}}, Subject: "Hello"},
+	}
+}
+
+func categoryTestClient(t *testing.T, requests *[]*jmap.Request) *Client {
+	t.Helper()
+	emails := categoryTestEmails()
+	return &Client{
+		accountID:    "acct-1",
+		mailboxCache: []*mailbox.Mailbox{{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox}},
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			*requests = append(*requests, req)
+			if get, ok := req.Calls[0].Args.(*searchSnippetGet); ok {
+				var list []*searchsnippet.SearchSnippet
+				for _, id := range get.EmailIDs {
+					list = append(list, &searchsnippet.SearchSnippet{Email: id, Preview: "snippet " + string(id)})
+				}
+				return &jmap.Response{Responses: []*jmap.Invocation{
+					{Name: "SearchSnippet/get", CallID: "0", Args: &searchsnippet.GetResponse{List: list}},
+				}}, nil
+			}
+			return scanPage(emails), nil
+		},
+	}
+}
+
+func TestListEmails_Category(t *testing.T) {
+	var requests []*jmap.Request
+	c := categoryTestClient(t, &requests)
+
+	result, err := c.ListEmails(ListOptions{MailboxNameOrID: "inbox", Category: classify.Personal, Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || len(result.Emails) != 1 || result.Emails[0].ID != "P1" || result.Emails[0].Category != classify.Personal {
+		t.Errorf("unexpected personal page: %+v", result)
+	}
+
+	get := requests[0].Calls[1].Args.(*email.Get)
+	if get.Properties[len(get.Properties)-1] != "headers" {
+		t.Errorf("expected headers to be fetched, got %v", get.Properties)
+	}
+
+	result, err = c.ListEmails(ListOptions{MailboxNameOrID: "inbox", Category: classify.Transactional})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 1 || result.Emails[0].ID != "R1" {
+		t.Errorf("unexpected transactional result: %+v", result)
+	}
+
+	result, err = c.ListEmails(ListOptions{MailboxNameOrID: "inbox", Category: classify.Newsletter, Offset: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || len(result.Emails) != 0 {
+		t.Errorf("expected an empty page past the end, got %+v", result)
+	}
+}
+
+func TestSearchEmails_CategoryWithSnippets(t *testing.T) {
+	var requests []*jmap.Request
+	c := categoryTestClient(t, &requests)
+
+	result, err := c.SearchEmails(SearchOptions{Text: "sale", Category: classify.Newsletter, Limit: 25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || len(result.Emails) != 2 || result.Emails[0].ID != "N2" || result.Emails[1].ID != "N1" {
+		t.Fatalf("unexpected newsletter result: %+v", result)
+	}
+	if result.Emails[0].Snippet != "snippet N2" {
+		t.Errorf("expected snippets for the page, got %q", result.Emails[0].Snippet)
+	}
+	if len(requests) != 2 {
+		t.Errorf("expected a query and a snippet request, got %d", len(requests))
+	}
+}
+
+func TestAggregateSummary_Categories(t *testing.T) {
+	var requests []*jmap.Request
+	c := categoryTestClient(t, &requests)
+
+	result, err := c.AggregateSummary(SummaryOptions{MailboxID: "mb-inbox", Limit: 10, Newsletters: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Newsletters) != 1 || result.Newsletters[0].Email != "news@shop.example" || result.Newsletters[0].Score == 0 {
+		t.Errorf("expected only the shop newsletter, got %+v", result.Newsletters)
+	}
+	if len(result.Categories) != 3 {
+		t.Fatalf("expected 3 categories, got %+v", result.Categories)
+	}
+	want := []struct {
+		category string
+		senders  int
+		count    int
+	}{{classify.Newsletter, 1, 2}, {classify.Transactional, 1, 1}, {classify.Personal, 2, 2}}
+	for i, w := range want {
+		got := result.Categories[i]
+		if got.Category != w.category || got.Senders != w.senders || got.Count != w.count {
+			t.Errorf("category %d = %+v, want %+v", i, got, w)
+		}
+	}
+	for _, s := range result.TopSenders {
+		if s.Category == "" {
+			t.Errorf("expected every top sender to be classified: %+v", s)
+		}
+	}
+}
@@ -13,12 +13,18 @@ import (
 	"git.sr.ht/~rockorager/go-jmap/mail"
 	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
 
+	"github.com/cboone/fm/internal/audit"
+	"github.com/cboone/fm/internal/budget"
 	"github.com/cboone/fm/internal/types"
 )
 
 // ErrNotFound indicates that a requested resource was not found.
 var ErrNotFound = fmt.Errorf("not found")
 
+// ErrStateMismatch indicates that an object changed on the server since it
+// was read, so a conditional update (ifInState) was rejected.
+var ErrStateMismatch = fmt.Errorf("state mismatch")
+
 const maxRetries = 3
 const defaultBatchSize = 50
 
@@ -27,23 +33,57 @@ type Client struct {
 	jmap         *jmap.Client
 	accountID    jmap.ID
 	mailboxCache []*mailbox.Mailbox
+	readOnly     bool
+	audit        *audit.Log
+	budget       *budget.Tracker
 	doFunc       func(*jmap.Request) (*jmap.Response, error)
 	uploadFunc   func(jmap.ID, io.Reader) (*jmap.UploadResponse, error)
 	downloadFunc func(jmap.ID, jmap.ID) (io.ReadCloser, error)
+
+	// unsubscribeHTTP sends one-click unsubscribe requests; nil uses
+	// unsubscribe.NewHTTPClient.
+	unsubscribeHTTP *http.Client
+}
+
+// TokenSource supplies the bearer token for each request. Refresh is called
+// when the server rejects the token with 401 and returns a replacement, or
+// an error if the token cannot be replaced.
+type TokenSource interface {
+	Token() (string, error)
+	Refresh() (string, error)
+}
+
+// StaticToken is a TokenSource for API tokens, which cannot be refreshed.
+type StaticToken string
+
+// Token returns the API token.
+func (t StaticToken) Token() (string, error) { return string(t), nil }
+
+// Refresh always fails: an API token rejected by the server stays rejected.
+func (t StaticToken) Refresh() (string, error) {
+	return "", fmt.Errorf("token rejected by server")
 }
 
 // New creates a Client, authenticates, and discovers the session.
 func New(sessionURL, token, accountID string) (*Client, error) {
+	return NewWithTokenSource(sessionURL, StaticToken(token), accountID)
+}
+
+// NewWithTokenSource is like New but takes its bearer token from source,
+// so an expired or rejected token can be refreshed and the request retried.
+func NewWithTokenSource(sessionURL string, source TokenSource, accountID string) (*Client, error) {
 	httpClient := &http.Client{
-		Transport: &retryTransport{base: http.DefaultTransport},
-		Timeout:   30 * time.Second,
+		Transport: &authTransport{
+			base:   &retryTransport{base: http.DefaultTransport},
+			source: source,
+		},
+		Timeout: 30 * time.Second,
 	}
 
 	jc := &jmap.Client{
 		SessionEndpoint: sessionURL,
 		HttpClient:      httpClient,
 	}
-	jc.WithAccessToken(token)
 
 	if err := jc.Authenticate(); err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
@@ -74,16 +114,60 @@ func (c *Client) Session() *jmap.Session {
 	return c.jmap.Session
 }
 
-// Do executes a JMAP request.
+// SetReadOnly turns read-only mode on or off. In read-only mode every
+// mutating method call and every upload is refused with ErrForbidden before
+// anything is sent to the server.
+func (c *Client) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
+
+// ReadOnly reports whether read-only mode is on.
+func (c *Client) ReadOnly() bool {
+	return c.readOnly
+}
+
+// mutatingMethodSuffixes are the JMAP method kinds that change server state:
+// Email/set, Mailbox/set, SieveScript/set, VacationResponse/set,
+// Email/import, Email/copy, and so on.
+var mutatingMethodSuffixes = []string{"/set", "/copy", "/import"}
+
+// checkWritable refuses req in read-only mode if any call in it mutates.
+func (c *Client) checkWritable(req *jmap.Request) error {
+	if !c.readOnly {
+		return nil
+	}
+	for _, call := range req.Calls {
+		if isMutating(call.Name) {
+			return &ErrForbidden{Operation: call.Name, Reason: "read-only mode is enabled"}
+		}
+	}
+	return nil
+}
+
+// Do executes a JMAP request. Mutating calls are recorded in the audit log,
+// when one is set.
 func (c *Client) Do(req *jmap.Request) (*jmap.Response, error) {
+	if err := c.checkWritable(req); err != nil {
+		c.auditRequest(req, nil, err)
+		return nil, err
+	}
+
+	var resp *jmap.Response
+	var err error
 	if c.doFunc != nil {
-		return c.doFunc(req)
+		resp, err = c.doFunc(req)
+	} else {
+		resp, err = c.jmap.Do(req)
 	}
-	return c.jmap.Do(req)
+	c.auditRequest(req, resp, err)
+	return resp, err
 }
 
 // Upload sends binary data to the server and returns the blob metadata.
 func (c *Client) Upload(accountID jmap.ID, blob io.Reader) (*jmap.UploadResponse, error) {
+	if c.readOnly {
+		return nil, &ErrForbidden{Operation: "upload", Reason: "read-only mode is enabled"}
+	}
 	if c.uploadFunc != nil {
 		return c.uploadFunc(accountID, blob)
 	}
@@ -133,9 +217,45 @@ func (c *Client) SessionInfo() types.SessionInfo {
 		Username:     s.Username,
 		Accounts:     accounts,
 		Capabilities: caps,
+		ReadOnly:     c.readOnly,
 	}
 }
 
+// authTransport adds the bearer token from source to each request. When the
+// server answers 401, it asks source for a new token and retries once.
+type authTransport struct {
+	base   http.RoundTripper
+	source TokenSource
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	first := req.Clone(req.Context())
+	first.Header.Set("Authorization", "Bearer "+token)
+	resp, err := t.base.RoundTrip(first)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	fresh, err := t.source.Refresh()
+	if err != nil || fresh == token {
+		return resp, nil
+	}
+	retry, err := cloneRequestForRetry(req)
+	if err != nil {
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	retry.Header.Set("Authorization", "Bearer "+fresh)
+	return t.base.RoundTrip(retry)
+}
+
 // retryTransport wraps an http.RoundTripper to retry on 429 and 503.
 type retryTransport struct {
 	base http.RoundTripper
@@ -151,6 +151,86 @@ func TestRetryTransport_FailsForNonRewindableBody(t *testing.T) {
 	}
 }
 
+// refreshingSource is a TokenSource that hands out "old" until refreshed.
+type refreshingSource struct {
+	token     string
+	refreshes int
+}
+
+func (s *refreshingSource) Token() (string, error) { return s.token, nil }
+
+func (s *refreshingSource) Refresh() (string, error) {
+	s.refreshes++
+	s.token = "new"
+	return s.token, nil
+}
+
+func TestAuthTransport_RefreshesOn401AndRetries(t *testing.T) {
+	var auths, bodies []string
+	source := &refreshingSource{token: "old"}
+	rt := &authTransport{source: source, base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		auth := req.Header.Get("Authorization")
+		auths = append(auths, auth)
+		payload, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(payload))
+		if auth != "Bearer new" {
+			return &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(strings.NewReader("expired"))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	})}
+
+	req, err := http.NewRequest(http.MethodPost, "http://example.com", bytes.NewBufferString("payload"))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("roundtrip failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 after refresh, got %d", resp.StatusCode)
+	}
+	if source.refreshes != 1 {
+		t.Errorf("expected 1 refresh, got %d", source.refreshes)
+	}
+	if len(auths) != 2 || auths[0] != "Bearer old" || auths[1] != "Bearer new" {
+		t.Errorf("unexpected Authorization headers: %q", auths)
+	}
+	if len(bodies) != 2 || bodies[1] != "payload" {
+		t.Errorf("expected the retry to resend the body, got %q", bodies)
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Error("the caller's request was modified")
+	}
+}
+
+func TestAuthTransport_StaticTokenReturns401(t *testing.T) {
+	var calls int
+	rt := &authTransport{source: StaticToken("tok"), base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(strings.NewReader("no"))}, nil
+	})}
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("roundtrip failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected the 401 to be returned, got %d", resp.StatusCode)
+	}
+	if calls != 1 {
+		t.Errorf("expected no retry for a static token, got %d calls", calls)
+	}
+}
+
 // --- maxBatchSize tests ---
 
 func TestMaxBatchSize_NilClient(t *testing.T) {
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// CorrespondentsOptions holds parameters for correspondence analytics.
+// Emails received in MailboxID and emails in the Sent mailbox, received at
+// or after After, are joined by thread. A thread is unanswered when its
+// latest message is inbound and at least OlderThan before Now.
+type CorrespondentsOptions struct {
+	MailboxID string
+	After     time.Time
+	OlderThan time.Duration
+	Now       time.Time
+	OwedOnly  bool // keep only correspondents with unanswered threads
+	Limit     int  // correspondents to keep; 0 keeps all
+}
+
+// correspondentProperties are the Email/get properties for correspondence
+// analytics.
+var correspondentProperties = []string{"id", "threadId", "from", "to", "cc", "bcc", "subject", "receivedAt"}
+
+// threadMessage is one message in a thread, inbound or sent.
+type threadMessage struct {
+	id       string
+	at       time.Time
+	inbound  bool
+	from     string
+	subject  string
+	contacts []string // the sender, or the recipients of a sent message
+}
+
+// Correspondents reports, per correspondent, how many messages were
+// received from and sent to them, when they were last in contact, the
+// median time taken to reply to them, and their threads still awaiting a
+// reply.
+func (c *Client) Correspondents(opts CorrespondentsOptions) (types.CorrespondentsResult, error) {
+	sent, err := c.GetMailboxByRole(mailbox.RoleSent)
+	if err != nil {
+		return types.CorrespondentsResult{}, fmt.Errorf("sent mailbox not found: %w", err)
+	}
+
+	after := opts.After
+	threads := make(map[string][]threadMessage)
+	names := make(map[string]string)
+	own := make(map[string]bool)
+	if c.jmap != nil && c.jmap.Session != nil && strings.Contains(c.jmap.Session.Username, "@") {
+		own[strings.ToLower(c.jmap.Session.Username)] = true
+	}
+	seen := make(map[jmap.ID]bool)
+
+	// Sent first, so the account's own addresses are known before inbound
+	// mail is read.
+	var sentMessages []*email.Email
+	_, err = c.scanEmails("sent query", &email.FilterCondition{InMailbox: sent.ID, After: &after},
+		correspondentProperties, nil, func(e *email.Email) {
+			seen[e.ID] = true
+			for _, a := range e.From {
+				own[strings.ToLower(a.Email)] = true
+			}
+			sentMessages = append(sentMessages, e)
+		})
+	if err != nil {
+		return types.CorrespondentsResult{}, err
+	}
+	for _, e := range sentMessages {
+		if e.ReceivedAt == nil {
+			continue
+		}
+		m := threadMessage{id: string(e.ID), at: *e.ReceivedAt, subject: e.Subject}
+		for _, a := range slices.Concat(e.To, e.CC, e.BCC) {
+			addr := strings.ToLower(a.Email)
+			if addr == "" || own[addr] {
+				continue
+			}
+			m.contacts = append(m.contacts, addr)
+			if names[addr] == "" {
+				names[addr] = a.Name
+			}
+		}
+		threads[string(e.ThreadID)] = append(threads[string(e.ThreadID)], m)
+	}
+
+	// Newsletters and mailing lists are not correspondents; their headers
+	// give them away.
+	inboxProperties := append(slices.Clone(correspondentProperties), "headers")
+	_, err = c.scanEmails("inbox query", &email.FilterCondition{InMailbox: jmap.ID(opts.MailboxID), After: &after},
+		inboxProperties, nil, func(e *email.Email) {
+			if seen[e.ID] || e.ReceivedAt == nil || len(e.From) == 0 || hasListHeaders(e.Headers) {
+				return
+			}
+			addr := strings.ToLower(e.From[0].Email)
+			if addr == "" || own[addr] {
+				return
+			}
+			if names[addr] == "" {
+				names[addr] = e.From[0].Name
+			}
+			threads[string(e.ThreadID)] = append(threads[string(e.ThreadID)], threadMessage{
+				id: string(e.ID), at: *e.ReceivedAt, inbound: true, from: addr,
+				subject: e.Subject, contacts: []string{addr},
+			})
+		})
+	if err != nil {
+		return types.CorrespondentsResult{}, err
+	}
+
+	type acc struct {
+		stat      types.CorrespondentStat
+		latencies []time.Duration
+	}
+	people := make(map[string]*acc)
+	person := func(addr string) *acc {
+		a, ok := people[addr]
+		if !ok {
+			a = &acc{stat: types.CorrespondentStat{Email: addr, Name: names[addr], Unanswered: []types.UnansweredThread{}}}
+			people[addr] = a
+		}
+		return a
+	}
+
+	for threadID, messages := range threads {
+		sort.Slice(messages, func(i, j int) bool { return messages[i].at.Before(messages[j].at) })
+
+		// waiting holds the earliest unreplied inbound time per sender.
+		waiting := make(map[string]time.Time)
+		for _, m := range messages {
+			for _, addr := range m.contacts {
+				p := person(addr)
+				if m.inbound {
+					p.stat.Received++
+				} else {
+					p.stat.Sent++
+				}
+				if m.at.After(p.stat.LastContact) {
+					p.stat.LastContact = m.at
+				}
+			}
+			if m.inbound {
+				if _, ok := waiting[m.from]; !ok {
+					waiting[m.from] = m.at
+				}
+				continue
+			}
+			for addr, since := range waiting {
+				p := person(addr)
+				p.latencies = append(p.latencies, m.at.Sub(since))
+			}
+			waiting = make(map[string]time.Time)
+		}
+
+		last := messages[len(messages)-1]
+		if last.inbound && opts.Now.Sub(last.at) >= opts.OlderThan {
+			p := person(last.from)
+			p.stat.Unanswered = append(p.stat.Unanswered, types.UnansweredThread{
+				ThreadID:    threadID,
+				EmailID:     last.id,
+				Subject:     last.subject,
+				ReceivedAt:  last.at,
+				WaitingDays: int(opts.Now.Sub(last.at).Hours() / 24),
+			})
+		}
+	}
+
+	result := types.CorrespondentsResult{After: opts.After, Correspondents: []types.CorrespondentStat{}}
+	for _, p := range people {
+		if opts.OwedOnly && len(p.stat.Unanswered) == 0 {
+			continue
+		}
+		if len(p.latencies) > 0 {
+			median := medianDuration(p.latencies)
+			seconds := int64(median / time.Second)
+			p.stat.Replies = len(p.latencies)
+			p.stat.MedianReplySeconds = &seconds
+		}
+		sort.Slice(p.stat.Unanswered, func(i, j int) bool {
+			return p.stat.Unanswered[i].ReceivedAt.Before(p.stat.Unanswered[j].ReceivedAt)
+		})
+		result.Correspondents = append(result.Correspondents, p.stat)
+	}
+	sort.Slice(result.Correspondents, func(i, j int) bool {
+		a, b := result.Correspondents[i], result.Correspondents[j]
+		if len(a.Unanswered) != len(b.Unanswered) {
+			return len(a.Unanswered) > len(b.Unanswered)
+		}
+		if a.Received+a.Sent != b.Received+b.Sent {
+			return a.Received+a.Sent > b.Received+b.Sent
+		}
+		return a.Email < b.Email
+	})
+	result.Total = len(result.Correspondents)
+	if opts.Limit > 0 && len(result.Correspondents) > opts.Limit {
+		result.Correspondents = result.Correspondents[:opts.Limit]
+	}
+	return result, nil
+}
+
+// medianDuration returns the median of ds, which it sorts.
+func medianDuration(ds []time.Duration) time.Duration {
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	mid := len(ds) / 2
+	if len(ds)%2 == 1 {
+		return ds[mid]
+	}
+	return (ds[mid-1] + ds[mid]) / 2
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+)
+
+// mailboxScanClient returns a client whose Email/query results depend on
+// the inMailbox filter.
+func mailboxScanClient(t *testing.T, byMailbox map[jmap.ID][]*email.Email) *Client {
+	t.Helper()
+	return &Client{
+		accountID: "acct-1",
+		jmap:      &jmap.Client{Session: &jmap.Session{Username: "me@example.com"}},
+		mailboxCache: []*mailbox.Mailbox{
+			{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox},
+			{ID: "mb-sent", Name: "Sent", Role: mailbox.RoleSent},
+		},
+		doFunc: scanStub(func(q *email.Query, _ *email.Get) []*email.Email {
+			return byMailbox[q.Filter.(*email.FilterCondition).InMailbox]
+		}),
+	}
+}
+
+func TestCorrespondents_RepliesAndUnanswered(t *testing.T) {
+	me := []*mail.Address{{Email: "me@example.com"}}
+	alice := []*mail.Address{{Email: "Alice@example.com", Name: "Alice"}}
+	bob := []*mail.Address{{Email: "bob@example.org"}}
+	carol := []*mail.Address{{Email: "carol@example.net"}}
+	list := []*email.Header{{Name: "List-Id", Value: "<news.example.com>"}}
+
+	c := mailboxScanClient(t, map[jmap.ID][]*email.Email{
+		"mb-inbox": {
+			{ID: "I1", ThreadID: "T1", From: alice, Subject: "Lunch?", ReceivedAt: received("2026-03-01T10:00:00Z")},
+			{ID: "I2", ThreadID: "T1", From: alice, Subject: "Re: Lunch?", ReceivedAt: received("2026-03-02T10:00:00Z")},
+			{ID: "I3", ThreadID: "T2", From: bob, Subject: "Invoice", ReceivedAt: received("2026-03-03T08:00:00Z")},
+			{ID: "I4", ThreadID: "T3", From: bob, Subject: "Weekly news", Headers: list, ReceivedAt: received("2026-03-03T09:00:00Z")},
+			{ID: "I5", ThreadID: "T4", From: carol, Subject: "Hello", ReceivedAt: received("2026-03-09T09:00:00Z")},
+			{ID: "I6", ThreadID: "T5", From: me, To: alice, Subject: "Note to self", ReceivedAt: received("2026-03-04T09:00:00Z")},
+		},
+		"mb-sent": {
+			{ID: "S1", ThreadID: "T1", From: me, To: alice, Subject: "Re: Lunch?", ReceivedAt: received("2026-03-01T12:00:00Z")},
+			{ID: "S2", ThreadID: "T2", From: me, To: bob, CC: me, Subject: "Re: Invoice", ReceivedAt: received("2026-03-03T14:00:00Z")},
+		},
+	})
+
+	result, err := c.Correspondents(CorrespondentsOptions{
+		MailboxID: "mb-inbox",
+		After:     time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		OlderThan: 3 * 24 * time.Hour,
+		Now:       time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 3 || len(result.Correspondents) != 3 {
+		t.Fatalf("expected 3 correspondents, got %+v", result.Correspondents)
+	}
+
+	a, b, cc := result.Correspondents[0], result.Correspondents[1], result.Correspondents[2]
+	if a.Email != "alice@example.com" || a.Name != "Alice" || a.Received != 2 || a.Sent != 1 {
+		t.Errorf("unexpected first correspondent: %+v", a)
+	}
+	if a.Replies != 1 || a.MedianReplySeconds == nil || *a.MedianReplySeconds != 7200 {
+		t.Errorf("expected a 2h reply to alice, got %+v", a)
+	}
+	if len(a.Unanswered) != 1 || a.Unanswered[0].EmailID != "I2" || a.Unanswered[0].WaitingDays != 8 {
+		t.Errorf("unexpected unanswered threads: %+v", a.Unanswered)
+	}
+	if b.Email != "bob@example.org" || b.Received != 1 || *b.MedianReplySeconds != 6*3600 || len(b.Unanswered) != 0 {
+		t.Errorf("expected the newsletter to be ignored and the invoice answered: %+v", b)
+	}
+	if cc.Email != "carol@example.net" || len(cc.Unanswered) != 0 || cc.MedianReplySeconds != nil {
+		t.Errorf("expected carol's recent message not to be overdue: %+v", cc)
+	}
+
+	owed, err := c.Correspondents(CorrespondentsOptions{
+		MailboxID: "mb-inbox",
+		OlderThan: 24 * time.Hour,
+		Now:       time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC),
+		OwedOnly:  true,
+		Limit:     1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owed.Total != 2 || len(owed.Correspondents) != 1 || owed.Correspondents[0].Email != "alice@example.com" {
+		t.Errorf("unexpected owed result: %+v", owed)
+	}
+}
+
+func TestMedianDuration(t *testing.T) {
+	if got := medianDuration([]time.Duration{3, 1, 2}); got != 2 {
+		t.Errorf("odd median = %v", got)
+	}
+	if got := medianDuration([]time.Duration{4, 1, 2, 3}); got != 2 {
+		t.Errorf("even median = %v", got)
+	}
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"html"
 	"strings"
 
 	"git.sr.ht/~rockorager/go-jmap"
@@ -20,8 +21,13 @@ const (
 	DraftModeReply    DraftMode = "reply"
 	DraftModeReplyAll DraftMode = "reply-all"
 	DraftModeForward  DraftMode = "forward"
+	DraftModeEdit     DraftMode = "edit"
 )
 
+// draftArchiveMailboxName is the folder that receives superseded drafts when
+// a draft is edited. Drafts are moved there, never destroyed.
+const draftArchiveMailboxName = "Draft Revisions"
+
 // DraftOptions holds parameters for creating a draft email.
 type DraftOptions struct {
 	Mode       DraftMode
@@ -31,7 +37,8 @@ type DraftOptions struct {
 	Subject    string
 	Body       string
 	HTML       bool
-	OriginalID string // email ID for reply/reply-all/forward
+	Markdown   bool   // render Body as Markdown into a multipart/alternative draft
+	OriginalID string // email ID for reply/reply-all/forward, or the draft being edited
 }
 
 // replyProperties are the Email/get properties needed for composing a reply.
@@ -41,7 +48,18 @@ var replyProperties = []string{
 	"bodyValues", "textBody", "htmlBody",
 }
 
+// editProperties are the Email/get properties needed for revising a draft.
+var editProperties = []string{
+	"id", "mailboxIds", "keywords", "from", "to", "cc", "bcc", "subject",
+	"inReplyTo", "references", "bodyValues", "textBody", "htmlBody", "attachments",
+}
+
 // CreateDraft creates a draft email in the Drafts mailbox.
+//
+// In DraftModeEdit, the draft identified by OriginalID is replaced: fields not
+// given in opts are carried over from it, the revised draft is created, and
+// the previous draft is moved into the "Draft Revisions" mailbox. Attachments
+// are always carried over by blob ID.
 func (c *Client) CreateDraft(opts DraftOptions) (types.DraftResult, error) {
 	draftsMB, err := c.GetMailboxByRole(mailbox.RoleDrafts)
 	if err != nil {
@@ -65,8 +83,10 @@ func (c *Client) CreateDraft(opts DraftOptions) (types.DraftResult, error) {
 		bccAddrs []*mail.Address
 		subject  string
 		body     string
+		quoted   string
 		replyTo  []string
 		refs     []string
+		previous *email.Email
 	)
 
 	switch opts.Mode {
@@ -144,8 +164,44 @@ func (c *Client) CreateDraft(opts DraftOptions) (types.DraftResult, error) {
 		}
 
 		// Prepend quoted original to body.
-		origBody := extractBody(orig, false)
-		body = opts.Body + "\n\n---------- Forwarded message ----------\n" + origBody
+		quoted = extractBody(orig, false)
+		body = opts.Body + forwardSeparator + quoted
+
+	case DraftModeEdit:
+		if opts.Body == "" && (opts.HTML || opts.Markdown) {
+			return types.DraftResult{}, fmt.Errorf("--html and --markdown apply to a new body; provide one to change the draft's format")
+		}
+		orig, fetchErr := c.fetchOriginal(opts.OriginalID, editProperties)
+		if fetchErr != nil {
+			return types.DraftResult{}, fetchErr
+		}
+		if err := ValidateIsDraft(orig); err != nil {
+			return types.DraftResult{}, err
+		}
+		previous = orig
+
+		if len(orig.From) > 0 {
+			fromAddrs = orig.From
+		}
+		toAddrs = orig.To
+		if len(opts.To) > 0 {
+			toAddrs = toJMAPAddresses(opts.To)
+		}
+		ccAddrs = orig.CC
+		if len(opts.CC) > 0 {
+			ccAddrs = toJMAPAddresses(opts.CC)
+		}
+		bccAddrs = orig.BCC
+		if len(opts.BCC) > 0 {
+			bccAddrs = toJMAPAddresses(opts.BCC)
+		}
+		subject = orig.Subject
+		if opts.Subject != "" {
+			subject = opts.Subject
+		}
+		body = opts.Body
+		replyTo = orig.InReplyTo
+		refs = orig.References
 
 	default:
 		return types.DraftResult{}, fmt.Errorf("unknown draft mode: %s", opts.Mode)
@@ -174,13 +230,41 @@ func (c *Client) CreateDraft(opts DraftOptions) (types.DraftResult, error) {
 
 	// Set body content.
 	bodyPartID := "body"
-	if opts.HTML {
+	switch {
+	case previous != nil && opts.Body == "":
+		draft.TextBody, draft.HTMLBody, draft.BodyValues = copyDraftBody(previous)
+		if len(draft.BodyValues) == 0 {
+			return types.DraftResult{}, fmt.Errorf("draft %s has no body to carry over; provide a new body", opts.OriginalID)
+		}
+		draft.Attachments = draftAttachments(previous, true)
+	case opts.Markdown:
+		// Supplying both textBody and htmlBody makes the server build a
+		// multipart/alternative message. The Markdown source doubles as the
+		// plain-text rendition.
+		htmlBody, err := markdownDraftHTML(opts.Body, quoted, opts.Mode == DraftModeForward)
+		if err != nil {
+			return types.DraftResult{}, err
+		}
+		htmlPartID := "html"
+		draft.TextBody = []*email.BodyPart{{PartID: bodyPartID, Type: "text/plain"}}
+		draft.HTMLBody = []*email.BodyPart{{PartID: htmlPartID, Type: "text/html"}}
+		draft.BodyValues = map[string]*email.BodyValue{
+			bodyPartID: {Value: body},
+			htmlPartID: {Value: htmlBody},
+		}
+	case opts.HTML:
 		draft.HTMLBody = []*email.BodyPart{{PartID: bodyPartID, Type: "text/html"}}
-	} else {
+		draft.BodyValues = map[string]*email.BodyValue{
+			bodyPartID: {Value: body},
+		}
+	default:
 		draft.TextBody = []*email.BodyPart{{PartID: bodyPartID, Type: "text/plain"}}
+		draft.BodyValues = map[string]*email.BodyValue{
+			bodyPartID: {Value: body},
+		}
 	}
-	draft.BodyValues = map[string]*email.BodyValue{
-		bodyPartID: {Value: body},
+	if previous != nil && opts.Body != "" {
+		draft.Attachments = draftAttachments(previous, false)
 	}
 
 	// Validate and execute.
@@ -194,6 +278,12 @@ func (c *Client) CreateDraft(opts DraftOptions) (types.DraftResult, error) {
 		return types.DraftResult{}, err
 	}
 
+	if c.budget != nil {
+		if _, err := c.budget.Allow(1); err != nil {
+			return types.DraftResult{}, &ErrForbidden{Operation: "Email/set", Reason: err.Error()}
+		}
+	}
+
 	req := &jmap.Request{}
 	req.Invoke(set)
 
@@ -202,14 +292,16 @@ func (c *Client) CreateDraft(opts DraftOptions) (types.DraftResult, error) {
 		return types.DraftResult{}, fmt.Errorf("draft creation: %w", err)
 	}
 
+	var result types.DraftResult
+	created := false
 	for _, inv := range resp.Responses {
 		switch r := inv.Args.(type) {
 		case *email.SetResponse:
-			if created, ok := r.Created[createID]; ok {
-				result := types.DraftResult{
-					ID:      string(created.ID),
+			if createdEmail, ok := r.Created[createID]; ok {
+				result = types.DraftResult{
+					ID:      string(createdEmail.ID),
 					Mode:    string(opts.Mode),
-					Mailbox: &types.DestinationInfo{ID: string(draftsID), Name: draftsMB.Name},
+					Mailbox: c.Destination(draftsMB),
 					From:    convertAddresses(fromAddrs),
 					To:      convertAddresses(toAddrs),
 					CC:      convertAddresses(ccAddrs),
@@ -218,9 +310,8 @@ func (c *Client) CreateDraft(opts DraftOptions) (types.DraftResult, error) {
 				if len(replyTo) > 0 {
 					result.InReplyTo = strings.Join(replyTo, ", ")
 				}
-				return result, nil
-			}
-			if setErr, ok := r.NotCreated[createID]; ok {
+				created = true
+			} else if setErr, ok := r.NotCreated[createID]; ok {
 				desc := "unknown error"
 				if setErr.Description != nil {
 					desc = *setErr.Description
@@ -232,16 +323,140 @@ func (c *Client) CreateDraft(opts DraftOptions) (types.DraftResult, error) {
 		}
 	}
 
-	return types.DraftResult{}, fmt.Errorf("draft creation: unexpected response")
+	if !created {
+		return types.DraftResult{}, fmt.Errorf("draft creation: unexpected response")
+	}
+	c.spendBudget(1)
+
+	if previous != nil {
+		c.archivePreviousDraft(previous, &result)
+	}
+
+	return result, nil
+}
+
+// archivePreviousDraft moves a superseded draft out of Drafts into the
+// "Draft Revisions" mailbox and records the outcome on result. Failures are
+// reported in result.ArchiveError because the revised draft already exists.
+func (c *Client) archivePreviousDraft(previous *email.Email, result *types.DraftResult) {
+	result.Replaced = string(previous.ID)
+
+	archiveMB, err := c.EnsureMailbox(draftArchiveMailboxName)
+	if err != nil {
+		result.ArchiveError = err.Error()
+		return
+	}
+
+	_, errs := c.MoveEmails([]string{string(previous.ID)}, archiveMB.ID)
+	if len(errs) > 0 {
+		result.ArchiveError = strings.Join(errs, "; ")
+		return
+	}
+	result.ArchivedTo = c.Destination(archiveMB)
+}
+
+// copyDraftBody rebuilds the text and HTML body parts of an existing draft so
+// they can be reused verbatim in a revised draft. When the text and HTML
+// bodies reference the same part, it is copied once under its own type.
+func copyDraftBody(prev *email.Email) (textBody, htmlBody []*email.BodyPart, values map[string]*email.BodyValue) {
+	values = make(map[string]*email.BodyValue)
+	var candidates []*email.BodyPart
+	if len(prev.TextBody) > 0 {
+		candidates = append(candidates, prev.TextBody[0])
+	}
+	if len(prev.HTMLBody) > 0 {
+		candidates = append(candidates, prev.HTMLBody[0])
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range candidates {
+		if seen[part.PartID] {
+			continue
+		}
+		seen[part.PartID] = true
+		bv, ok := prev.BodyValues[part.PartID]
+		if !ok {
+			continue
+		}
+		if part.Type == "text/html" {
+			htmlBody = []*email.BodyPart{{PartID: "html", Type: "text/html"}}
+			values["html"] = &email.BodyValue{Value: bv.Value}
+		} else {
+			textBody = []*email.BodyPart{{PartID: "body", Type: "text/plain"}}
+			values["body"] = &email.BodyValue{Value: bv.Value}
+		}
+	}
+	return textBody, htmlBody, values
+}
+
+// draftAttachments returns blob references for the parts of an existing
+// draft that a revised draft must keep: its attachments and, when withBody is
+// set, any body parts beyond the first text and HTML parts (such as inline
+// images), which copyDraftBody does not rebuild.
+func draftAttachments(prev *email.Email, withBody bool) []*email.BodyPart {
+	seen := make(map[string]bool)
+	if len(prev.TextBody) > 0 {
+		seen[prev.TextBody[0].PartID] = true
+	}
+	if len(prev.HTMLBody) > 0 {
+		seen[prev.HTMLBody[0].PartID] = true
+	}
+
+	var parts []*email.BodyPart
+	if withBody {
+		parts = append(parts, prev.TextBody...)
+		parts = append(parts, prev.HTMLBody...)
+	}
+	parts = append(parts, prev.Attachments...)
+
+	var attachments []*email.BodyPart
+	for _, part := range parts {
+		if part.BlobID == "" || seen[part.PartID] {
+			continue
+		}
+		seen[part.PartID] = true
+		attachments = append(attachments, &email.BodyPart{
+			BlobID:      part.BlobID,
+			Type:        part.Type,
+			Charset:     part.Charset,
+			Name:        part.Name,
+			Disposition: part.Disposition,
+			CID:         part.CID,
+		})
+	}
+	return attachments
+}
+
+// forwardSeparator introduces the quoted original in forwarded drafts.
+const forwardSeparator = "\n\n---------- Forwarded message ----------\n"
+
+// markdownDraftHTML renders the user's Markdown body as HTML. For forwards,
+// the original message is appended verbatim (HTML-escaped, preformatted)
+// rather than interpreted as Markdown.
+func markdownDraftHTML(userBody, quoted string, forward bool) (string, error) {
+	rendered, err := renderMarkdown(userBody)
+	if err != nil {
+		return "", err
+	}
+	if !forward {
+		return rendered, nil
+	}
+	return rendered + "<p>---------- Forwarded message ----------</p>\n<pre>" +
+		html.EscapeString(quoted) + "</pre>\n", nil
 }
 
 // fetchOriginalForReply fetches the original email needed for reply/reply-all/forward.
 func (c *Client) fetchOriginalForReply(emailID string) (*email.Email, error) {
+	return c.fetchOriginal(emailID, replyProperties)
+}
+
+// fetchOriginal fetches a single email with body values for draft composition.
+func (c *Client) fetchOriginal(emailID string, properties []string) (*email.Email, error) {
 	req := &jmap.Request{}
 	get := &email.Get{
 		Account:    c.accountID,
 		IDs:        []jmap.ID{jmap.ID(emailID)},
-		Properties: replyProperties,
+		Properties: properties,
 	}
 	get.FetchTextBodyValues = true
 	get.FetchHTMLBodyValues = true
@@ -335,3 +550,58 @@ func dedup(items []string) []string {
 	}
 	return result
 }
+
+// ListDrafts returns emails in the Drafts mailbox that carry the $draft
+// keyword, newest first.
+func (c *Client) ListDrafts(limit uint64, offset int64) (types.EmailListResult, error) {
+	if limit == 0 {
+		limit = 25
+	}
+
+	draftsMB, err := c.GetMailboxByRole(mailbox.RoleDrafts)
+	if err != nil {
+		return types.EmailListResult{}, fmt.Errorf("drafts mailbox not found: %w", err)
+	}
+
+	req := &jmap.Request{}
+	queryCallID := req.Invoke(&email.Query{
+		Account: c.accountID,
+		Filter: &email.FilterCondition{
+			InMailbox:  draftsMB.ID,
+			HasKeyword: "$draft",
+		},
+		Sort:           []*email.SortComparator{{Property: "receivedAt", IsAscending: false}},
+		Position:       offset,
+		Limit:          limit,
+		CalculateTotal: true,
+	})
+
+	req.Invoke(&email.Get{
+		Account:    c.accountID,
+		Properties: summaryProperties,
+		ReferenceIDs: &jmap.ResultReference{
+			ResultOf: queryCallID,
+			Name:     "Email/query",
+			Path:     "/ids",
+		},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return types.EmailListResult{}, fmt.Errorf("drafts query: %w", err)
+	}
+
+	result := types.EmailListResult{Offset: offset}
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.QueryResponse:
+			result.Total = r.Total
+		case *email.GetResponse:
+			result.Emails = convertSummaries(r.List)
+		case *jmap.MethodError:
+			return types.EmailListResult{}, fmt.Errorf("drafts query: %s", r.Error())
+		}
+	}
+
+	return result, nil
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -143,6 +144,78 @@ func TestCreateDraft_NewPlaintext(t *testing.T) {
 	}
 }
 
+func TestCreateDraft_NewMarkdown(t *testing.T) {
+	var capturedSet *email.Set
+	c := testClientForDraft(func(req *jmap.Request) (*jmap.Response, error) {
+		capturedSet = req.Calls[0].Args.(*email.Set)
+		return mockDraftCreateSuccess("M-md")(req)
+	})
+
+	_, err := c.CreateDraft(DraftOptions{
+		Mode:     DraftModeNew,
+		To:       []types.Address{{Email: "alice@example.com"}},
+		Subject:  "Markdown",
+		Body:     "Hello **Alice**\n\n- one\n- two\n",
+		Markdown: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, draft := range capturedSet.Create {
+		if len(draft.TextBody) != 1 || len(draft.HTMLBody) != 1 {
+			t.Fatalf("expected one text and one HTML part, got text=%d html=%d", len(draft.TextBody), len(draft.HTMLBody))
+		}
+		textPart := draft.TextBody[0]
+		htmlPart := draft.HTMLBody[0]
+		if textPart.PartID == htmlPart.PartID {
+			t.Errorf("expected distinct part IDs, both are %q", textPart.PartID)
+		}
+		if textPart.Type != "text/plain" || htmlPart.Type != "text/html" {
+			t.Errorf("unexpected part types: text=%q html=%q", textPart.Type, htmlPart.Type)
+		}
+		if got := draft.BodyValues[textPart.PartID].Value; got != "Hello **Alice**\n\n- one\n- two\n" {
+			t.Errorf("expected plain-text part to carry the Markdown source, got %q", got)
+		}
+		htmlValue := draft.BodyValues[htmlPart.PartID].Value
+		if !strings.Contains(htmlValue, "<strong>Alice</strong>") {
+			t.Errorf("expected rendered emphasis in HTML, got %q", htmlValue)
+		}
+		if !strings.Contains(htmlValue, "<li>one</li>") {
+			t.Errorf("expected rendered list in HTML, got %q", htmlValue)
+		}
+	}
+}
+
+func TestCreateDraft_MarkdownStripsRawHTML(t *testing.T) {
+	var capturedSet *email.Set
+	c := testClientForDraft(func(req *jmap.Request) (*jmap.Response, error) {
+		capturedSet = req.Calls[0].Args.(*email.Set)
+		return mockDraftCreateSuccess("M-md-raw")(req)
+	})
+
+	_, err := c.CreateDraft(DraftOptions{
+		Mode:     DraftModeNew,
+		To:       []types.Address{{Email: "alice@example.com"}},
+		Subject:  "Sanitized",
+		Body:     "Hi <script>alert(1)</script>\n\n[click](javascript:alert(1))\n",
+		Markdown: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, draft := range capturedSet.Create {
+		htmlValue := draft.BodyValues[draft.HTMLBody[0].PartID].Value
+		if strings.Contains(htmlValue, "<script>") {
+			t.Errorf("expected raw HTML to be omitted, got %q", htmlValue)
+		}
+		if strings.Contains(htmlValue, "javascript:") {
+			t.Errorf("expected dangerous link to be dropped, got %q", htmlValue)
+		}
+	}
+}
+
 func TestCreateDraft_NewWithCC(t *testing.T) {
 	c := testClientForDraft(mockDraftCreateSuccess("M-cc"))
 
@@ -524,6 +597,56 @@ func TestCreateDraft_ForwardBodyIncludesOriginal(t *testing.T) {
 	}
 }
 
+func TestCreateDraft_ForwardMarkdownQuotesOriginalVerbatim(t *testing.T) {
+	var capturedSet *email.Set
+	callCount := 0
+	c := testClientForDraft(func(req *jmap.Request) (*jmap.Response, error) {
+		callCount++
+		if callCount == 1 {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/get", CallID: "0", Args: &email.GetResponse{
+					List: []*email.Email{{
+						ID:       "M-orig",
+						From:     []*mail.Address{{Email: "sender@example.com"}},
+						Subject:  "Info",
+						TextBody: []*email.BodyPart{{PartID: "1"}},
+						BodyValues: map[string]*email.BodyValue{
+							"1": {Value: "Total: *not emphasis* <b>"},
+						},
+					}},
+				}},
+			}}, nil
+		}
+		capturedSet = req.Calls[0].Args.(*email.Set)
+		return mockDraftCreateSuccess("M-fwd-md")(req)
+	})
+
+	_, err := c.CreateDraft(DraftOptions{
+		Mode:       DraftModeForward,
+		OriginalID: "M-orig",
+		To:         []types.Address{{Email: "alice@example.com"}},
+		Body:       "**FYI**",
+		Markdown:   true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, draft := range capturedSet.Create {
+		htmlValue := draft.BodyValues[draft.HTMLBody[0].PartID].Value
+		if !strings.Contains(htmlValue, "<strong>FYI</strong>") {
+			t.Errorf("expected user body rendered as Markdown, got %q", htmlValue)
+		}
+		if !strings.Contains(htmlValue, "<pre>Total: *not emphasis* &lt;b&gt;</pre>") {
+			t.Errorf("expected original quoted verbatim and escaped, got %q", htmlValue)
+		}
+		textValue := draft.BodyValues[draft.TextBody[0].PartID].Value
+		if !strings.Contains(textValue, "Forwarded message") || !strings.Contains(textValue, "Total: *not emphasis* <b>") {
+			t.Errorf("expected plain-text part to include the forwarded original, got %q", textValue)
+		}
+	}
+}
+
 func TestCreateDraft_ForwardSubjectAlreadyHasFwd(t *testing.T) {
 	callCount := 0
 	c := testClientForDraft(func(req *jmap.Request) (*jmap.Response, error) {
@@ -954,3 +1077,337 @@ func TestUnknownDraftMode(t *testing.T) {
 		t.Fatal("expected error for unknown draft mode")
 	}
 }
+
+// --- Edit tests ---
+
+// mockDraftEdit returns a doFunc that serves the existing draft, accepts the
+// revised draft creation, creates the archive mailbox, and accepts the move.
+// Captured requests are recorded in the returned slice pointers.
+func mockDraftEdit(existing *email.Email, creates *[]*email.Set, updates *[]*email.Set, mailboxSets *[]*mailbox.Set) func(*jmap.Request) (*jmap.Response, error) {
+	return func(req *jmap.Request) (*jmap.Response, error) {
+		switch args := req.Calls[0].Args.(type) {
+		case *email.Get:
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/get", CallID: "0", Args: &email.GetResponse{List: []*email.Email{existing}}},
+			}}, nil
+		case *email.Set:
+			if len(args.Create) > 0 {
+				*creates = append(*creates, args)
+				return mockDraftCreateSuccess("M-revised")(req)
+			}
+			*updates = append(*updates, args)
+			updated := make(map[jmap.ID]*email.Email)
+			for id := range args.Update {
+				updated[id] = nil
+			}
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/set", CallID: "0", Args: &email.SetResponse{Updated: updated}},
+			}}, nil
+		case *mailbox.Set:
+			*mailboxSets = append(*mailboxSets, args)
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Mailbox/set", CallID: "0", Args: &mailbox.SetResponse{
+					Created: map[jmap.ID]*mailbox.Mailbox{"mailbox-0": {ID: "mb-revisions"}},
+				}},
+			}}, nil
+		}
+		return nil, fmt.Errorf("unexpected request")
+	}
+}
+
+func existingDraft() *email.Email {
+	return &email.Email{
+		ID:         "M-old",
+		MailboxIDs: map[jmap.ID]bool{testDraftsID: true},
+		Keywords:   map[string]bool{"$draft": true, "$seen": true},
+		From:       []*mail.Address{{Email: "user@fastmail.com"}},
+		To:         []*mail.Address{{Name: "Alice", Email: "alice@example.com"}},
+		CC:         []*mail.Address{{Email: "carol@example.com"}},
+		Subject:    "Re: Plans",
+		InReplyTo:  []string{"<orig@example.com>"},
+		References: []string{"<root@example.com>", "<orig@example.com>"},
+		TextBody:   []*email.BodyPart{{PartID: "1", Type: "text/plain"}},
+		BodyValues: map[string]*email.BodyValue{"1": {Value: "Old body"}},
+	}
+}
+
+func TestCreateDraft_EditReplacesAndArchives(t *testing.T) {
+	var creates, updates []*email.Set
+	var mailboxSets []*mailbox.Set
+	c := testClientForDraft(mockDraftEdit(existingDraft(), &creates, &updates, &mailboxSets))
+
+	result, err := c.CreateDraft(DraftOptions{
+		Mode:       DraftModeEdit,
+		OriginalID: "M-old",
+		Body:       "New body",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.ID != "M-revised" || result.Mode != "edit" {
+		t.Errorf("unexpected result: id=%s mode=%s", result.ID, result.Mode)
+	}
+	if result.Replaced != "M-old" {
+		t.Errorf("expected Replaced M-old, got %q", result.Replaced)
+	}
+	if result.ArchivedTo == nil || result.ArchivedTo.ID != "mb-revisions" || result.ArchivedTo.Name != "Draft Revisions" {
+		t.Errorf("unexpected ArchivedTo: %+v", result.ArchivedTo)
+	}
+	if result.ArchiveError != "" {
+		t.Errorf("unexpected ArchiveError: %s", result.ArchiveError)
+	}
+
+	if len(creates) != 1 {
+		t.Fatalf("expected 1 create, got %d", len(creates))
+	}
+	for _, draft := range creates[0].Create {
+		if draft.Subject != "Re: Plans" {
+			t.Errorf("expected subject carried over, got %q", draft.Subject)
+		}
+		if len(draft.To) != 1 || draft.To[0].Email != "alice@example.com" {
+			t.Errorf("expected To carried over, got %v", draft.To)
+		}
+		if len(draft.CC) != 1 || draft.CC[0].Email != "carol@example.com" {
+			t.Errorf("expected CC carried over, got %v", draft.CC)
+		}
+		if len(draft.InReplyTo) != 1 || draft.InReplyTo[0] != "<orig@example.com>" {
+			t.Errorf("expected threading carried over, got %v", draft.InReplyTo)
+		}
+		if len(draft.References) != 2 {
+			t.Errorf("expected references carried over, got %v", draft.References)
+		}
+		if draft.BodyValues["body"].Value != "New body" {
+			t.Errorf("expected new body, got %q", draft.BodyValues["body"].Value)
+		}
+	}
+
+	if len(mailboxSets) != 1 || len(mailboxSets[0].Destroy) != 0 {
+		t.Fatalf("expected one Mailbox/set create, got %+v", mailboxSets)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("expected 1 Email/set update, got %d", len(updates))
+	}
+	if len(updates[0].Destroy) != 0 {
+		t.Error("expected no destroy when archiving the previous draft")
+	}
+	patch, ok := updates[0].Update["M-old"]
+	if !ok {
+		t.Fatalf("expected update for M-old, got %v", updates[0].Update)
+	}
+	mbIDs, ok := patch["mailboxIds"].(map[jmap.ID]bool)
+	if !ok || len(mbIDs) != 1 || !mbIDs["mb-revisions"] {
+		t.Errorf("expected previous draft moved to mb-revisions only, got %v", patch["mailboxIds"])
+	}
+}
+
+func TestCreateDraft_EditUsesExistingArchiveMailbox(t *testing.T) {
+	var creates, updates []*email.Set
+	var mailboxSets []*mailbox.Set
+	c := testClientForDraft(mockDraftEdit(existingDraft(), &creates, &updates, &mailboxSets))
+	c.mailboxCache = append(c.mailboxCache, &mailbox.Mailbox{ID: "mb-existing", Name: "draft revisions"})
+
+	result, err := c.CreateDraft(DraftOptions{
+		Mode:       DraftModeEdit,
+		OriginalID: "M-old",
+		Subject:    "Re: Plans (v2)",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mailboxSets) != 0 {
+		t.Errorf("expected no Mailbox/set when the archive mailbox exists, got %d", len(mailboxSets))
+	}
+	if result.ArchivedTo == nil || result.ArchivedTo.ID != "mb-existing" {
+		t.Errorf("unexpected ArchivedTo: %+v", result.ArchivedTo)
+	}
+	if result.Subject != "Re: Plans (v2)" {
+		t.Errorf("expected overridden subject, got %q", result.Subject)
+	}
+	for _, draft := range creates[0].Create {
+		if draft.BodyValues["body"].Value != "Old body" {
+			t.Errorf("expected body carried over, got %q", draft.BodyValues["body"].Value)
+		}
+	}
+}
+
+func TestCreateDraft_EditCarriesOverHTMLAlternative(t *testing.T) {
+	existing := existingDraft()
+	existing.TextBody = []*email.BodyPart{{PartID: "1", Type: "text/plain"}}
+	existing.HTMLBody = []*email.BodyPart{{PartID: "2", Type: "text/html"}}
+	existing.BodyValues = map[string]*email.BodyValue{
+		"1": {Value: "plain"},
+		"2": {Value: "<p>html</p>"},
+	}
+
+	var creates, updates []*email.Set
+	var mailboxSets []*mailbox.Set
+	c := testClientForDraft(mockDraftEdit(existing, &creates, &updates, &mailboxSets))
+
+	if _, err := c.CreateDraft(DraftOptions{Mode: DraftModeEdit, OriginalID: "M-old", Subject: "x"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, draft := range creates[0].Create {
+		if len(draft.TextBody) != 1 || len(draft.HTMLBody) != 1 {
+			t.Fatalf("expected both parts carried over, got text=%d html=%d", len(draft.TextBody), len(draft.HTMLBody))
+		}
+		if draft.BodyValues[draft.HTMLBody[0].PartID].Value != "<p>html</p>" {
+			t.Errorf("unexpected HTML value: %q", draft.BodyValues[draft.HTMLBody[0].PartID].Value)
+		}
+	}
+}
+
+func TestCreateDraft_EditKeepsAttachmentsAndInlineParts(t *testing.T) {
+	existing := existingDraft()
+	existing.HTMLBody = []*email.BodyPart{
+		{PartID: "2", Type: "text/html"},
+		{PartID: "3", BlobID: "B-logo", Type: "image/png", Disposition: "inline", CID: "logo@x"},
+	}
+	existing.BodyValues["2"] = &email.BodyValue{Value: `<p>hi <img src="cid:logo@x"></p>`}
+	existing.Attachments = []*email.BodyPart{
+		{PartID: "3", BlobID: "B-logo", Type: "image/png", Disposition: "inline", CID: "logo@x"},
+		{PartID: "4", BlobID: "B-pdf", Type: "application/pdf", Name: "plan.pdf", Disposition: "attachment"},
+	}
+
+	tests := []struct {
+		name      string
+		body      string
+		wantBlobs []jmap.ID
+	}{
+		{name: "carried-over body keeps inline parts", wantBlobs: []jmap.ID{"B-logo", "B-pdf"}},
+		{name: "new body keeps attachments", body: "New body", wantBlobs: []jmap.ID{"B-logo", "B-pdf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var creates, updates []*email.Set
+			var mailboxSets []*mailbox.Set
+			c := testClientForDraft(mockDraftEdit(existing, &creates, &updates, &mailboxSets))
+
+			if _, err := c.CreateDraft(DraftOptions{Mode: DraftModeEdit, OriginalID: "M-old", Body: tt.body}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, draft := range creates[0].Create {
+				var blobs []jmap.ID
+				for _, a := range draft.Attachments {
+					if a.PartID != "" {
+						t.Errorf("expected blob reference without part ID, got %+v", a)
+					}
+					blobs = append(blobs, a.BlobID)
+				}
+				if !reflect.DeepEqual(blobs, tt.wantBlobs) {
+					t.Errorf("attachments = %v, want %v", blobs, tt.wantBlobs)
+				}
+				if draft.Attachments[1].Name != "plan.pdf" || draft.Attachments[0].CID != "logo@x" {
+					t.Errorf("expected name and cid kept, got %+v %+v", draft.Attachments[0], draft.Attachments[1])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateDraft_EditFormatNeedsNewBody(t *testing.T) {
+	var creates, updates []*email.Set
+	var mailboxSets []*mailbox.Set
+	c := testClientForDraft(mockDraftEdit(existingDraft(), &creates, &updates, &mailboxSets))
+
+	_, err := c.CreateDraft(DraftOptions{Mode: DraftModeEdit, OriginalID: "M-old", Markdown: true})
+	if err == nil || !strings.Contains(err.Error(), "new body") {
+		t.Fatalf("expected new body error, got %v", err)
+	}
+	if len(creates) != 0 || len(updates) != 0 {
+		t.Error("expected no mutations")
+	}
+}
+
+func TestCreateDraft_EditRefusesNonDraft(t *testing.T) {
+	existing := existingDraft()
+	existing.Keywords = map[string]bool{"$seen": true}
+
+	var creates, updates []*email.Set
+	var mailboxSets []*mailbox.Set
+	c := testClientForDraft(mockDraftEdit(existing, &creates, &updates, &mailboxSets))
+
+	_, err := c.CreateDraft(DraftOptions{Mode: DraftModeEdit, OriginalID: "M-old", Body: "x"})
+	if err == nil {
+		t.Fatal("expected error for email without $draft")
+	}
+	if _, ok := err.(*ErrForbidden); !ok {
+		t.Errorf("expected *ErrForbidden, got %T", err)
+	}
+	if len(creates) != 0 || len(updates) != 0 || len(mailboxSets) != 0 {
+		t.Error("expected no mutations for a non-draft email")
+	}
+}
+
+func TestCreateDraft_EditArchiveFailureReported(t *testing.T) {
+	var creates []*email.Set
+	c := testClientForDraft(func(req *jmap.Request) (*jmap.Response, error) {
+		switch args := req.Calls[0].Args.(type) {
+		case *email.Get:
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/get", CallID: "0", Args: &email.GetResponse{List: []*email.Email{existingDraft()}}},
+			}}, nil
+		case *email.Set:
+			if len(args.Create) > 0 {
+				creates = append(creates, args)
+				return mockDraftCreateSuccess("M-revised")(req)
+			}
+			desc := "locked"
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/set", CallID: "0", Args: &email.SetResponse{
+					NotUpdated: map[jmap.ID]*jmap.SetError{"M-old": {Type: "forbidden", Description: &desc}},
+				}},
+			}}, nil
+		}
+		return nil, fmt.Errorf("unexpected request")
+	})
+	c.mailboxCache = append(c.mailboxCache, &mailbox.Mailbox{ID: "mb-existing", Name: "Draft Revisions"})
+
+	result, err := c.CreateDraft(DraftOptions{Mode: DraftModeEdit, OriginalID: "M-old", Body: "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ID != "M-revised" {
+		t.Errorf("expected revised draft ID, got %q", result.ID)
+	}
+	if !strings.Contains(result.ArchiveError, "locked") {
+		t.Errorf("expected archive error to be reported, got %q", result.ArchiveError)
+	}
+	if result.ArchivedTo != nil {
+		t.Errorf("expected no ArchivedTo on failure, got %+v", result.ArchivedTo)
+	}
+}
+
+func TestListDrafts_FiltersDraftsMailboxAndKeyword(t *testing.T) {
+	var captured *email.Query
+	c := testClientForDraft(func(req *jmap.Request) (*jmap.Response, error) {
+		captured = req.Calls[0].Args.(*email.Query)
+		return &jmap.Response{Responses: []*jmap.Invocation{
+			{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: 1, IDs: []jmap.ID{"M-d1"}}},
+			{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: []*email.Email{
+				{ID: "M-d1", Subject: "Draft one", Keywords: map[string]bool{"$draft": true, "$seen": true}},
+			}}},
+		}}, nil
+	})
+
+	result, err := c.ListDrafts(10, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fc, ok := captured.Filter.(*email.FilterCondition)
+	if !ok {
+		t.Fatalf("expected *email.FilterCondition, got %T", captured.Filter)
+	}
+	if fc.InMailbox != testDraftsID {
+		t.Errorf("expected InMailbox %s, got %s", testDraftsID, fc.InMailbox)
+	}
+	if fc.HasKeyword != "$draft" {
+		t.Errorf("expected HasKeyword $draft, got %q", fc.HasKeyword)
+	}
+	if captured.Limit != 10 {
+		t.Errorf("expected limit 10, got %d", captured.Limit)
+	}
+	if result.Total != 1 || len(result.Emails) != 1 || result.Emails[0].ID != "M-d1" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// DuplicatesMailboxName is the top-level review folder duplicate copies are
+// moved into. It is created on first use.
+const DuplicatesMailboxName = "Duplicates"
+
+// Duplicate match kinds.
+const (
+	DuplicateByMessageID   = "message_id"
+	DuplicateByFingerprint = "fingerprint"
+)
+
+// DuplicateOptions holds parameters for duplicate detection.
+type DuplicateOptions struct {
+	MailboxID string // restrict to one mailbox; empty scans the whole account
+	Limit     int    // groups to keep, and whose copies to list; 0 keeps all
+}
+
+// duplicateProperties are the Email/get properties for duplicate detection.
+var duplicateProperties = []string{
+	"id", "messageId", "mailboxIds", "from", "subject", "sentAt", "receivedAt", "size",
+}
+
+// FindDuplicates groups emails that are separate copies of one message:
+// those sharing a Message-ID, or, for emails without one, sharing size,
+// sent time, sender, and subject. An email filed in several mailboxes is a
+// single email in JMAP and is not a duplicate. Emails only in Trash are
+// ignored.
+//
+// In each group the earliest-received copy outside the Duplicates folder is
+// kept. DuplicateIDs lists the other copies in the groups returned, except
+// those already only in the Duplicates folder.
+func (c *Client) FindDuplicates(opts DuplicateOptions) (types.DuplicatesResult, error) {
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return types.DuplicatesResult{}, err
+	}
+	byID := mailboxesByID(mailboxes)
+
+	var reviewID jmap.ID
+	if mb, err := c.TopLevelMailbox(DuplicatesMailboxName); err == nil {
+		reviewID = mb.ID
+	} else if !errors.Is(err, ErrNotFound) {
+		return types.DuplicatesResult{}, err
+	}
+
+	filter := &email.FilterCondition{InMailbox: jmap.ID(opts.MailboxID)}
+	if trash, err := c.GetMailboxByRole(mailbox.RoleTrash); err == nil {
+		filter.InMailboxOtherThan = []jmap.ID{trash.ID}
+	}
+
+	var result types.DuplicatesResult
+	groups := make(map[string][]*email.Email)
+	var order []string
+	_, err = c.scanEmails("duplicates query", filter, duplicateProperties, nil, func(e *email.Email) {
+		result.Scanned++
+		key := duplicateKey(e)
+		if key == "" {
+			return
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], e)
+	})
+	if err != nil {
+		return types.DuplicatesResult{}, err
+	}
+
+	onlyInReview := func(e *email.Email) bool {
+		if reviewID == "" {
+			return false
+		}
+		for id, in := range e.MailboxIDs {
+			if in && id != reviewID {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Each group carries the IDs of the copies that would move, so the
+	// groups left after the limit decide what is moved.
+	type found struct {
+		group types.DuplicateGroup
+		ids   []string
+	}
+	var all []found
+	for _, key := range order {
+		copies := groups[key]
+		if len(copies) < 2 {
+			continue
+		}
+		sort.SliceStable(copies, func(i, j int) bool {
+			ri, rj := onlyInReview(copies[i]), onlyInReview(copies[j])
+			if ri != rj {
+				return !ri
+			}
+			ti, tj := receivedTime(copies[i]), receivedTime(copies[j])
+			if !ti.Equal(tj) {
+				return ti.Before(tj)
+			}
+			return copies[i].ID < copies[j].ID
+		})
+
+		keep := copies[0]
+		f := found{group: types.DuplicateGroup{
+			MatchedBy: DuplicateByFingerprint,
+			From:      senderAddress(keep),
+			Subject:   keep.Subject,
+			SentAt:    keep.SentAt,
+			Keep:      duplicateCopy(keep, byID),
+		}}
+		if len(keep.MessageID) > 0 {
+			f.group.MatchedBy = DuplicateByMessageID
+			f.group.MessageID = keep.MessageID[0]
+		}
+		for _, e := range copies[1:] {
+			f.group.Duplicates = append(f.group.Duplicates, duplicateCopy(e, byID))
+			if !onlyInReview(e) {
+				f.ids = append(f.ids, string(e.ID))
+			}
+		}
+		all = append(all, f)
+		result.DuplicateCount += len(f.group.Duplicates)
+	}
+	result.GroupCount = len(all)
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return len(all[i].group.Duplicates) > len(all[j].group.Duplicates)
+	})
+	if opts.Limit > 0 && len(all) > opts.Limit {
+		all = all[:opts.Limit]
+	}
+	result.Groups = []types.DuplicateGroup{}
+	result.DuplicateIDs = []string{}
+	for _, f := range all {
+		result.Groups = append(result.Groups, f.group)
+		result.DuplicateIDs = append(result.DuplicateIDs, f.ids...)
+	}
+	return result, nil
+}
+
+// duplicateKey returns the key that copies of e share: its Message-ID, or
+// a fingerprint of size, sent time, sender, and subject when it has none.
+// Emails without a Message-ID, sent time, or sender get no key and are
+// never grouped.
+func duplicateKey(e *email.Email) string {
+	if len(e.MessageID) > 0 {
+		return DuplicateByMessageID + ":" + e.MessageID[0]
+	}
+	from := senderAddress(e)
+	if e.SentAt == nil || from == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d|%s|%s|%s", DuplicateByFingerprint,
+		e.Size, e.SentAt.UTC().Format(time.RFC3339), from, strings.TrimSpace(e.Subject))
+}
+
+// duplicateCopy describes one copy of a duplicated email.
+func duplicateCopy(e *email.Email, byID map[jmap.ID]*mailbox.Mailbox) types.DuplicateCopy {
+	d := types.DuplicateCopy{ID: string(e.ID), ReceivedAt: receivedTime(e), Size: e.Size, Mailboxes: []string{}}
+	for id, in := range e.MailboxIDs {
+		if !in {
+			continue
+		}
+		if mb := byID[id]; mb != nil {
+			d.Mailboxes = append(d.Mailboxes, mailboxPath(mb, byID))
+		} else {
+			d.Mailboxes = append(d.Mailboxes, string(id))
+		}
+	}
+	sort.Strings(d.Mailboxes)
+	return d
+}
+
+// receivedTime returns when e was received, or the zero time if unknown.
+func receivedTime(e *email.Email) time.Time {
+	if e.ReceivedAt == nil {
+		return time.Time{}
+	}
+	return *e.ReceivedAt
+}
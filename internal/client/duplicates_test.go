@@ -0,0 +1,170 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+)
+
+// duplicatesClient serves emails from a single whole-account scan and
+// records the filter it was given.
+func duplicatesClient(emails []*email.Email, filter **email.FilterCondition) *Client {
+	return &Client{
+		accountID: "acct-1",
+		mailboxCache: []*mailbox.Mailbox{
+			{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox},
+			{ID: "mb-archive", Name: "Archive", Role: mailbox.RoleArchive},
+			{ID: "mb-trash", Name: "Trash", Role: mailbox.RoleTrash},
+			{ID: "mb-receipts", Name: "Receipts"},
+			{ID: "mb-r2025", Name: "2025", ParentID: "mb-receipts"},
+			{ID: "mb-dup", Name: "Duplicates"},
+		},
+		doFunc: scanStub(func(q *email.Query, _ *email.Get) []*email.Email {
+			if filter != nil {
+				*filter = q.Filter.(*email.FilterCondition)
+			}
+			return emails
+		}),
+	}
+}
+
+func dupEmail(id, messageID string, received time.Time, mailboxes ...jmap.ID) *email.Email {
+	sent := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	e := &email.Email{
+		ID:         jmap.ID(id),
+		From:       []*mail.Address{{Email: "Billing@Example.com"}},
+		Subject:    "Invoice",
+		SentAt:     &sent,
+		ReceivedAt: &received,
+		Size:       2048,
+		MailboxIDs: map[jmap.ID]bool{},
+	}
+	if messageID != "" {
+		e.MessageID = []string{messageID}
+	}
+	for _, mb := range mailboxes {
+		e.MailboxIDs[mb] = true
+	}
+	return e
+}
+
+func TestFindDuplicates_GroupsByMessageID(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var filter *email.FilterCondition
+	c := duplicatesClient([]*email.Email{
+		dupEmail("M3", "a@example.com", day.Add(3*time.Hour), "mb-inbox"),
+		dupEmail("M1", "a@example.com", day.Add(1*time.Hour), "mb-archive", "mb-r2025"),
+		dupEmail("M2", "a@example.com", day.Add(2*time.Hour), "mb-inbox"),
+		dupEmail("M4", "other@example.com", day, "mb-inbox"),
+	}, &filter)
+
+	result, err := c.FindDuplicates(DuplicateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Scanned != 4 || result.GroupCount != 1 || result.DuplicateCount != 2 {
+		t.Fatalf("expected 4 scanned, 1 group, 2 duplicates, got %+v", result)
+	}
+	g := result.Groups[0]
+	if g.MatchedBy != DuplicateByMessageID || g.MessageID != "a@example.com" {
+		t.Errorf("expected message_id match, got %s %q", g.MatchedBy, g.MessageID)
+	}
+	if g.Keep.ID != "M1" {
+		t.Errorf("expected earliest copy M1 kept, got %s", g.Keep.ID)
+	}
+	if len(g.Keep.Mailboxes) != 2 || g.Keep.Mailboxes[0] != "Archive" || g.Keep.Mailboxes[1] != "Receipts/2025" {
+		t.Errorf("expected kept copy's mailbox paths, got %v", g.Keep.Mailboxes)
+	}
+	if g.From != "billing@example.com" {
+		t.Errorf("expected lowercased sender, got %s", g.From)
+	}
+	if len(result.DuplicateIDs) != 2 || result.DuplicateIDs[0] != "M2" || result.DuplicateIDs[1] != "M3" {
+		t.Errorf("expected M2 and M3 to move, got %v", result.DuplicateIDs)
+	}
+	if len(filter.InMailboxOtherThan) != 1 || filter.InMailboxOtherThan[0] != "mb-trash" {
+		t.Errorf("expected Trash-only emails to be excluded, got %+v", filter)
+	}
+}
+
+func TestFindDuplicates_FingerprintFallback(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	different := dupEmail("M3", "", day, "mb-inbox")
+	different.Size = 4096
+	noSent := dupEmail("M4", "", day, "mb-inbox")
+	noSent.SentAt = nil
+	noSent2 := dupEmail("M5", "", day, "mb-inbox")
+	noSent2.SentAt = nil
+	c := duplicatesClient([]*email.Email{
+		dupEmail("M1", "", day, "mb-inbox"),
+		dupEmail("M2", "", day.Add(time.Hour), "mb-archive"),
+		different, noSent, noSent2,
+	}, nil)
+
+	result, err := c.FindDuplicates(DuplicateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.GroupCount != 1 {
+		t.Fatalf("expected 1 group, got %+v", result.Groups)
+	}
+	g := result.Groups[0]
+	if g.MatchedBy != DuplicateByFingerprint || g.MessageID != "" {
+		t.Errorf("expected fingerprint match, got %s %q", g.MatchedBy, g.MessageID)
+	}
+	if g.Keep.ID != "M1" || len(g.Duplicates) != 1 || g.Duplicates[0].ID != "M2" {
+		t.Errorf("expected M1 kept and M2 duplicate, got %+v", g)
+	}
+}
+
+func TestFindDuplicates_SkipsCopiesAlreadyInReviewFolder(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	c := duplicatesClient([]*email.Email{
+		dupEmail("M1", "a@example.com", day, "mb-dup"),
+		dupEmail("M2", "a@example.com", day.Add(time.Hour), "mb-inbox"),
+		dupEmail("M3", "a@example.com", day.Add(2*time.Hour), "mb-inbox"),
+	}, nil)
+
+	result, err := c.FindDuplicates(DuplicateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Groups[0].Keep.ID != "M2" {
+		t.Errorf("expected a copy outside Duplicates to be kept, got %s", result.Groups[0].Keep.ID)
+	}
+	if len(result.DuplicateIDs) != 1 || result.DuplicateIDs[0] != "M3" {
+		t.Errorf("expected only M3 to move, got %v", result.DuplicateIDs)
+	}
+}
+
+func TestFindDuplicates_LimitAppliesToIDs(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	c := duplicatesClient([]*email.Email{
+		dupEmail("A1", "a@example.com", day, "mb-inbox"),
+		dupEmail("A2", "a@example.com", day.Add(time.Hour), "mb-inbox"),
+		dupEmail("B1", "b@example.com", day, "mb-inbox"),
+		dupEmail("B2", "b@example.com", day.Add(time.Hour), "mb-inbox"),
+		dupEmail("B3", "b@example.com", day.Add(2*time.Hour), "mb-inbox"),
+	}, nil)
+
+	result, err := c.FindDuplicates(DuplicateOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.GroupCount != 2 || len(result.Groups) != 1 {
+		t.Fatalf("expected 1 of 2 groups shown, got %d of %d", len(result.Groups), result.GroupCount)
+	}
+	if result.Groups[0].MessageID != "b@example.com" {
+		t.Errorf("expected group with most copies first, got %s", result.Groups[0].MessageID)
+	}
+	if got := strings.Join(result.DuplicateIDs, ","); got != "B2,B3" {
+		t.Errorf("expected only the shown group's duplicates, got %s", got)
+	}
+	if result.DuplicateCount != 3 {
+		t.Errorf("expected duplicate count across all groups, got %d", result.DuplicateCount)
+	}
+}
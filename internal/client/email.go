@@ -12,6 +12,7 @@ import (
 	"git.sr.ht/~rockorager/go-jmap/mail/searchsnippet"
 	"git.sr.ht/~rockorager/go-jmap/mail/thread"
 
+	"github.com/cboone/fm/internal/classify"
 	"github.com/cboone/fm/internal/types"
 )
 
@@ -26,7 +27,8 @@ func (m *searchSnippetGet) Name() string { return "SearchSnippet/get" }
 func (m *searchSnippetGet) Requires() []jmap.URI { return []jmap.URI{mail.URI} }
 
 // batchSetEmails executes Email/set in server-aware batches.
-// patchFn builds the jmap.Patch for a single email ID.
+// patchFn builds the jmap.Patch for a single email ID. Emails beyond the
+// mutation budget, when one is set, are refused without being sent.
 func (c *Client) batchSetEmails(emailIDs []string, patchFn func(string) jmap.Patch) (succeeded, errors []string) {
 	size := c.maxBatchSize()
 	succeeded = []string{}
@@ -37,7 +39,11 @@ func (c *Client) batchSetEmails(emailIDs []string, patchFn func(string) jmap.Pat
 		if end > len(emailIDs) {
 			end = len(emailIDs)
 		}
-		batch := emailIDs[start:end]
+		batch, refused := c.takeBudget(emailIDs[start:end])
+		errors = append(errors, refused...)
+		if len(batch) == 0 {
+			continue
+		}
 
 		updates := make(map[jmap.ID]jmap.Patch, len(batch))
 		for _, id := range batch {
@@ -58,6 +64,7 @@ func (c *Client) batchSetEmails(emailIDs []string, patchFn func(string) jmap.Pat
 			continue
 		}
 
+		before := len(succeeded)
 		for _, inv := range resp.Responses {
 			switch r := inv.Args.(type) {
 			case *email.SetResponse:
@@ -81,6 +88,7 @@ func (c *Client) batchSetEmails(emailIDs []string, patchFn func(string) jmap.Pat
 				}
 			}
 		}
+		c.spendBudget(len(succeeded) - before)
 	}
 	return succeeded, errors
 }
@@ -108,6 +116,7 @@ type ListOptions struct {
 	UnflaggedOnly   bool
 	SortField       string
 	SortAsc         bool
+	Category        string // keep only senders in this classify category
 }
 
 // ListEmails queries emails in a mailbox and returns summaries.
@@ -150,11 +159,16 @@ func (c *Client) ListEmails(opts ListOptions) (types.EmailListResult, error) {
 		}
 	}
 
+	order := []*email.SortComparator{{Property: opts.SortField, IsAscending: opts.SortAsc}}
+	if opts.Category != "" {
+		return c.listByCategory("email query", filter, order, opts.Category, opts.Offset, opts.Limit, "")
+	}
+
 	req := &jmap.Request{}
 	queryCallID := req.Invoke(&email.Query{
 		Account:        c.accountID,
 		Filter:         filter,
-		Sort:           []*email.SortComparator{{Property: opts.SortField, IsAscending: opts.SortAsc}},
+		Sort:           order,
 		Position:       opts.Offset,
 		Limit:          opts.Limit,
 		CalculateTotal: true,
@@ -399,6 +413,11 @@ func (c *Client) SearchEmails(opts SearchOptions) (types.EmailListResult, error)
 		sortField = "receivedAt"
 	}
 
+	if opts.Category != "" {
+		order := []*email.SortComparator{{Property: sortField, IsAscending: opts.SortAsc}}
+		return c.listByCategory("search", filter, order, opts.Category, opts.Offset, opts.Limit, opts.Text)
+	}
+
 	// Track call IDs so we can identify which method failed in errors.
 	callMethods := make(map[string]string) // callID -> method name
 
@@ -512,6 +531,7 @@ type SearchOptions struct {
 	Offset        int64
 	SortField     string
 	SortAsc       bool
+	Category      string // keep only senders in this classify category
 }
 
 const defaultQueryPageSize = 250
@@ -562,6 +582,77 @@ func (c *Client) QueryEmailIDs(opts SearchOptions) ([]string, error) {
 	return collected, nil
 }
 
+// analyticsPageSize is the Email/query page size for commands that read
+// every matching email.
+const analyticsPageSize = 500
+
+// scanEmails pages through every email matching filter, newest first,
+// fetching properties (and bodyProperties for body parts such as
+// attachments, when set) for each page and passing the emails to fn. It
+// returns the total reported by the server. what names the operation in
+// errors.
+func (c *Client) scanEmails(what string, filter email.Filter, properties, bodyProperties []string, fn func(*email.Email)) (uint64, error) {
+	newestFirst := []*email.SortComparator{{Property: "receivedAt", IsAscending: false}}
+	return c.scanEmailsSorted(what, filter, newestFirst, properties, bodyProperties, fn)
+}
+
+// scanEmailsSorted is scanEmails with the order given by order.
+func (c *Client) scanEmailsSorted(what string, filter email.Filter, order []*email.SortComparator, properties, bodyProperties []string, fn func(*email.Email)) (uint64, error) {
+	var total uint64
+	var position int64
+
+	for {
+		req := &jmap.Request{}
+		queryCallID := req.Invoke(&email.Query{
+			Account:        c.accountID,
+			Filter:         filter,
+			Sort:           order,
+			Position:       position,
+			Limit:          analyticsPageSize,
+			CalculateTotal: true,
+		})
+
+		req.Invoke(&email.Get{
+			Account:        c.accountID,
+			Properties:     properties,
+			BodyProperties: bodyProperties,
+			ReferenceIDs: &jmap.ResultReference{
+				ResultOf: queryCallID,
+				Name:     "Email/query",
+				Path:     "/ids",
+			},
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", what, err)
+		}
+
+		var pageIDs []jmap.ID
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.QueryResponse:
+				if position == 0 {
+					total = r.Total
+				}
+				pageIDs = r.IDs
+			case *email.GetResponse:
+				for _, e := range r.List {
+					fn(e)
+				}
+			case *jmap.MethodError:
+				return 0, fmt.Errorf("%s: %s", what, r.Error())
+			}
+		}
+
+		position += int64(len(pageIDs))
+		if uint64(position) >= total || len(pageIDs) == 0 {
+			break
+		}
+	}
+	return total, nil
+}
+
 // StatsOptions holds parameters for sender aggregation.
 type StatsOptions struct {
 	MailboxID     string
@@ -720,7 +811,10 @@ type SummaryOptions struct {
 var summaryBaseProperties = []string{"id", "from", "subject", "keywords"}
 
 // AggregateSummary queries all matching emails and returns a triage summary
-// with top senders, top domains, unread count, and optional newsletter detection.
+// with top senders, top domains, unread count, and, when Newsletters is set,
+// every sender classified with the classify package: top senders carry
+// their category and score, newsletters are listed, and messages are
+// counted per category.
 func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, error) {
 	fc := &email.FilterCondition{
 		InMailbox: jmap.ID(opts.MailboxID),
@@ -751,11 +845,11 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 	}
 
 	type senderAcc struct {
-		count        int
-		name         string
-		subjects     map[string]bool
-		isNewsletter bool
+		count    int
+		name     string
+		subjects map[string]bool
 	}
+	classifier := classify.New()
 	senders := make(map[string]*senderAcc)
 	domains := make(map[string]int)
 	var total uint64
@@ -826,8 +920,8 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 			if opts.Subjects && e.Subject != "" {
 				acc.subjects[e.Subject] = true
 			}
-			if opts.Newsletters && hasListHeaders(e.Headers) {
-				acc.isNewsletter = true
+			if opts.Newsletters {
+				classifier.Add(key, classifyHeaders(e.Headers))
 			}
 
 			domain := extractDomain(e.From[0].Email)
@@ -842,13 +936,23 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 		}
 	}
 
+	// Classify every sender once all their messages have been seen.
+	classes := make(map[string]types.Classification)
+	if opts.Newsletters {
+		for addr := range senders {
+			classes[addr] = classifier.Classify(addr)
+		}
+	}
+
 	// Build top senders.
 	topSenders := make([]types.SenderStat, 0, len(senders))
 	for addr, acc := range senders {
 		stat := types.SenderStat{
-			Email: addr,
-			Name:  acc.name,
-			Count: acc.count,
+			Email:    addr,
+			Name:     acc.name,
+			Count:    acc.count,
+			Category: classes[addr].Category,
+			Score:    classes[addr].Score,
 		}
 		if opts.Subjects && len(acc.subjects) > 0 {
 			subjects := make([]string, 0, len(acc.subjects))
@@ -892,17 +996,29 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 		TopDomains: topDomains,
 	}
 
-	// Build newsletter list if requested.
+	// Build newsletter list and category breakdown if requested.
 	if opts.Newsletters {
 		var newsletters []types.SenderStat
+		categories := make(map[string]*types.CategoryStat)
 		for addr, acc := range senders {
-			if !acc.isNewsletter {
+			class := classes[addr]
+			cat, ok := categories[class.Category]
+			if !ok {
+				cat = &types.CategoryStat{Category: class.Category}
+				categories[class.Category] = cat
+			}
+			cat.Senders++
+			cat.Count += acc.count
+
+			if class.Category != classify.Newsletter {
 				continue
 			}
 			stat := types.SenderStat{
-				Email: addr,
-				Name:  acc.name,
-				Count: acc.count,
+				Email:    addr,
+				Name:     acc.name,
+				Count:    acc.count,
+				Category: class.Category,
+				Score:    class.Score,
 			}
 			if opts.Subjects && len(acc.subjects) > 0 {
 				subjects := make([]string, 0, len(acc.subjects))
@@ -921,6 +1037,11 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 			return newsletters[i].Email < newsletters[j].Email
 		})
 		result.Newsletters = newsletters
+		for _, name := range classify.Categories {
+			if cat, ok := categories[name]; ok {
+				result.Categories = append(result.Categories, *cat)
+			}
+		}
 	}
 
 	return result, nil
@@ -1,7 +1,10 @@
 package client
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"git.sr.ht/~rockorager/go-jmap"
@@ -54,24 +57,95 @@ func (c *Client) GetMailboxByRole(role mailbox.Role) (*mailbox.Mailbox, error) {
 	return nil, fmt.Errorf("no mailbox found with role %q", role)
 }
 
-// GetMailboxByNameOrID finds a mailbox by name (case-insensitive) or by ID.
+// ErrAmbiguousMailbox reports a mailbox name that matches more than one
+// mailbox. Candidates holds the full path and ID of each match.
+type ErrAmbiguousMailbox struct {
+	Name       string
+	Candidates []string
+}
+
+func (e *ErrAmbiguousMailbox) Error() string {
+	return fmt.Sprintf("mailbox %q is ambiguous; use a full path or ID: %s", e.Name, strings.Join(e.Candidates, ", "))
+}
+
+// GetMailboxByNameOrID finds a mailbox by ID, by full path such as
+// "Receipts/2025", or by name, in that order. Paths and names are matched
+// case-insensitively; a name shared by several mailboxes is an
+// *ErrAmbiguousMailbox listing them.
 func (c *Client) GetMailboxByNameOrID(nameOrID string) (*mailbox.Mailbox, error) {
 	mailboxes, err := c.GetAllMailboxes()
 	if err != nil {
 		return nil, err
 	}
-	lower := strings.ToLower(nameOrID)
 	for _, mb := range mailboxes {
 		if string(mb.ID) == nameOrID {
 			return mb, nil
 		}
+	}
+
+	byID := mailboxesByID(mailboxes)
+	lower := strings.ToLower(strings.Trim(nameOrID, "/"))
+	var byPath, byName []*mailbox.Mailbox
+	for _, mb := range mailboxes {
+		if strings.ToLower(mailboxPath(mb, byID)) == lower {
+			byPath = append(byPath, mb)
+		}
 		if strings.ToLower(mb.Name) == lower {
-			return mb, nil
+			byName = append(byName, mb)
 		}
 	}
+	for _, matches := range [][]*mailbox.Mailbox{byPath, byName} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return matches[0], nil
+		}
+		candidates := make([]string, len(matches))
+		for i, mb := range matches {
+			candidates[i] = fmt.Sprintf("%q (%s)", mailboxPath(mb, byID), mb.ID)
+		}
+		return nil, &ErrAmbiguousMailbox{Name: nameOrID, Candidates: candidates}
+	}
 	return nil, fmt.Errorf("mailbox not found: %q", nameOrID)
 }
 
+// MailboxPath returns the full path of mb, its ancestors' names and its own
+// joined by "/", such as "Receipts/2025".
+func (c *Client) MailboxPath(mb *mailbox.Mailbox) string {
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return mb.Name
+	}
+	return mailboxPath(mb, mailboxesByID(mailboxes))
+}
+
+// Destination describes mb as the target of a move.
+func (c *Client) Destination(mb *mailbox.Mailbox) *types.DestinationInfo {
+	return &types.DestinationInfo{ID: string(mb.ID), Name: mb.Name, Path: c.MailboxPath(mb)}
+}
+
+func mailboxesByID(mailboxes []*mailbox.Mailbox) map[jmap.ID]*mailbox.Mailbox {
+	byID := make(map[jmap.ID]*mailbox.Mailbox, len(mailboxes))
+	for _, mb := range mailboxes {
+		byID[mb.ID] = mb
+	}
+	return byID
+}
+
+// mailboxPath walks up from mb through byID. A parent that is missing, or
+// a cycle, ends the walk.
+func mailboxPath(mb *mailbox.Mailbox, byID map[jmap.ID]*mailbox.Mailbox) string {
+	parts := []string{mb.Name}
+	seen := map[jmap.ID]bool{mb.ID: true}
+	for parent := byID[mb.ParentID]; parent != nil && !seen[parent.ID]; parent = byID[parent.ParentID] {
+		seen[parent.ID] = true
+		parts = append(parts, parent.Name)
+	}
+	slices.Reverse(parts)
+	return strings.Join(parts, "/")
+}
+
 // ResolveMailboxID resolves "inbox", other role names, a mailbox name, or a
 // raw mailbox ID to a JMAP mailbox ID.
 func (c *Client) ResolveMailboxID(nameOrID string) (jmap.ID, error) {
@@ -100,19 +174,156 @@ func (c *Client) ListMailboxes(rolesOnly bool) ([]types.MailboxInfo, error) {
 		return nil, err
 	}
 
+	byID := mailboxesByID(mailboxes)
 	var result []types.MailboxInfo
 	for _, mb := range mailboxes {
 		if rolesOnly && mb.Role == "" {
 			continue
 		}
-		result = append(result, types.MailboxInfo{
-			ID:           string(mb.ID),
-			Name:         mb.Name,
-			Role:         string(mb.Role),
-			TotalEmails:  mb.TotalEmails,
-			UnreadEmails: mb.UnreadEmails,
-			ParentID:     string(mb.ParentID),
-		})
+		result = append(result, mailboxInfo(mb, byID))
 	}
 	return result, nil
 }
+
+func mailboxInfo(mb *mailbox.Mailbox, byID map[jmap.ID]*mailbox.Mailbox) types.MailboxInfo {
+	return types.MailboxInfo{
+		ID:           string(mb.ID),
+		Name:         mb.Name,
+		Path:         mailboxPath(mb, byID),
+		Role:         string(mb.Role),
+		TotalEmails:  mb.TotalEmails,
+		UnreadEmails: mb.UnreadEmails,
+		ParentID:     string(mb.ParentID),
+	}
+}
+
+// MailboxTree returns the mailboxes as a tree, children ordered by their
+// sort order and then name. Each node's subtree counts add its own counts
+// to those of every mailbox below it. Mailboxes whose parent is missing, or
+// whose ancestors form a cycle, are shown at the top level.
+func (c *Client) MailboxTree() ([]types.MailboxNode, error) {
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return nil, err
+	}
+	byID := mailboxesByID(mailboxes)
+	children := make(map[jmap.ID][]*mailbox.Mailbox)
+	var roots []*mailbox.Mailbox
+	for _, mb := range mailboxes {
+		if parent := byID[mb.ParentID]; parent != nil && parent.ID != mb.ID {
+			children[mb.ParentID] = append(children[mb.ParentID], mb)
+		} else {
+			roots = append(roots, mb)
+		}
+	}
+
+	seen := make(map[jmap.ID]bool)
+	var build func([]*mailbox.Mailbox) []types.MailboxNode
+	build = func(level []*mailbox.Mailbox) []types.MailboxNode {
+		sort.SliceStable(level, func(i, j int) bool {
+			if level[i].SortOrder != level[j].SortOrder {
+				return level[i].SortOrder < level[j].SortOrder
+			}
+			return strings.ToLower(level[i].Name) < strings.ToLower(level[j].Name)
+		})
+		nodes := []types.MailboxNode{}
+		for _, mb := range level {
+			if seen[mb.ID] {
+				continue
+			}
+			seen[mb.ID] = true
+			node := types.MailboxNode{
+				MailboxInfo:   mailboxInfo(mb, byID),
+				SubtreeTotal:  mb.TotalEmails,
+				SubtreeUnread: mb.UnreadEmails,
+				Children:      build(children[mb.ID]),
+			}
+			for _, child := range node.Children {
+				node.SubtreeTotal += child.SubtreeTotal
+				node.SubtreeUnread += child.SubtreeUnread
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	nodes := build(roots)
+	// Mailboxes whose parents form a cycle are never reached from a root.
+	for _, mb := range mailboxes {
+		if !seen[mb.ID] {
+			nodes = append(nodes, build([]*mailbox.Mailbox{mb})...)
+		}
+	}
+	return nodes, nil
+}
+
+// TopLevelMailbox returns the top-level mailbox with the given name
+// (case-insensitive), or ErrNotFound.
+func (c *Client) TopLevelMailbox(name string) (*mailbox.Mailbox, error) {
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return nil, err
+	}
+	lower := strings.ToLower(name)
+	for _, mb := range mailboxes {
+		if mb.ParentID == "" && strings.ToLower(mb.Name) == lower {
+			return mb, nil
+		}
+	}
+	return nil, fmt.Errorf("mailbox %q: %w", name, ErrNotFound)
+}
+
+// EnsureMailbox returns the top-level mailbox with the given name, creating
+// it with Mailbox/set when it does not exist yet. The name is checked with
+// ValidateTargetMailbox so a trash folder can never be created or returned.
+func (c *Client) EnsureMailbox(name string) (*mailbox.Mailbox, error) {
+	if err := ValidateTargetMailbox(&mailbox.Mailbox{Name: name}); err != nil {
+		return nil, err
+	}
+
+	mb, err := c.TopLevelMailbox(name)
+	if err == nil {
+		if err := ValidateTargetMailbox(mb); err != nil {
+			return nil, err
+		}
+		return mb, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	createID := jmap.ID("mailbox-0")
+	req := &jmap.Request{}
+	req.Invoke(&mailbox.Set{
+		Account: c.accountID,
+		Create: map[jmap.ID]*mailbox.Mailbox{
+			createID: {Name: name},
+		},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("mailbox/set: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *mailbox.SetResponse:
+			if created, ok := r.Created[createID]; ok {
+				mb := &mailbox.Mailbox{ID: created.ID, Name: name}
+				c.mailboxCache = append(c.mailboxCache, mb)
+				return mb, nil
+			}
+			if setErr, ok := r.NotCreated[createID]; ok {
+				desc := "unknown error"
+				if setErr.Description != nil {
+					desc = *setErr.Description
+				}
+				return nil, fmt.Errorf("creating mailbox %q: %s", name, desc)
+			}
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("mailbox/set: %s", r.Error())
+		}
+	}
+
+	return nil, fmt.Errorf("mailbox/set: unexpected response")
+}
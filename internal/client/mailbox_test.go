@@ -0,0 +1,196 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+)
+
+// nestedMailboxClient has two folders named "2025", under Receipts and
+// under Taxes, and a top-level Archive.
+func nestedMailboxClient() *Client {
+	return &Client{
+		accountID: "acct-1",
+		mailboxCache: []*mailbox.Mailbox{
+			{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox, TotalEmails: 5, UnreadEmails: 2},
+			{ID: "mb-taxes", Name: "Taxes", SortOrder: 20, TotalEmails: 1},
+			{ID: "mb-receipts", Name: "Receipts", SortOrder: 10, TotalEmails: 10},
+			{ID: "mb-r2025", Name: "2025", ParentID: "mb-receipts", TotalEmails: 30, UnreadEmails: 3},
+			{ID: "mb-r2025-q1", Name: "Q1", ParentID: "mb-r2025", TotalEmails: 4, UnreadEmails: 1},
+			{ID: "mb-t2025", Name: "2025", ParentID: "mb-taxes", TotalEmails: 7},
+			{ID: "mb-archive", Name: "Archive", Role: mailbox.RoleArchive},
+		},
+	}
+}
+
+func TestGetMailboxByNameOrID_Path(t *testing.T) {
+	c := nestedMailboxClient()
+
+	for _, name := range []string{"Receipts/2025", "receipts/2025", "/Receipts/2025/"} {
+		mb, err := c.GetMailboxByNameOrID(name)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", name, err)
+		}
+		if mb.ID != "mb-r2025" {
+			t.Errorf("%q: expected mb-r2025, got %s", name, mb.ID)
+		}
+	}
+
+	mb, err := c.GetMailboxByNameOrID("Taxes/2025")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mb.ID != "mb-t2025" {
+		t.Errorf("expected mb-t2025, got %s", mb.ID)
+	}
+}
+
+func TestGetMailboxByNameOrID_UniqueNameAndID(t *testing.T) {
+	c := nestedMailboxClient()
+
+	mb, err := c.GetMailboxByNameOrID("q1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mb.ID != "mb-r2025-q1" {
+		t.Errorf("expected mb-r2025-q1, got %s", mb.ID)
+	}
+
+	mb, err = c.GetMailboxByNameOrID("mb-t2025")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mb.Name != "2025" {
+		t.Errorf("expected 2025, got %s", mb.Name)
+	}
+}
+
+func TestGetMailboxByNameOrID_Ambiguous(t *testing.T) {
+	c := nestedMailboxClient()
+
+	_, err := c.GetMailboxByNameOrID("2025")
+	var ambiguous *ErrAmbiguousMailbox
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected ErrAmbiguousMailbox, got %v", err)
+	}
+	if len(ambiguous.Candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %v", ambiguous.Candidates)
+	}
+	for _, want := range []string{`"Receipts/2025" (mb-r2025)`, `"Taxes/2025" (mb-t2025)`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %s in error, got %q", want, err.Error())
+		}
+	}
+}
+
+func TestGetMailboxByNameOrID_NotFound(t *testing.T) {
+	c := nestedMailboxClient()
+
+	_, err := c.GetMailboxByNameOrID("Receipts/2024")
+	if err == nil || !strings.Contains(err.Error(), "mailbox not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestMailboxPath_StopsAtCycle(t *testing.T) {
+	c := &Client{mailboxCache: []*mailbox.Mailbox{
+		{ID: "mb-a", Name: "A", ParentID: "mb-b"},
+		{ID: "mb-b", Name: "B", ParentID: "mb-a"},
+	}}
+	if got := c.MailboxPath(c.mailboxCache[0]); got != "B/A" {
+		t.Errorf("expected B/A, got %q", got)
+	}
+}
+
+func TestListMailboxes_IncludesPaths(t *testing.T) {
+	c := nestedMailboxClient()
+
+	mailboxes, err := c.ListMailboxes(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	paths := map[string]string{}
+	for _, mb := range mailboxes {
+		paths[mb.ID] = mb.Path
+	}
+	want := map[string]string{
+		"mb-inbox":    "Inbox",
+		"mb-r2025":    "Receipts/2025",
+		"mb-r2025-q1": "Receipts/2025/Q1",
+		"mb-t2025":    "Taxes/2025",
+	}
+	for id, path := range want {
+		if paths[id] != path {
+			t.Errorf("%s: expected path %q, got %q", id, path, paths[id])
+		}
+	}
+}
+
+func TestDestination_IncludesPath(t *testing.T) {
+	c := nestedMailboxClient()
+
+	dest := c.Destination(c.mailboxCache[3])
+	if dest.ID != "mb-r2025" || dest.Name != "2025" || dest.Path != "Receipts/2025" {
+		t.Errorf("unexpected destination: %+v", dest)
+	}
+}
+
+func TestMailboxTree_NestsAndSums(t *testing.T) {
+	c := nestedMailboxClient()
+
+	roots, err := c.MailboxTree()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, n := range roots {
+		names = append(names, n.Name)
+	}
+	if got := strings.Join(names, ","); got != "Archive,Inbox,Receipts,Taxes" {
+		t.Fatalf("expected roots ordered by sort order then name, got %s", got)
+	}
+
+	receipts := roots[2]
+	if receipts.SubtreeTotal != 44 || receipts.SubtreeUnread != 4 {
+		t.Errorf("expected Receipts subtree 44/4, got %d/%d", receipts.SubtreeTotal, receipts.SubtreeUnread)
+	}
+	if len(receipts.Children) != 1 || receipts.Children[0].Path != "Receipts/2025" {
+		t.Fatalf("expected Receipts/2025 child, got %+v", receipts.Children)
+	}
+	r2025 := receipts.Children[0]
+	if r2025.SubtreeTotal != 34 || r2025.SubtreeUnread != 4 {
+		t.Errorf("expected Receipts/2025 subtree 34/4, got %d/%d", r2025.SubtreeTotal, r2025.SubtreeUnread)
+	}
+	if len(r2025.Children) != 1 || r2025.Children[0].Name != "Q1" || r2025.Children[0].Children == nil {
+		t.Errorf("expected Q1 leaf with empty children, got %+v", r2025.Children)
+	}
+}
+
+func TestMailboxTree_OrphanIsTopLevel(t *testing.T) {
+	c := &Client{mailboxCache: []*mailbox.Mailbox{
+		{ID: "mb-orphan", Name: "Orphan", ParentID: "mb-gone"},
+	}}
+	roots, err := c.MailboxTree()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roots) != 1 || roots[0].Path != "Orphan" {
+		t.Errorf("expected orphan at top level, got %+v", roots)
+	}
+}
+
+func TestMailboxTree_CycleIsNotLost(t *testing.T) {
+	c := &Client{mailboxCache: []*mailbox.Mailbox{
+		{ID: "mb-a", Name: "A", ParentID: "mb-b"},
+		{ID: "mb-b", Name: "B", ParentID: "mb-a"},
+	}}
+	roots, err := c.MailboxTree()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roots) != 1 || len(roots[0].Children) != 1 {
+		t.Errorf("expected both mailboxes in the tree, got %+v", roots)
+	}
+}
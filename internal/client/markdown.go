@@ -0,0 +1,26 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdownRenderer converts Markdown to HTML. It uses goldmark's safe
+// defaults: raw HTML blocks and inline tags in the source are omitted, and
+// links with dangerous schemes (javascript:, vbscript:, data: other than
+// images) are dropped, so the output is sanitized.
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+)
+
+// renderMarkdown converts a Markdown body into a sanitized HTML fragment.
+func renderMarkdown(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(source), &buf); err != nil {
+		return "", fmt.Errorf("rendering markdown: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -89,3 +89,16 @@ func ValidateSetForDraft(set *email.Set, draftsMailboxID jmap.ID) error {
 	}
 	return nil
 }
+
+// ValidateIsDraft checks that an email carries the $draft keyword before a
+// draft operation touches it. Editing replaces and archives the existing
+// message, so anything that is not a draft must be refused.
+func ValidateIsDraft(e *email.Email) error {
+	if !e.Keywords["$draft"] {
+		return &ErrForbidden{
+			Operation: "draft edit",
+			Reason:    fmt.Sprintf("email %s does not have the $draft keyword; only drafts can be edited", e.ID),
+		}
+	}
+	return nil
+}
@@ -1,11 +1,16 @@
 package client
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
 
 	"git.sr.ht/~rockorager/go-jmap"
 	"git.sr.ht/~rockorager/go-jmap/mail/email"
 	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+
+	"github.com/cboone/fm/internal/types"
 )
 
 func TestValidateTargetMailbox_AllowedMailboxes(t *testing.T) {
@@ -215,3 +220,95 @@ func TestValidateSetForDraft_RejectMissingDraftKeyword(t *testing.T) {
 		t.Fatal("expected error for missing $draft keyword")
 	}
 }
+
+func TestValidateIsDraft(t *testing.T) {
+	if err := ValidateIsDraft(&email.Email{ID: "M1", Keywords: map[string]bool{"$draft": true}}); err != nil {
+		t.Errorf("expected draft to pass, got %v", err)
+	}
+
+	for _, kw := range []map[string]bool{nil, {"$seen": true}, {"$draft": false}} {
+		err := ValidateIsDraft(&email.Email{ID: "M2", Keywords: kw})
+		if err == nil {
+			t.Errorf("expected error for keywords %v", kw)
+			continue
+		}
+		if fe, ok := err.(*ErrForbidden); !ok || fe.Operation != "draft edit" {
+			t.Errorf("expected ErrForbidden with operation 'draft edit', got %v", err)
+		}
+	}
+}
+
+// --- read-only mode tests ---
+
+func TestReadOnly_RefusesMutatingCallsBeforeSending(t *testing.T) {
+	var sent int
+	c := sieveTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		sent++
+		return &jmap.Response{}, nil
+	})
+	c.uploadFunc = func(jmap.ID, io.Reader) (*jmap.UploadResponse, error) {
+		sent++
+		return &jmap.UploadResponse{}, nil
+	}
+	c.SetReadOnly(true)
+
+	for _, method := range []jmap.Method{
+		&email.Set{Account: "acct-1"},
+		&mailbox.Set{Account: "acct-1"},
+		&email.Query{Account: "acct-1"},
+	} {
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{Account: "acct-1"})
+		req.Invoke(method)
+		_, err := c.Do(req)
+
+		var forbidden *ErrForbidden
+		isSet := strings.HasSuffix(method.Name(), "/set")
+		if isSet && (!errors.As(err, &forbidden) || forbidden.Operation != method.Name()) {
+			t.Errorf("%s: expected ErrForbidden, got %v", method.Name(), err)
+		}
+		if !isSet && err != nil {
+			t.Errorf("%s: expected reads to pass, got %v", method.Name(), err)
+		}
+	}
+
+	if _, err := c.UpdateSieveScript(types.SieveScriptDetail{ID: "S1", State: "s1"}, "keep;"); err == nil {
+		t.Error("expected sieve update to be refused")
+	} else if !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("unexpected sieve update error: %v", err)
+	}
+	if _, err := c.ActivateSieveScript("S1"); err == nil {
+		t.Error("expected sieve activate to be refused")
+	}
+
+	if sent != 1 {
+		t.Errorf("expected only the read request to be sent, got %d calls", sent)
+	}
+}
+
+func TestReadOnly_BatchEmailUpdatesFailWithoutSending(t *testing.T) {
+	c := &Client{accountID: "acct-1", doFunc: func(*jmap.Request) (*jmap.Response, error) {
+		t.Fatal("request sent in read-only mode")
+		return nil, nil
+	}}
+	c.SetReadOnly(true)
+
+	succeeded, failed := c.MarkAsRead([]string{"M1", "M2"})
+	if len(succeeded) != 0 || len(failed) != 2 {
+		t.Fatalf("expected both to fail, got succeeded=%v failed=%v", succeeded, failed)
+	}
+	if !strings.Contains(failed[0], "read-only mode is enabled") {
+		t.Errorf("unexpected error: %s", failed[0])
+	}
+}
+
+func TestReadOnly_SessionInfoReportsMode(t *testing.T) {
+	c := &Client{jmap: &jmap.Client{Session: &jmap.Session{Username: "u@example.com"}}}
+	if c.SessionInfo().ReadOnly {
+		t.Error("expected read_only false by default")
+	}
+	c.SetReadOnly(true)
+	if !c.SessionInfo().ReadOnly || !c.ReadOnly() {
+		t.Error("expected read_only true after SetReadOnly")
+	}
+}
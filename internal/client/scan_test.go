@@ -0,0 +1,92 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+// scanPage answers the Email/query and Email/get pair a scan sends with
+// emails as one complete page.
+func scanPage(emails []*email.Email) *jmap.Response {
+	ids := make([]jmap.ID, len(emails))
+	for i, e := range emails {
+		ids[i] = e.ID
+	}
+	return &jmap.Response{Responses: []*jmap.Invocation{
+		{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: uint64(len(emails)), IDs: ids}},
+		{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: emails}},
+	}}
+}
+
+// scanStub returns a doFunc that answers every scan with one page of the
+// emails pick chooses for its query. pick may also check the request.
+func scanStub(pick func(q *email.Query, get *email.Get) []*email.Email) func(*jmap.Request) (*jmap.Response, error) {
+	return func(req *jmap.Request) (*jmap.Response, error) {
+		return scanPage(pick(req.Calls[0].Args.(*email.Query), req.Calls[1].Args.(*email.Get))), nil
+	}
+}
+
+// pagedScanClient serves the first n of total emails in pages of the
+// requested size, recording the position of each Email/query.
+func pagedScanClient(total uint64, n int, positions *[]int64) *Client {
+	return &Client{
+		accountID: "acct-1",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			q := req.Calls[0].Args.(*email.Query)
+			*positions = append(*positions, q.Position)
+			var ids []jmap.ID
+			var list []*email.Email
+			for i := int(q.Position); i < n && i < int(q.Position)+int(q.Limit); i++ {
+				id := jmap.ID(fmt.Sprintf("M%d", i))
+				ids = append(ids, id)
+				list = append(list, &email.Email{ID: id})
+			}
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: total, Position: uint64(q.Position), IDs: ids}},
+				{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: list}},
+			}}, nil
+		},
+	}
+}
+
+func TestScanEmails_Pages(t *testing.T) {
+	var positions []int64
+	c := pagedScanClient(1200, 1200, &positions)
+
+	seen := make(map[jmap.ID]bool)
+	total, err := c.scanEmails("scan", nil, []string{"id"}, nil, func(e *email.Email) {
+		seen[e.ID] = true
+	})
+	if err != nil {
+		t.Fatalf("scanEmails() error: %v", err)
+	}
+	if total != 1200 || len(seen) != 1200 {
+		t.Errorf("total = %d, seen = %d, want 1200 each", total, len(seen))
+	}
+	if want := []int64{0, analyticsPageSize, 2 * analyticsPageSize}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("positions = %v, want %v", positions, want)
+	}
+}
+
+func TestScanEmails_StopsOnShortResults(t *testing.T) {
+	// The server reports more emails than it returns, as when mail is
+	// deleted during the scan: the scan stops at the first empty page.
+	var positions []int64
+	c := pagedScanClient(1200, 600, &positions)
+
+	count := 0
+	total, err := c.scanEmailsSorted("scan", nil, nil, []string{"id"}, nil, func(*email.Email) { count++ })
+	if err != nil {
+		t.Fatalf("scanEmailsSorted() error: %v", err)
+	}
+	if total != 1200 || count != 600 {
+		t.Errorf("total = %d, count = %d, want 1200 and 600", total, count)
+	}
+	if want := []int64{0, analyticsPageSize, 600}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("positions = %v, want %v", positions, want)
+	}
+}
@@ -101,6 +101,7 @@ func (c *Client) GetSieveScript(id string) (types.SieveScriptDetail, error) {
 				Name:     s.Name,
 				BlobID:   string(s.BlobID),
 				IsActive: s.IsActive,
+				State:    r.State,
 				Content:  content,
 			}, nil
 		case *jmap.MethodError:
@@ -186,6 +187,68 @@ func (c *Client) CreateSieveScript(name, content string, activate bool) (types.S
 	return types.SieveCreateResult{}, fmt.Errorf("creating sieve script: unexpected response")
 }
 
+// UpdateSieveScript replaces the content of an existing script with a newly
+// uploaded blob. The update is conditional on the SieveScript state recorded
+// in current (ifInState), so a script changed elsewhere since it was read is
+// not overwritten; that case returns ErrStateMismatch.
+func (c *Client) UpdateSieveScript(current types.SieveScriptDetail, content string) (types.SieveUpdateResult, error) {
+	if err := c.requireSieve(); err != nil {
+		return types.SieveUpdateResult{}, err
+	}
+
+	upload, err := c.Upload(c.accountID, strings.NewReader(content))
+	if err != nil {
+		return types.SieveUpdateResult{}, fmt.Errorf("uploading sieve script: %w", err)
+	}
+
+	scriptID := jmap.ID(current.ID)
+	req := &jmap.Request{}
+	req.Invoke(&sieve.Set{
+		Account:   c.accountID,
+		IfInState: current.State,
+		Update: map[jmap.ID]jmap.Patch{
+			scriptID: {"blobId": upload.ID},
+		},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return types.SieveUpdateResult{}, fmt.Errorf("updating sieve script: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *sieve.SetResponse:
+			if _, ok := r.Updated[scriptID]; ok {
+				return types.SieveUpdateResult{
+					ID:       current.ID,
+					Name:     current.Name,
+					BlobID:   string(upload.ID),
+					IsActive: current.IsActive,
+					Content:  content,
+				}, nil
+			}
+			if setErr, ok := r.NotUpdated[scriptID]; ok {
+				desc := setErr.Type
+				if setErr.Description != nil {
+					desc = *setErr.Description
+				}
+				if setErr.Type == "notFound" {
+					return types.SieveUpdateResult{}, fmt.Errorf("sieve script %s: %w", current.ID, ErrNotFound)
+				}
+				return types.SieveUpdateResult{}, fmt.Errorf("updating sieve script: %s", desc)
+			}
+		case *jmap.MethodError:
+			if r.Type == "stateMismatch" {
+				return types.SieveUpdateResult{}, fmt.Errorf("updating sieve script %s: script changed on the server since it was read: %w", current.ID, ErrStateMismatch)
+			}
+			return types.SieveUpdateResult{}, fmt.Errorf("updating sieve script: %s", r.Error())
+		}
+	}
+
+	return types.SieveUpdateResult{}, fmt.Errorf("updating sieve script: unexpected response")
+}
+
 // ValidateSieveScript uploads content as a blob and validates it server-side.
 func (c *Client) ValidateSieveScript(content string) (types.SieveValidateResult, error) {
 	if err := c.requireSieve(); err != nil {
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/cboone/fm/internal/sieve"
+	"github.com/cboone/fm/internal/types"
+)
+
+// SieveRestoreEntry is one backed-up script to restore: its name, the file
+// it was read from, its content, and whether it was active at backup time.
+type SieveRestoreEntry struct {
+	Name     string
+	File     string
+	Content  string
+	IsActive bool
+}
+
+// RestoreSieveScripts restores backed-up scripts, matching them to the
+// server by name. Missing scripts are created, scripts whose content differs
+// are updated under the state they were read in, and identical scripts are
+// left alone. A script that was active at backup time is activated again if
+// it is not active now. Scripts on the server that are not in entries are
+// never touched.
+//
+// With dryRun set nothing is changed, and each update carries a diff from
+// the server's content. Failures for single scripts are collected in the
+// result's Errors; only a failure to list the server's scripts is returned.
+func (c *Client) RestoreSieveScripts(entries []SieveRestoreEntry, dryRun bool) (types.SieveRestoreResult, error) {
+	list, err := c.ListSieveScripts()
+	if err != nil {
+		return types.SieveRestoreResult{}, err
+	}
+	onServer := make(map[string]types.SieveScriptInfo, len(list.Scripts))
+	for _, s := range list.Scripts {
+		onServer[s.Name] = s
+	}
+
+	result := types.SieveRestoreResult{
+		DryRun:  dryRun,
+		Scripts: []types.SieveRestoreScript{},
+		Errors:  []string{},
+	}
+
+	for _, entry := range entries {
+		item := types.SieveRestoreScript{Name: entry.Name, File: entry.File}
+
+		existing, found := onServer[entry.Name]
+		if !found {
+			item.Action = "create"
+			if !dryRun {
+				created, err := c.CreateSieveScript(entry.Name, entry.Content, entry.IsActive)
+				if err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry.Name, err))
+					continue
+				}
+				item.ID = created.ID
+			}
+			if entry.IsActive {
+				result.Activated = entry.Name
+			}
+			result.Created++
+			result.Scripts = append(result.Scripts, item)
+			continue
+		}
+
+		item.ID = existing.ID
+		current, err := c.GetSieveScript(existing.ID)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry.Name, err))
+			continue
+		}
+
+		if current.Content == entry.Content {
+			item.Action = "unchanged"
+			result.Unchanged++
+		} else {
+			item.Action = "update"
+			if dryRun {
+				item.Diff = sieve.UnifiedDiff(current.Content, entry.Content, "server/"+entry.Name, "backup/"+entry.File)
+			} else if _, err := c.UpdateSieveScript(current, entry.Content); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", entry.Name, err))
+				continue
+			}
+			result.Updated++
+		}
+
+		if entry.IsActive && !existing.IsActive {
+			if !dryRun {
+				if _, err := c.ActivateSieveScript(existing.ID); err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("%s: activating: %v", entry.Name, err))
+				}
+			}
+			result.Activated = entry.Name
+		}
+		result.Scripts = append(result.Scripts, item)
+	}
+
+	return result, nil
+}
+
+// DiffSieveScript compares the script stored on the server with content,
+// read from file, as a unified diff from the server's version.
+func (c *Client) DiffSieveScript(id, content, file string) (types.SieveDiffResult, error) {
+	current, err := c.GetSieveScript(id)
+	if err != nil {
+		return types.SieveDiffResult{}, err
+	}
+	diff := sieve.UnifiedDiff(current.Content, content, "server/"+id, file)
+	return types.SieveDiffResult{
+		Script:    id,
+		File:      file,
+		Identical: diff == "",
+		Diff:      diff,
+	}, nil
+}
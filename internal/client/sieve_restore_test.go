@@ -0,0 +1,153 @@
+package client
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+
+	"github.com/cboone/fm/internal/jmap/sieve"
+)
+
+// sieveRestoreClient serves the given scripts (their content keyed by blob
+// ID) and records every SieveScript/set it receives.
+func sieveRestoreClient(scripts []*sieve.SieveScript, blobs map[jmap.ID]string, sets *[]*sieve.Set) *Client {
+	c := sieveTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		switch args := req.Calls[0].Args.(type) {
+		case *sieve.Get:
+			list := scripts
+			if len(args.IDs) > 0 {
+				list = nil
+				for _, s := range scripts {
+					if s.ID == args.IDs[0] {
+						list = append(list, s)
+					}
+				}
+			}
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "SieveScript/get", Args: &sieve.GetResponse{State: "st-1", List: list}},
+			}}, nil
+		case *sieve.Set:
+			*sets = append(*sets, args)
+			resp := &sieve.SetResponse{
+				Created: map[jmap.ID]*sieve.SieveScript{},
+				Updated: map[jmap.ID]*sieve.SieveScript{},
+			}
+			for id := range args.Create {
+				resp.Created[id] = &sieve.SieveScript{ID: "S-new"}
+			}
+			for id := range args.Update {
+				resp.Updated[id] = nil
+			}
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "SieveScript/set", Args: resp},
+			}}, nil
+		}
+		return nil, io.ErrUnexpectedEOF
+	})
+	c.downloadFunc = func(_, blobID jmap.ID) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(blobs[blobID])), nil
+	}
+	c.uploadFunc = func(jmap.ID, io.Reader) (*jmap.UploadResponse, error) {
+		return &jmap.UploadResponse{ID: "B-upload"}, nil
+	}
+	return c
+}
+
+func TestRestoreSieveScripts(t *testing.T) {
+	scripts := []*sieve.SieveScript{
+		{ID: "S1", Name: "Filters", BlobID: "B1", IsActive: true},
+		{ID: "S2", Name: "Vacation", BlobID: "B2"},
+		{ID: "S3", Name: "Extra", BlobID: "B3"},
+	}
+	blobs := map[jmap.ID]string{"B1": "keep;\n", "B2": "stop;\n", "B3": "discard;\n"}
+	entries := []SieveRestoreEntry{
+		{Name: "Filters", File: "Filters.sieve", Content: "keep;\n"},
+		{Name: "Vacation", File: "Vacation.sieve", Content: "keep;\nstop;\n", IsActive: true},
+		{Name: "Spam", File: "Spam.sieve", Content: "discard;\n"},
+	}
+
+	var sets []*sieve.Set
+	c := sieveRestoreClient(scripts, blobs, &sets)
+	result, err := c.RestoreSieveScripts(entries, false)
+	if err != nil {
+		t.Fatalf("RestoreSieveScripts() error: %v", err)
+	}
+	if result.Created != 1 || result.Updated != 1 || result.Unchanged != 1 || len(result.Errors) != 0 {
+		t.Fatalf("unexpected counts: %+v", result)
+	}
+	if result.Activated != "Vacation" {
+		t.Errorf("Activated = %q, want Vacation", result.Activated)
+	}
+	var actions []string
+	for _, s := range result.Scripts {
+		actions = append(actions, s.Name+":"+s.Action+":"+s.ID)
+	}
+	if got := strings.Join(actions, ","); got != "Filters:unchanged:S1,Vacation:update:S2,Spam:create:S-new" {
+		t.Errorf("scripts = %s", got)
+	}
+
+	if len(sets) != 3 {
+		t.Fatalf("expected update, activate, and create sets, got %d", len(sets))
+	}
+	update, activate, create := sets[0], sets[1], sets[2]
+	if update.IfInState != "st-1" || update.Update["S2"]["blobId"] != jmap.ID("B-upload") {
+		t.Errorf("expected a state-checked update of S2, got %+v", update)
+	}
+	if activate.OnSuccessActivateScript == nil || *activate.OnSuccessActivateScript != "S2" {
+		t.Errorf("expected S2 activated, got %+v", activate)
+	}
+	if len(create.Create) != 1 || create.OnSuccessActivateScript != nil {
+		t.Errorf("expected an inactive create, got %+v", create)
+	}
+	for _, s := range sets {
+		if len(s.Destroy) != 0 || s.Update["S3"] != nil {
+			t.Errorf("expected scripts missing from the backup untouched, got %+v", s)
+		}
+	}
+}
+
+func TestRestoreSieveScripts_CreateActive(t *testing.T) {
+	var sets []*sieve.Set
+	c := sieveRestoreClient(nil, nil, &sets)
+	result, err := c.RestoreSieveScripts([]SieveRestoreEntry{
+		{Name: "Filters", File: "Filters.sieve", Content: "keep;\n", IsActive: true},
+	}, false)
+	if err != nil {
+		t.Fatalf("RestoreSieveScripts() error: %v", err)
+	}
+	if result.Created != 1 || result.Activated != "Filters" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(sets) != 1 || sets[0].OnSuccessActivateScript == nil || *sets[0].OnSuccessActivateScript != "#create0" {
+		t.Errorf("expected the new script activated on creation, got %+v", sets)
+	}
+}
+
+func TestRestoreSieveScripts_DryRun(t *testing.T) {
+	scripts := []*sieve.SieveScript{{ID: "S1", Name: "Filters", BlobID: "B1"}}
+	blobs := map[jmap.ID]string{"B1": "keep;\n"}
+
+	var sets []*sieve.Set
+	c := sieveRestoreClient(scripts, blobs, &sets)
+	result, err := c.RestoreSieveScripts([]SieveRestoreEntry{
+		{Name: "Filters", File: "Filters.sieve", Content: "discard;\n", IsActive: true},
+		{Name: "Spam", File: "Spam.sieve", Content: "discard;\n"},
+	}, true)
+	if err != nil {
+		t.Fatalf("RestoreSieveScripts() error: %v", err)
+	}
+	if len(sets) != 0 {
+		t.Errorf("expected no SieveScript/set in a dry run, got %d", len(sets))
+	}
+	if !result.DryRun || result.Updated != 1 || result.Created != 1 || result.Activated != "Filters" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	diff := result.Scripts[0].Diff
+	for _, want := range []string{"--- server/Filters", "+++ backup/Filters.sieve", "-keep;", "+discard;"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("expected %q in diff, got:\n%s", want, diff)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/sieve"
+	"github.com/cboone/fm/internal/types"
+)
+
+// SieveTestOptions holds parameters for simulating a script against
+// existing mail.
+type SieveTestOptions struct {
+	MailboxNameOrID string
+	Limit           uint64
+}
+
+// sieveTestProperties are the Email/get properties needed to evaluate a
+// script from headers alone.
+var sieveTestProperties = []string{
+	"id", "from", "subject", "receivedAt", "size", "headers",
+}
+
+// sieveTestPageSize caps the number of emails fetched per request.
+const sieveTestPageSize = 500
+
+// sieveKeepDestination is the destination reported for keep actions: the
+// message stays where sieve delivers it.
+const sieveKeepDestination = "INBOX"
+
+// SimulateSieveScript evaluates a parsed script against the most recent
+// emails in a mailbox without changing anything. Only headers and size are
+// available, so tests needing the body or envelope count as false and are
+// listed in Unsupported. The emails' current keywords are not used: new mail
+// arrives without flags.
+func (c *Client) SimulateSieveScript(script *sieve.Script, opts SieveTestOptions) (types.SieveTestResult, error) {
+	if opts.Limit == 0 {
+		opts.Limit = 500
+	}
+
+	mailboxID, err := c.ResolveMailboxID(opts.MailboxNameOrID)
+	if err != nil {
+		return types.SieveTestResult{}, err
+	}
+	mb, err := c.GetMailboxByNameOrID(string(mailboxID))
+	if err != nil {
+		return types.SieveTestResult{}, err
+	}
+
+	result := types.SieveTestResult{
+		Mailbox:  mb.Name,
+		Summary:  []types.SieveTestSummary{},
+		Messages: []types.SieveTestMessage{},
+	}
+	counts := make(map[types.SieveTestSummary]int)
+	unsupported := make(map[string]bool)
+
+	var position int64
+	for uint64(position) < opts.Limit {
+		pageSize := opts.Limit - uint64(position)
+		if pageSize > sieveTestPageSize {
+			pageSize = sieveTestPageSize
+		}
+
+		req := &jmap.Request{}
+		queryCallID := req.Invoke(&email.Query{
+			Account:  c.accountID,
+			Filter:   &email.FilterCondition{InMailbox: mb.ID},
+			Sort:     []*email.SortComparator{{Property: "receivedAt", IsAscending: false}},
+			Position: position,
+			Limit:    pageSize,
+		})
+		req.Invoke(&email.Get{
+			Account:    c.accountID,
+			Properties: sieveTestProperties,
+			ReferenceIDs: &jmap.ResultReference{
+				ResultOf: queryCallID,
+				Name:     "Email/query",
+				Path:     "/ids",
+			},
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return types.SieveTestResult{}, fmt.Errorf("sieve test query: %w", err)
+		}
+
+		var pageIDs []jmap.ID
+		var emails []*email.Email
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.QueryResponse:
+				pageIDs = r.IDs
+			case *email.GetResponse:
+				emails = r.List
+			case *jmap.MethodError:
+				return types.SieveTestResult{}, fmt.Errorf("sieve test query: %s", r.Error())
+			}
+		}
+
+		for _, e := range emails {
+			outcome := sieve.Evaluate(script, sieveMessage(e))
+			msg := types.SieveTestMessage{
+				ID:         string(e.ID),
+				From:       convertAddresses(e.From),
+				Subject:    e.Subject,
+				ReceivedAt: safeTime(e.ReceivedAt),
+			}
+			for _, pos := range outcome.Rules {
+				msg.Rules = append(msg.Rules, pos.Line)
+			}
+			if len(msg.Rules) > 0 {
+				result.Matched++
+			}
+
+			seen := make(map[types.SieveTestSummary]bool)
+			for _, a := range outcome.Actions {
+				action := types.SieveTestAction{
+					Action:   a.Name,
+					Target:   a.Target,
+					Copy:     a.Copy,
+					Flags:    a.Flags,
+					Implicit: a.Implicit,
+				}
+				if a.Name == "keep" {
+					action.Target = sieveKeepDestination
+				}
+				msg.Actions = append(msg.Actions, action)
+
+				key := types.SieveTestSummary{Action: a.Name, Destination: action.Target}
+				if !seen[key] {
+					seen[key] = true
+					counts[key]++
+				}
+			}
+			for _, name := range outcome.Unsupported {
+				unsupported[name] = true
+			}
+
+			result.Messages = append(result.Messages, msg)
+		}
+
+		position += int64(len(pageIDs))
+		if uint64(len(pageIDs)) < pageSize {
+			break
+		}
+	}
+
+	result.Total = len(result.Messages)
+	for key, n := range counts {
+		key.Count = n
+		result.Summary = append(result.Summary, key)
+	}
+	sort.Slice(result.Summary, func(i, j int) bool {
+		a, b := result.Summary[i], result.Summary[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Action != b.Action {
+			return a.Action < b.Action
+		}
+		return a.Destination < b.Destination
+	})
+	for name := range unsupported {
+		result.Unsupported = append(result.Unsupported, name)
+	}
+	sort.Strings(result.Unsupported)
+
+	return result, nil
+}
+
+// sieveMessage converts an email's raw headers and size into the form the
+// sieve evaluator expects.
+func sieveMessage(e *email.Email) sieve.Message {
+	msg := sieve.Message{Size: e.Size}
+	for _, h := range e.Headers {
+		msg.Headers = append(msg.Headers, sieve.Header{
+			Name:  h.Name,
+			Value: sieve.DecodeHeaderValue(h.Value),
+		})
+	}
+	return msg
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+
+	"github.com/cboone/fm/internal/sieve"
+)
+
+func TestSimulateSieveScript(t *testing.T) {
+	var requestedProps []string
+	c := &Client{
+		accountID: "acct-1",
+		mailboxCache: []*mailbox.Mailbox{
+			{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox},
+		},
+		doFunc: scanStub(func(_ *email.Query, get *email.Get) []*email.Email {
+			requestedProps = get.Properties
+			return []*email.Email{
+				{
+					ID:      "M1",
+					From:    []*mail.Address{{Email: "orders@shop.example"}},
+					Subject: "Receipt",
+					Headers: []*email.Header{
+						{Name: "From", Value: " Shop <orders@shop.example>"},
+						{Name: "Subject", Value: " =?UTF-8?Q?Receipt_=E2=84=961?="},
+					},
+				},
+				{
+					ID:      "M2",
+					Subject: "Team news",
+					Headers: []*email.Header{
+						{Name: "From", Value: " news@lists.example"},
+						{Name: "List-Id", Value: " <news.lists.example>"},
+					},
+					Keywords: map[string]bool{"$seen": true},
+				},
+				{
+					ID:      "M3",
+					Subject: "Hello",
+					Headers: []*email.Header{{Name: "From", Value: " friend@example.com"}},
+				},
+			}
+		}),
+	}
+
+	script, err := sieve.Parse("require [\"fileinto\", \"copy\"];\n" +
+		"if address :domain \"from\" \"shop.example\" {\n  fileinto \"Receipts\";\n  stop;\n}\n" +
+		"if header :contains \"subject\" \"receipt №1\" { discard; }\n" +
+		"if exists \"list-id\" {\n  fileinto :copy \"Lists\";\n}\n")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	result, err := c.SimulateSieveScript(script, SieveTestOptions{MailboxNameOrID: "inbox", Limit: 10})
+	if err != nil {
+		t.Fatalf("SimulateSieveScript() error: %v", err)
+	}
+
+	if !containsString(requestedProps, "headers") {
+		t.Errorf("expected headers to be requested, got %v", requestedProps)
+	}
+	if result.Mailbox != "Inbox" || result.Total != 3 || result.Matched != 2 {
+		t.Errorf("unexpected totals: mailbox=%q total=%d matched=%d", result.Mailbox, result.Total, result.Matched)
+	}
+
+	m1 := result.Messages[0]
+	if len(m1.Rules) != 1 || m1.Rules[0] != 2 {
+		t.Errorf("M1 rules = %v, want [2] (stop prevents later rules)", m1.Rules)
+	}
+	if len(m1.Actions) != 1 || m1.Actions[0].Action != "fileinto" || m1.Actions[0].Target != "Receipts" {
+		t.Errorf("M1 actions = %+v", m1.Actions)
+	}
+
+	m2 := result.Messages[1]
+	if len(m2.Actions) != 2 || !m2.Actions[0].Copy || !m2.Actions[1].Implicit || m2.Actions[1].Target != "INBOX" {
+		t.Errorf("M2 actions = %+v, want fileinto :copy then implicit keep", m2.Actions)
+	}
+
+	want := map[string]int{"keep INBOX": 2, "fileinto Lists": 1, "fileinto Receipts": 1}
+	if len(result.Summary) != len(want) {
+		t.Fatalf("summary = %+v", result.Summary)
+	}
+	for _, s := range result.Summary {
+		if want[s.Action+" "+s.Destination] != s.Count {
+			t.Errorf("summary %s %s = %d", s.Action, s.Destination, s.Count)
+		}
+	}
+	if result.Summary[0].Action != "keep" {
+		t.Errorf("expected summary sorted by count, got %+v", result.Summary)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
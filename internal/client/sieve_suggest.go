@@ -0,0 +1,247 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// SieveSuggestOptions holds parameters for proposing sieve rules.
+type SieveSuggestOptions struct {
+	Limit    uint64  // most recent messages to scan
+	MinCount int     // messages a sender must have in the target mailbox
+	MinShare float64 // fraction of the sender's messages in the target mailbox
+}
+
+// sieveSuggestProperties are the Email/get properties needed to see where
+// each sender's mail sits.
+var sieveSuggestProperties = []string{"id", "from", "mailboxIds"}
+
+// personalMailDomains are shared providers where a domain-wide rule would
+// catch unrelated people; only per-address rules are proposed for them.
+var personalMailDomains = map[string]bool{
+	"aol.com":        true,
+	"fastmail.com":   true,
+	"fastmail.fm":    true,
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"gmx.com":        true,
+	"gmx.de":         true,
+	"hotmail.com":    true,
+	"icloud.com":     true,
+	"live.com":       true,
+	"mac.com":        true,
+	"me.com":         true,
+	"outlook.com":    true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"yahoo.com":      true,
+}
+
+// SuggestSieveRules scans the most recent mail outside Trash, Sent, and
+// Drafts and proposes a rule for each sender domain (or, failing that,
+// sender address) whose mail sits almost entirely in one filing mailbox.
+// Filing mailboxes are those without a role, which become fileinto rules,
+// and Junk, which becomes a junk rule. The generated script is returned in
+// Content but not created.
+func (c *Client) SuggestSieveRules(opts SieveSuggestOptions) (types.SieveSuggestResult, error) {
+	if opts.Limit == 0 {
+		opts.Limit = 2000
+	}
+	if opts.MinCount <= 0 {
+		opts.MinCount = 5
+	}
+	if opts.MinShare <= 0 {
+		opts.MinShare = 0.9
+	}
+
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return types.SieveSuggestResult{}, err
+	}
+	byID := make(map[jmap.ID]*mailbox.Mailbox, len(mailboxes))
+	var excluded []jmap.ID
+	for _, mb := range mailboxes {
+		byID[mb.ID] = mb
+		switch mb.Role {
+		case mailbox.RoleTrash, mailbox.RoleSent, mailbox.RoleDrafts:
+			excluded = append(excluded, mb.ID)
+		}
+	}
+
+	type senderAcc struct {
+		total     int
+		mailboxes map[jmap.ID]int
+	}
+	addresses := make(map[string]*senderAcc)
+	domains := make(map[string]*senderAcc)
+	add := func(m map[string]*senderAcc, key string, ids map[jmap.ID]bool) {
+		acc, ok := m[key]
+		if !ok {
+			acc = &senderAcc{mailboxes: make(map[jmap.ID]int)}
+			m[key] = acc
+		}
+		acc.total++
+		for id := range ids {
+			acc.mailboxes[id]++
+		}
+	}
+
+	var filter email.Filter
+	if len(excluded) > 0 {
+		filter = &email.FilterCondition{InMailboxOtherThan: excluded}
+	}
+
+	var result types.SieveSuggestResult
+	var position int64
+	for uint64(position) < opts.Limit {
+		pageSize := opts.Limit - uint64(position)
+		if pageSize > 500 {
+			pageSize = 500
+		}
+
+		req := &jmap.Request{}
+		queryCallID := req.Invoke(&email.Query{
+			Account:  c.accountID,
+			Filter:   filter,
+			Sort:     []*email.SortComparator{{Property: "receivedAt", IsAscending: false}},
+			Position: position,
+			Limit:    pageSize,
+		})
+		req.Invoke(&email.Get{
+			Account:    c.accountID,
+			Properties: sieveSuggestProperties,
+			ReferenceIDs: &jmap.ResultReference{
+				ResultOf: queryCallID,
+				Name:     "Email/query",
+				Path:     "/ids",
+			},
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return types.SieveSuggestResult{}, fmt.Errorf("sieve suggest query: %w", err)
+		}
+
+		var pageIDs []jmap.ID
+		var emails []*email.Email
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.QueryResponse:
+				pageIDs = r.IDs
+			case *email.GetResponse:
+				emails = r.List
+			case *jmap.MethodError:
+				return types.SieveSuggestResult{}, fmt.Errorf("sieve suggest query: %s", r.Error())
+			}
+		}
+
+		for _, e := range emails {
+			result.Scanned++
+			if len(e.From) == 0 || e.From[0].Email == "" {
+				continue
+			}
+			addr := strings.ToLower(e.From[0].Email)
+			add(addresses, addr, e.MailboxIDs)
+			if domain := extractDomain(addr); domain != "" {
+				add(domains, domain, e.MailboxIDs)
+			}
+		}
+
+		position += int64(len(pageIDs))
+		if len(pageIDs) == 0 || uint64(len(pageIDs)) < pageSize {
+			break
+		}
+	}
+
+	// suggest returns the rule for a sender if one filing mailbox holds
+	// enough of its mail.
+	suggest := func(acc *senderAcc) (types.SieveSuggestion, bool) {
+		var best *mailbox.Mailbox
+		bestCount, bestPath := 0, ""
+		for id, n := range acc.mailboxes {
+			mb := byID[id]
+			if mb == nil || (mb.Role != "" && mb.Role != mailbox.RoleJunk) {
+				continue
+			}
+			path := mailboxPath(mb, byID)
+			if n > bestCount || (n == bestCount && best != nil && path < bestPath) {
+				best, bestCount, bestPath = mb, n, path
+			}
+		}
+		if best == nil || bestCount < opts.MinCount {
+			return types.SieveSuggestion{}, false
+		}
+		share := float64(bestCount) / float64(acc.total)
+		if share < opts.MinShare {
+			return types.SieveSuggestion{}, false
+		}
+		s := types.SieveSuggestion{
+			Action:     "fileinto",
+			Mailbox:    bestPath,
+			Count:      bestCount,
+			Total:      acc.total,
+			Confidence: math.Round(share*100) / 100,
+		}
+		if best.Role == mailbox.RoleJunk {
+			s.Action = "junk"
+			s.Mailbox = ""
+		}
+		return s, true
+	}
+
+	suggestions := []types.SieveSuggestion{}
+	covered := make(map[string]bool)
+	for domain, acc := range domains {
+		if personalMailDomains[domain] {
+			continue
+		}
+		if s, ok := suggest(acc); ok {
+			s.FromDomain = domain
+			suggestions = append(suggestions, s)
+			covered[domain] = true
+		}
+	}
+	for addr, acc := range addresses {
+		if covered[extractDomain(addr)] {
+			continue
+		}
+		if s, ok := suggest(acc); ok {
+			s.From = addr
+			suggestions = append(suggestions, s)
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return suggestions[i].FromDomain+suggestions[i].From < suggestions[j].FromDomain+suggestions[j].From
+	})
+	result.Suggestions = suggestions
+
+	if len(suggestions) > 0 {
+		rules := make([]SieveTemplateOptions, len(suggestions))
+		for i, s := range suggestions {
+			rules[i] = SieveTemplateOptions{
+				From:       s.From,
+				FromDomain: s.FromDomain,
+				Action:     s.Action,
+				FileInto:   s.Mailbox,
+			}
+		}
+		content, err := GenerateSieveRules(rules)
+		if err != nil {
+			return types.SieveSuggestResult{}, err
+		}
+		result.Content = content
+	}
+
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+)
+
+func TestSuggestSieveRules(t *testing.T) {
+	var emails []*email.Email
+	addEmails := func(n int, from string, mailboxIDs ...jmap.ID) {
+		for i := 0; i < n; i++ {
+			ids := make(map[jmap.ID]bool)
+			for _, id := range mailboxIDs {
+				ids[id] = true
+			}
+			emails = append(emails, &email.Email{
+				ID:         jmap.ID(fmt.Sprintf("M%d", len(emails)+1)),
+				From:       []*mail.Address{{Email: from}},
+				MailboxIDs: ids,
+			})
+		}
+	}
+	// Every shop.example message is in Finance/Receipts, from two addresses.
+	addEmails(4, "orders@shop.example", "mb-receipts")
+	addEmails(2, "Billing@Shop.example", "mb-receipts")
+	// A personal provider: only the address is suggested.
+	addEmails(5, "pal@gmail.com", "mb-friends")
+	addEmails(3, "other@gmail.com", "mb-friends")
+	// Spread across mailboxes: no suggestion.
+	addEmails(3, "mixed@news.example", "mb-lists")
+	addEmails(3, "mixed@news.example", "mb-inbox")
+	// Junk becomes a junk rule.
+	addEmails(5, "spam@bad.example", "mb-junk")
+	// Everything in the inbox: not a filing target.
+	addEmails(9, "boss@work.example", "mb-inbox")
+
+	var gotFilter email.Filter
+	c := &Client{
+		accountID: "acct-1",
+		mailboxCache: []*mailbox.Mailbox{
+			{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox},
+			{ID: "mb-junk", Name: "Junk Mail", Role: mailbox.RoleJunk},
+			{ID: "mb-trash", Name: "Trash", Role: mailbox.RoleTrash},
+			{ID: "mb-finance", Name: "Finance"},
+			{ID: "mb-receipts", Name: "Receipts", ParentID: "mb-finance"},
+			{ID: "mb-friends", Name: "Friends"},
+			{ID: "mb-lists", Name: "Lists"},
+		},
+		doFunc: scanStub(func(q *email.Query, _ *email.Get) []*email.Email {
+			gotFilter = q.Filter
+			return emails
+		}),
+	}
+
+	result, err := c.SuggestSieveRules(SieveSuggestOptions{})
+	if err != nil {
+		t.Fatalf("SuggestSieveRules() error: %v", err)
+	}
+
+	fc, ok := gotFilter.(*email.FilterCondition)
+	if !ok || len(fc.InMailboxOtherThan) != 1 || fc.InMailboxOtherThan[0] != "mb-trash" {
+		t.Errorf("filter = %+v, want Trash excluded", gotFilter)
+	}
+	if result.Scanned != len(emails) {
+		t.Errorf("Scanned = %d, want %d", result.Scanned, len(emails))
+	}
+
+	var got []string
+	for _, s := range result.Suggestions {
+		got = append(got, fmt.Sprintf("%s%s %s %s %d/%d %.2f", s.FromDomain, s.From, s.Action, s.Mailbox, s.Count, s.Total, s.Confidence))
+	}
+	want := []string{
+		"shop.example fileinto Finance/Receipts 6/6 1.00",
+		"bad.example junk  5/5 1.00",
+		"pal@gmail.com fileinto Friends 5/5 1.00",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("suggestions =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if !strings.HasPrefix(result.Content, "require [\"fileinto\"];\n") {
+		t.Errorf("content should start with a single require line, got:\n%s", result.Content)
+	}
+	for _, rule := range []string{
+		`if address :domain :is "from" "shop.example" {`,
+		`fileinto "Finance/Receipts";`,
+		`fileinto "Junk";`,
+		`if address :is "from" "pal@gmail.com" {`,
+	} {
+		if !strings.Contains(result.Content, rule) {
+			t.Errorf("content missing %q:\n%s", rule, result.Content)
+		}
+	}
+}
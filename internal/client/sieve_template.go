@@ -2,46 +2,212 @@ package client
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+
+	"github.com/cboone/fm/internal/sieve"
 )
 
 // SieveTemplateOptions configures sieve script generation from CLI flags.
 type SieveTemplateOptions struct {
-	From       string // exact sender address match
-	FromDomain string // sender domain match
-	Action     string // junk, discard, keep, or fileinto
-	FileInto   string // target mailbox name (required when Action is "fileinto")
+	// Conditions. Every populated field adds one test per value; the tests
+	// are combined with allof or anyof according to Match.
+	From            string   // exact sender address match
+	FromDomain      string   // sender domain match
+	SubjectContains []string // subject substrings
+	Headers         []string // "Name: pattern" pairs matched with :matches wildcards
+	To              []string // exact To address matches
+	Cc              []string // exact Cc address matches
+	SizeOver        string   // message size threshold such as "500K" or "2M"
+	ListID          string   // List-Id substring
+	Match           string   // all (default) or any
+
+	// Actions.
+	Action   string   // junk, discard, keep, or fileinto
+	FileInto string   // target mailbox name (required when Action is "fileinto")
+	Copy     bool     // file a copy with :copy, leaving the implicit keep in place
+	AddFlags []string // IMAP flags or keywords added with addflag
+	MarkRead bool     // set \Seen with setflag
 }
 
+// sieveSizePattern matches RFC 5228 size quantities.
+var sieveSizePattern = regexp.MustCompile(`^[0-9]+[KkMmGg]?$`)
+
 // GenerateSieveScript produces a complete sieve script from template options.
 func GenerateSieveScript(opts SieveTemplateOptions) (string, error) {
-	if err := validateTemplateOptions(opts); err != nil {
+	rule, extensions, err := GenerateSieveRule(opts)
+	if err != nil {
 		return "", err
 	}
 
-	var b strings.Builder
+	if len(extensions) == 0 {
+		return rule, nil
+	}
+	return requireLine(extensions) + "\n" + rule, nil
+}
 
-	action, needsFileinto := sieveAction(opts)
-	if needsFileinto {
-		b.WriteString("require [\"fileinto\"];\n\n")
+// GenerateSieveRules produces one script holding a rule per set of options,
+// in order, with a single require line covering every rule.
+func GenerateSieveRules(rules []SieveTemplateOptions) (string, error) {
+	var extensions []string
+	seen := map[string]bool{}
+	bodies := make([]string, 0, len(rules))
+	for i, opts := range rules {
+		rule, exts, err := GenerateSieveRule(opts)
+		if err != nil {
+			return "", fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		bodies = append(bodies, rule)
+		for _, ext := range exts {
+			if !seen[ext] {
+				seen[ext] = true
+				extensions = append(extensions, ext)
+			}
+		}
+	}
+
+	script := strings.Join(bodies, "\n")
+	if len(extensions) == 0 {
+		return script, nil
+	}
+	return requireLine(extensions) + "\n" + script, nil
+}
+
+// GenerateSieveRule produces a single if-rule from template options, without
+// a require line, along with the extensions the rule needs.
+//
+// A rule that files, keeps, or discards the message ends with stop. One
+// that only sets flags, or files a copy, lets later rules run.
+func GenerateSieveRule(opts SieveTemplateOptions) (rule string, extensions []string, err error) {
+	if err := validateTemplateOptions(opts); err != nil {
+		return "", nil, err
 	}
 
-	condition := sieveCondition(opts)
-	b.WriteString(fmt.Sprintf("if %s {\n", condition))
-	b.WriteString(fmt.Sprintf("    %s\n", action))
-	b.WriteString("    stop;\n")
+	var b strings.Builder
+
+	actions, extensions := sieveActions(opts)
+	b.WriteString(fmt.Sprintf("if %s {\n", sieveCondition(opts)))
+	for _, action := range actions {
+		b.WriteString(fmt.Sprintf("    %s\n", action))
+	}
+	if opts.Action != "" && !opts.Copy {
+		b.WriteString("    stop;\n")
+	}
 	b.WriteString("}\n")
 
-	return b.String(), nil
+	return b.String(), extensions, nil
 }
 
-func validateTemplateOptions(opts SieveTemplateOptions) error {
-	if opts.From == "" && opts.FromDomain == "" {
-		return fmt.Errorf("either --from or --from-domain is required")
+// AppendSieveRule appends a generated rule to an existing script. Extensions
+// the rule needs that the script does not already require are added in a new
+// require line after the existing ones (or at the top if there are none).
+//
+// A rule after a top-level stop would never run, so when the script has one
+// the rule is inserted just before it instead. A stop that shares its line
+// with other commands is refused rather than rewritten.
+func AppendSieveRule(content string, opts SieveTemplateOptions) (string, error) {
+	rule, extensions, err := GenerateSieveRule(opts)
+	if err != nil {
+		return "", err
 	}
+
+	script, err := sieve.Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("existing script does not parse: %w", err)
+	}
+
+	required := map[string]bool{}
+	var lastRequire, stop *sieve.Command
+	for _, cmd := range script.Commands {
+		if cmd.Name == "stop" && stop == nil {
+			stop = cmd
+		}
+		if cmd.Name != "require" {
+			continue
+		}
+		lastRequire = cmd
+		for _, arg := range cmd.Args {
+			for _, ext := range arg.Strings {
+				required[ext] = true
+			}
+		}
+	}
+
+	if stop != nil {
+		lines := strings.SplitAfter(content, "\n")
+		at := stop.Pos.Line - 1
+		if prefix := []rune(lines[at]); strings.TrimSpace(string(prefix[:stop.Pos.Column-1])) != "" {
+			return "", fmt.Errorf("%s: a rule added after stop would be unreachable, and stop shares its line with other commands; move stop to its own line first", stop.Pos)
+		}
+		lines = append(lines[:at], append([]string{rule + "\n"}, lines[at:]...)...)
+		content = strings.Join(lines, "")
+		rule = ""
+	}
+	var missing []string
+	for _, ext := range extensions {
+		if !required[ext] {
+			missing = append(missing, ext)
+		}
+	}
+
+	merged := content
+	if len(missing) > 0 {
+		lines := strings.SplitAfter(merged, "\n")
+		at := 0
+		if lastRequire != nil {
+			at = lastRequire.End.Line
+		}
+		line := requireLine(missing)
+		if at > 0 && at <= len(lines) && !strings.HasSuffix(lines[at-1], "\n") {
+			line = "\n" + line
+		}
+		lines = append(lines[:at], append([]string{line}, lines[at:]...)...)
+		merged = strings.Join(lines, "")
+	}
+
+	if rule == "" {
+		return merged, nil
+	}
+	if merged != "" && !strings.HasSuffix(merged, "\n") {
+		merged += "\n"
+	}
+	if strings.TrimSpace(merged) != "" {
+		merged += "\n"
+	}
+	return merged + rule, nil
+}
+
+// requireLine renders a require command for the given extensions.
+func requireLine(extensions []string) string {
+	quoted := make([]string, len(extensions))
+	for i, ext := range extensions {
+		quoted[i] = fmt.Sprintf("%q", ext)
+	}
+	return fmt.Sprintf("require [%s];\n", strings.Join(quoted, ", "))
+}
+
+func validateTemplateOptions(opts SieveTemplateOptions) error {
 	if opts.From != "" && opts.FromDomain != "" {
 		return fmt.Errorf("--from and --from-domain are mutually exclusive")
 	}
+	for _, h := range opts.Headers {
+		if _, _, err := splitHeaderCondition(h); err != nil {
+			return err
+		}
+	}
+	if len(sieveTests(opts)) == 0 {
+		return fmt.Errorf("at least one condition is required (--from, --from-domain, --subject-contains, --header, --to, --cc, --size-over, or --list-id)")
+	}
+	if opts.SizeOver != "" && !sieveSizePattern.MatchString(opts.SizeOver) {
+		return fmt.Errorf("invalid --size-over %q: use a number with an optional K, M, or G suffix", opts.SizeOver)
+	}
+	switch opts.Match {
+	case "", "all", "any":
+		// valid
+	default:
+		return fmt.Errorf("unsupported match %q: use all or any", opts.Match)
+	}
+
 	switch opts.Action {
 	case "junk", "discard", "keep":
 		// valid
@@ -50,31 +216,119 @@ func validateTemplateOptions(opts SieveTemplateOptions) error {
 			return fmt.Errorf("--fileinto is required when --action is fileinto")
 		}
 	case "":
-		return fmt.Errorf("--action is required")
+		if len(opts.AddFlags) == 0 && !opts.MarkRead {
+			return fmt.Errorf("--action is required")
+		}
 	default:
 		return fmt.Errorf("unsupported action %q: use junk, discard, keep, or fileinto", opts.Action)
 	}
+	if opts.Copy && opts.Action != "fileinto" && opts.Action != "junk" {
+		return fmt.Errorf("--copy requires --action fileinto or junk")
+	}
 	return nil
 }
 
-func sieveCondition(opts SieveTemplateOptions) string {
+// splitHeaderCondition parses a "Name: pattern" header condition.
+func splitHeaderCondition(s string) (name, pattern string, err error) {
+	name, pattern, ok := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid --header %q: use \"Name: pattern\"", s)
+	}
+	return name, strings.TrimSpace(pattern), nil
+}
+
+// sieveTests returns the individual sieve tests for the configured conditions,
+// in a stable order.
+func sieveTests(opts SieveTemplateOptions) []string {
+	var tests []string
 	if opts.From != "" {
-		return fmt.Sprintf("address :is \"from\" %q", opts.From)
+		tests = append(tests, fmt.Sprintf("address :is \"from\" %q", opts.From))
+	}
+	if opts.FromDomain != "" {
+		tests = append(tests, fmt.Sprintf("address :domain :is \"from\" %q", opts.FromDomain))
+	}
+	for _, addr := range opts.To {
+		tests = append(tests, fmt.Sprintf("address :is \"to\" %q", addr))
+	}
+	for _, addr := range opts.Cc {
+		tests = append(tests, fmt.Sprintf("address :is \"cc\" %q", addr))
+	}
+	for _, s := range opts.SubjectContains {
+		tests = append(tests, fmt.Sprintf("header :contains \"subject\" %q", s))
 	}
-	return fmt.Sprintf("address :domain :is \"from\" %q", opts.FromDomain)
+	if opts.ListID != "" {
+		tests = append(tests, fmt.Sprintf("header :contains \"list-id\" %q", opts.ListID))
+	}
+	for _, h := range opts.Headers {
+		name, pattern, err := splitHeaderCondition(h)
+		if err != nil {
+			continue
+		}
+		tests = append(tests, fmt.Sprintf("header :matches %q %q", name, pattern))
+	}
+	if opts.SizeOver != "" {
+		tests = append(tests, fmt.Sprintf("size :over %s", strings.ToUpper(opts.SizeOver)))
+	}
+	return tests
 }
 
-func sieveAction(opts SieveTemplateOptions) (action string, needsFileinto bool) {
+// sieveCondition renders the if-condition. A single test is used as is;
+// several are wrapped in allof or anyof, one per line.
+func sieveCondition(opts SieveTemplateOptions) string {
+	tests := sieveTests(opts)
+	if len(tests) == 1 {
+		return tests[0]
+	}
+
+	combinator := "allof"
+	if opts.Match == "any" {
+		combinator = "anyof"
+	}
+	return fmt.Sprintf("%s (\n    %s\n)", combinator, strings.Join(tests, ",\n    "))
+}
+
+// sieveActions renders the rule body and the extensions it needs, in the
+// order they must appear in the require line. Flags are set before filing so
+// the stored copy carries them.
+func sieveActions(opts SieveTemplateOptions) (actions []string, extensions []string) {
+	needsFileinto := false
+	needsFlags := false
+
+	if opts.MarkRead {
+		actions = append(actions, "setflag \"\\\\Seen\";")
+		needsFlags = true
+	}
+	for _, flag := range opts.AddFlags {
+		actions = append(actions, fmt.Sprintf("addflag %q;", flag))
+		needsFlags = true
+	}
+
+	copyTag := ""
+	if opts.Copy {
+		copyTag = ":copy "
+	}
 	switch opts.Action {
 	case "junk":
-		return "fileinto \"Junk\";", true
+		actions = append(actions, fmt.Sprintf("fileinto %s\"Junk\";", copyTag))
+		needsFileinto = true
 	case "discard":
-		return "discard;", false
+		actions = append(actions, "discard;")
 	case "keep":
-		return "keep;", false
+		actions = append(actions, "keep;")
 	case "fileinto":
-		return fmt.Sprintf("fileinto %q;", opts.FileInto), true
-	default:
-		return "keep;", false
+		actions = append(actions, fmt.Sprintf("fileinto %s%q;", copyTag, opts.FileInto))
+		needsFileinto = true
+	}
+
+	if needsFileinto {
+		extensions = append(extensions, "fileinto")
+	}
+	if opts.Copy {
+		extensions = append(extensions, "copy")
+	}
+	if needsFlags {
+		extensions = append(extensions, "imap4flags")
 	}
+	return actions, extensions
 }
@@ -1,6 +1,9 @@
 package client
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestGenerateSieveScript(t *testing.T) {
 	tests := []struct {
@@ -35,9 +38,69 @@ func TestGenerateSieveScript(t *testing.T) {
 			want: "require [\"fileinto\"];\n\nif address :domain :is \"from\" \"example.com\" {\n    fileinto \"Archive\";\n    stop;\n}\n",
 		},
 		{
-			name:    "missing from and from-domain",
+			name: "multiple conditions combined with allof",
+			opts: SieveTemplateOptions{
+				FromDomain:      "example.com",
+				SubjectContains: []string{"invoice"},
+				SizeOver:        "500k",
+				Action:          "fileinto",
+				FileInto:        "Receipts",
+			},
+			want: "require [\"fileinto\"];\n\nif allof (\n    address :domain :is \"from\" \"example.com\",\n    header :contains \"subject\" \"invoice\",\n    size :over 500K\n) {\n    fileinto \"Receipts\";\n    stop;\n}\n",
+		},
+		{
+			name: "anyof with to, cc, list-id, and header",
+			opts: SieveTemplateOptions{
+				To:      []string{"team@example.com"},
+				Cc:      []string{"team@example.com"},
+				ListID:  "dev.lists.example.com",
+				Headers: []string{"X-Priority: 1*"},
+				Match:   "any",
+				Action:  "keep",
+			},
+			want: "if anyof (\n    address :is \"to\" \"team@example.com\",\n    address :is \"cc\" \"team@example.com\",\n    header :contains \"list-id\" \"dev.lists.example.com\",\n    header :matches \"X-Priority\" \"1*\"\n) {\n    keep;\n    stop;\n}\n",
+		},
+		{
+			name: "mark read and addflag with fileinto copy",
+			opts: SieveTemplateOptions{
+				ListID:   "announce.example.com",
+				MarkRead: true,
+				AddFlags: []string{"$announce"},
+				Action:   "fileinto",
+				FileInto: "Lists",
+				Copy:     true,
+			},
+			want: "require [\"fileinto\", \"copy\", \"imap4flags\"];\n\nif header :contains \"list-id\" \"announce.example.com\" {\n    setflag \"\\\\Seen\";\n    addflag \"$announce\";\n    fileinto :copy \"Lists\";\n}\n",
+		},
+		{
+			name: "flags without a filing action",
+			opts: SieveTemplateOptions{From: "boss@example.com", AddFlags: []string{"\\Flagged"}},
+			want: "require [\"imap4flags\"];\n\nif address :is \"from\" \"boss@example.com\" {\n    addflag \"\\\\Flagged\";\n}\n",
+		},
+		{
+			name:    "copy with keep action",
+			opts:    SieveTemplateOptions{From: "a@b.com", Action: "keep", Copy: true},
+			wantErr: "--copy requires --action fileinto or junk",
+		},
+		{
+			name:    "invalid size",
+			opts:    SieveTemplateOptions{SizeOver: "1 MB", Action: "junk"},
+			wantErr: "invalid --size-over \"1 MB\": use a number with an optional K, M, or G suffix",
+		},
+		{
+			name:    "header without colon",
+			opts:    SieveTemplateOptions{Headers: []string{"X-Spam"}, Action: "junk"},
+			wantErr: "invalid --header \"X-Spam\": use \"Name: pattern\"",
+		},
+		{
+			name:    "unsupported match",
+			opts:    SieveTemplateOptions{From: "a@b.com", Match: "none", Action: "junk"},
+			wantErr: "unsupported match \"none\": use all or any",
+		},
+		{
+			name:    "missing conditions",
 			opts:    SieveTemplateOptions{Action: "junk"},
-			wantErr: "either --from or --from-domain is required",
+			wantErr: "at least one condition is required (--from, --from-domain, --subject-contains, --header, --to, --cc, --size-over, or --list-id)",
 		},
 		{
 			name:    "both from and from-domain",
@@ -84,3 +147,98 @@ func TestGenerateSieveScript(t *testing.T) {
 		})
 	}
 }
+
+func TestAppendSieveRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		opts    SieveTemplateOptions
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "adds missing extension after existing require",
+			content: "# filters\nrequire [\"fileinto\"];\n\nif address :is \"from\" \"a@example.com\" {\n    fileinto \"A\";\n    stop;\n}\n",
+			opts:    SieveTemplateOptions{From: "b@example.com", Action: "fileinto", FileInto: "B", MarkRead: true},
+			want: "# filters\nrequire [\"fileinto\"];\nrequire [\"imap4flags\"];\n\nif address :is \"from\" \"a@example.com\" {\n    fileinto \"A\";\n    stop;\n}\n" +
+				"\nif address :is \"from\" \"b@example.com\" {\n    setflag \"\\\\Seen\";\n    fileinto \"B\";\n    stop;\n}\n",
+		},
+		{
+			name:    "no require needed",
+			content: "if true {\n    keep;\n}",
+			opts:    SieveTemplateOptions{From: "c@example.com", Action: "discard"},
+			want:    "if true {\n    keep;\n}\n\nif address :is \"from\" \"c@example.com\" {\n    discard;\n    stop;\n}\n",
+		},
+		{
+			name:    "require inserted at top when script has none",
+			content: "keep;\n",
+			opts:    SieveTemplateOptions{From: "d@example.com", Action: "junk"},
+			want:    "require [\"fileinto\"];\nkeep;\n\nif address :is \"from\" \"d@example.com\" {\n    fileinto \"Junk\";\n    stop;\n}\n",
+		},
+		{
+			name:    "empty script",
+			content: "",
+			opts:    SieveTemplateOptions{From: "e@example.com", Action: "keep"},
+			want:    "if address :is \"from\" \"e@example.com\" {\n    keep;\n    stop;\n}\n",
+		},
+		{
+			name:    "inserted before a top-level stop",
+			content: "require \"fileinto\";\nif true {\n    fileinto \"A\";\n}\n\nstop;\n",
+			opts:    SieveTemplateOptions{From: "f@example.com", Action: "fileinto", FileInto: "F", MarkRead: true},
+			want: "require \"fileinto\";\nrequire [\"imap4flags\"];\nif true {\n    fileinto \"A\";\n}\n\n" +
+				"if address :is \"from\" \"f@example.com\" {\n    setflag \"\\\\Seen\";\n    fileinto \"F\";\n    stop;\n}\n\nstop;\n",
+		},
+		{
+			name:    "stop sharing its line is refused",
+			content: "keep; stop;\n",
+			opts:    SieveTemplateOptions{From: "g@example.com", Action: "discard"},
+			wantErr: "1:7: a rule added after stop would be unreachable, and stop shares its line with other commands; move stop to its own line first",
+		},
+		{
+			name:    "existing script does not parse",
+			content: "if true {",
+			opts:    SieveTemplateOptions{From: "e@example.com", Action: "keep"},
+			wantErr: "existing script does not parse: line 1, column 10: expected \"}\", found end of script",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AppendSieveRule(tt.content, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("AppendSieveRule() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AppendSieveRule() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("AppendSieveRule() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSieveRules(t *testing.T) {
+	got, err := GenerateSieveRules([]SieveTemplateOptions{
+		{From: "a@example.com", Action: "discard"},
+		{FromDomain: "shop.example", Action: "fileinto", FileInto: "Receipts", Copy: true},
+		{ListID: "dev.example.org", MarkRead: true, Action: "fileinto", FileInto: "Lists"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateSieveRules() error: %v", err)
+	}
+	if !strings.HasPrefix(got, "require [\"fileinto\", \"copy\", \"imap4flags\"];\n\nif address :is \"from\" \"a@example.com\" {\n") {
+		t.Errorf("GenerateSieveRules() should start with one combined require line, got:\n%s", got)
+	}
+	if strings.Count(got, "require") != 1 || strings.Count(got, "\nif ") != 3 || strings.Count(got, "stop;") != 2 {
+		t.Errorf("GenerateSieveRules() = \n%s\nwant three rules under one require, the copy rule without stop", got)
+	}
+
+	_, err = GenerateSieveRules([]SieveTemplateOptions{{From: "a@example.com", Action: "discard"}, {Action: "keep"}})
+	if err == nil || !strings.HasPrefix(err.Error(), "rule 2: ") {
+		t.Errorf("GenerateSieveRules() error = %v, want rule number", err)
+	}
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"strings"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"git.sr.ht/~rockorager/go-jmap"
 
 	"github.com/cboone/fm/internal/jmap/sieve"
+	"github.com/cboone/fm/internal/types"
 )
 
 func sieveTestClient(doFunc func(*jmap.Request) (*jmap.Response, error)) *Client {
@@ -128,6 +130,7 @@ func TestGetSieveScript(t *testing.T) {
 				{
 					Name: "SieveScript/get",
 					Args: &sieve.GetResponse{
+						State: "st-1",
 						List: []*sieve.SieveScript{
 							{ID: "S1", Name: "Block spam", BlobID: "B1", IsActive: true},
 						},
@@ -150,6 +153,9 @@ func TestGetSieveScript(t *testing.T) {
 	if result.Content != "require [\"fileinto\"];\nkeep;\n" {
 		t.Errorf("GetSieveScript() content = %q, want sieve script", result.Content)
 	}
+	if result.State != "st-1" {
+		t.Errorf("GetSieveScript() State = %q, want %q", result.State, "st-1")
+	}
 }
 
 func TestGetSieveScript_NotFound(t *testing.T) {
@@ -432,3 +438,114 @@ func TestDeleteSieveScript_ActiveScript(t *testing.T) {
 		t.Errorf("DeleteSieveScript() error = %q, want deactivation hint", err.Error())
 	}
 }
+
+func TestUpdateSieveScript(t *testing.T) {
+	var gotSet *sieve.Set
+	c := sieveTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		gotSet = req.Calls[0].Args.(*sieve.Set)
+		return &jmap.Response{
+			Responses: []*jmap.Invocation{
+				{
+					Name: "SieveScript/set",
+					Args: &sieve.SetResponse{
+						Updated: map[jmap.ID]*sieve.SieveScript{"S1": nil},
+					},
+				},
+			},
+		}, nil
+	})
+	c.uploadFunc = func(accountID jmap.ID, blob io.Reader) (*jmap.UploadResponse, error) {
+		return &jmap.UploadResponse{ID: "B-new"}, nil
+	}
+
+	current := types.SieveScriptDetail{ID: "S1", Name: "Filters", BlobID: "B1", IsActive: true, State: "st-1"}
+	result, err := c.UpdateSieveScript(current, "discard;\n")
+	if err != nil {
+		t.Fatalf("UpdateSieveScript() error: %v", err)
+	}
+	if gotSet.IfInState != "st-1" {
+		t.Errorf("IfInState = %q, want %q", gotSet.IfInState, "st-1")
+	}
+	if blobID := gotSet.Update["S1"]["blobId"]; blobID != jmap.ID("B-new") {
+		t.Errorf("patch blobId = %v, want B-new", blobID)
+	}
+	if result.BlobID != "B-new" || result.Name != "Filters" || !result.IsActive {
+		t.Errorf("UpdateSieveScript() = %+v, want new blob with name and active state kept", result)
+	}
+}
+
+func TestUpdateSieveScript_StateMismatch(t *testing.T) {
+	c := sieveTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		return &jmap.Response{
+			Responses: []*jmap.Invocation{
+				{
+					Name: "error",
+					Args: &jmap.MethodError{Type: "stateMismatch"},
+				},
+			},
+		}, nil
+	})
+	c.uploadFunc = func(accountID jmap.ID, blob io.Reader) (*jmap.UploadResponse, error) {
+		return &jmap.UploadResponse{ID: "B-new"}, nil
+	}
+
+	_, err := c.UpdateSieveScript(types.SieveScriptDetail{ID: "S1", State: "old"}, "keep;\n")
+	if !errors.Is(err, ErrStateMismatch) {
+		t.Errorf("UpdateSieveScript() error = %v, want ErrStateMismatch", err)
+	}
+}
+
+func TestUpdateSieveScript_NotUpdated(t *testing.T) {
+	desc := "script has errors"
+	c := sieveTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		return &jmap.Response{
+			Responses: []*jmap.Invocation{
+				{
+					Name: "SieveScript/set",
+					Args: &sieve.SetResponse{
+						NotUpdated: map[jmap.ID]*jmap.SetError{
+							"S1": {Type: "invalidSieve", Description: &desc},
+						},
+					},
+				},
+			},
+		}, nil
+	})
+	c.uploadFunc = func(accountID jmap.ID, blob io.Reader) (*jmap.UploadResponse, error) {
+		return &jmap.UploadResponse{ID: "B-new"}, nil
+	}
+
+	_, err := c.UpdateSieveScript(types.SieveScriptDetail{ID: "S1"}, "bogus\n")
+	if err == nil || !strings.Contains(err.Error(), "script has errors") {
+		t.Errorf("UpdateSieveScript() error = %v, want description", err)
+	}
+}
+
+func TestDiffSieveScript(t *testing.T) {
+	var sets []*sieve.Set
+	c := sieveRestoreClient([]*sieve.SieveScript{{ID: "S1", Name: "Filters", BlobID: "B1"}},
+		map[jmap.ID]string{"B1": "keep;\n"}, &sets)
+
+	result, err := c.DiffSieveScript("S1", "keep;\nstop;\n", "filters.sieve")
+	if err != nil {
+		t.Fatalf("DiffSieveScript() error: %v", err)
+	}
+	if result.Identical || !strings.Contains(result.Diff, "+stop;") || !strings.Contains(result.Diff, "--- server/S1") {
+		t.Errorf("unexpected diff: %+v", result)
+	}
+
+	result, err = c.DiffSieveScript("S1", "keep;\n", "filters.sieve")
+	if err != nil {
+		t.Fatalf("DiffSieveScript() error: %v", err)
+	}
+	if !result.Identical || result.Diff != "" {
+		t.Errorf("expected identical scripts, got %+v", result)
+	}
+
+	if _, err := c.DiffSieveScript("S9", "keep;\n", "filters.sieve"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing script, got %v", err)
+	}
+	if len(sets) != 0 {
+		t.Errorf("expected diff to change nothing, got %d sets", len(sets))
+	}
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+)
+
+// SnoozedMailboxName is the top-level folder snoozed emails wait in. It is
+// created on first use.
+const SnoozedMailboxName = "Snoozed"
+
+// snoozeKeywordPrefix starts the keyword that carries a snoozed email's
+// return time, such as "snoozed-until-20261101t0900z", so the time is kept
+// on the server as well as in the local snooze state. Keywords are
+// case-insensitive, so the UTC time is written in lower case.
+const snoozeKeywordPrefix = "snoozed-until-"
+
+// snoozeKeywordLayout is the time layout after snoozeKeywordPrefix, without
+// its trailing "z".
+const snoozeKeywordLayout = "20060102t1504"
+
+// SnoozeKeyword returns the keyword that records until, to the minute.
+// Seconds are rounded up to the next minute, so an email never wakes
+// before its time.
+func SnoozeKeyword(until time.Time) string {
+	if t := until.Truncate(time.Minute); !t.Equal(until) {
+		until = t.Add(time.Minute)
+	}
+	return snoozeKeywordPrefix + strings.ToLower(until.UTC().Format(snoozeKeywordLayout)) + "z"
+}
+
+// parseSnoozeKeyword returns the time recorded by a snooze keyword.
+func parseSnoozeKeyword(keyword string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(strings.ToLower(keyword), snoozeKeywordPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	rest, ok = strings.CutSuffix(rest, "z")
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(snoozeKeywordLayout, rest)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// SnoozedEmail is an email waiting in the snoozed mailbox. Until is the
+// return time from its snooze keyword, the latest if it has several, or nil
+// without one; Keywords lists every snooze keyword on it.
+type SnoozedEmail struct {
+	ID       string
+	Until    *time.Time
+	Keywords []string
+}
+
+// SnoozeEmails moves emails into target, which must pass
+// ValidateTargetMailbox, and tags each with the snooze keyword for until.
+// Snooze keywords from an earlier snooze are removed in the same update, so
+// a re-snoozed email carries only its new return time.
+func (c *Client) SnoozeEmails(emailIDs []string, target *mailbox.Mailbox, until time.Time) ([]string, []string, error) {
+	if err := ValidateTargetMailbox(target); err != nil {
+		return nil, nil, err
+	}
+	previous, err := c.snoozeKeywords(emailIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyword := SnoozeKeyword(until)
+	succeeded, errors := c.batchSetEmails(emailIDs, func(id string) jmap.Patch {
+		p := jmap.Patch{"mailboxIds": map[jmap.ID]bool{target.ID: true}}
+		for _, kw := range previous[id] {
+			p["keywords/"+kw] = nil
+		}
+		p["keywords/"+keyword] = true
+		return p
+	})
+	return succeeded, errors, nil
+}
+
+// snoozeKeywords returns the snooze keywords already set on each of ids.
+func (c *Client) snoozeKeywords(ids []string) (map[string][]string, error) {
+	keywords := make(map[string][]string)
+	size := c.maxBatchSize()
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+		jmapIDs := make([]jmap.ID, end-start)
+		for i, id := range ids[start:end] {
+			jmapIDs[i] = jmap.ID(id)
+		}
+
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{
+			Account:    c.accountID,
+			IDs:        jmapIDs,
+			Properties: []string{"id", "keywords"},
+		})
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("email/get: %w", err)
+		}
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.GetResponse:
+				for _, e := range r.List {
+					for kw, set := range e.Keywords {
+						if _, ok := parseSnoozeKeyword(kw); set && ok {
+							keywords[string(e.ID)] = append(keywords[string(e.ID)], kw)
+						}
+					}
+				}
+			case *jmap.MethodError:
+				return nil, fmt.Errorf("email/get: %s", r.Error())
+			}
+		}
+	}
+	return keywords, nil
+}
+
+// SnoozedEmails returns every email in the snoozed mailbox with the return
+// time from its snooze keyword.
+func (c *Client) SnoozedEmails(mailboxID jmap.ID) ([]SnoozedEmail, error) {
+	var snoozed []SnoozedEmail
+	filter := &email.FilterCondition{InMailbox: mailboxID}
+	_, err := c.scanEmails("snoozed query", filter, []string{"id", "keywords"}, nil, func(e *email.Email) {
+		s := SnoozedEmail{ID: string(e.ID)}
+		for kw, set := range e.Keywords {
+			t, ok := parseSnoozeKeyword(kw)
+			if !set || !ok {
+				continue
+			}
+			s.Keywords = append(s.Keywords, kw)
+			if s.Until == nil || t.After(*s.Until) {
+				s.Until = &t
+			}
+		}
+		snoozed = append(snoozed, s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snoozed, nil
+}
+
+// WakeEmails moves snoozed emails into inbox, which must pass
+// ValidateTargetMailbox, marks them unread, and removes their snooze
+// keywords.
+func (c *Client) WakeEmails(emails []SnoozedEmail, inbox *mailbox.Mailbox) ([]string, []string, error) {
+	if err := ValidateTargetMailbox(inbox); err != nil {
+		return nil, nil, err
+	}
+	ids := make([]string, len(emails))
+	keywords := make(map[string][]string, len(emails))
+	for i, e := range emails {
+		ids[i] = e.ID
+		keywords[e.ID] = e.Keywords
+	}
+	succeeded, errors := c.batchSetEmails(ids, func(id string) jmap.Patch {
+		p := jmap.Patch{
+			"mailboxIds":     map[jmap.ID]bool{inbox.ID: true},
+			"keywords/$seen": nil,
+		}
+		for _, kw := range keywords[id] {
+			p["keywords/"+kw] = nil
+		}
+		return p
+	})
+	return succeeded, errors, nil
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+)
+
+func TestSnoozeKeyword(t *testing.T) {
+	until := time.Date(2026, 11, 1, 9, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	kw := SnoozeKeyword(until)
+	if kw != "snoozed-until-20261101t1400z" {
+		t.Fatalf("SnoozeKeyword = %q", kw)
+	}
+	if kw := SnoozeKeyword(until.Add(time.Second)); kw != "snoozed-until-20261101t1401z" {
+		t.Errorf("expected seconds rounded up to the next minute, got %q", kw)
+	}
+	got, ok := parseSnoozeKeyword("Snoozed-Until-20261101T1400Z")
+	if !ok || !got.Equal(until) {
+		t.Errorf("parseSnoozeKeyword = %v, %v", got, ok)
+	}
+	for _, bad := range []string{"$seen", "snoozed-until-", "snoozed-until-20261101t1400", "snoozed-until-nope z"} {
+		if _, ok := parseSnoozeKeyword(bad); ok {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+// setRecorder records the patches of each Email/set and reports every
+// update as successful.
+func setRecorder(patches map[jmap.ID]jmap.Patch) func(*jmap.Request) (*jmap.Response, error) {
+	return func(req *jmap.Request) (*jmap.Response, error) {
+		set := req.Calls[0].Args.(*email.Set)
+		updated := make(map[jmap.ID]*email.Email)
+		for id, p := range set.Update {
+			patches[id] = p
+			updated[id] = nil
+		}
+		return &jmap.Response{Responses: []*jmap.Invocation{
+			{Name: "Email/set", CallID: "0", Args: &email.SetResponse{Updated: updated}},
+		}}, nil
+	}
+}
+
+func TestSnoozeEmails(t *testing.T) {
+	patches := make(map[jmap.ID]jmap.Patch)
+	record := setRecorder(patches)
+	c := &Client{accountID: "acct-1", doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+		if _, ok := req.Calls[0].Args.(*email.Get); ok {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/get", CallID: "0", Args: &email.GetResponse{List: []*email.Email{
+					{ID: "M1", Keywords: map[string]bool{"$seen": true, "snoozed-until-20261020t0900z": true}},
+					{ID: "M2", Keywords: map[string]bool{"$seen": true}},
+				}}},
+			}}, nil
+		}
+		return record(req)
+	}}
+	snoozed := &mailbox.Mailbox{ID: "mb-snoozed", Name: "Snoozed"}
+
+	until := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	succeeded, errs, err := c.SnoozeEmails([]string{"M1", "M2"}, snoozed, until)
+	if err != nil || len(succeeded) != 2 || len(errs) != 0 {
+		t.Fatalf("SnoozeEmails = %v, %v, %v", succeeded, errs, err)
+	}
+	p := patches["M1"]
+	if mbs := p["mailboxIds"].(map[jmap.ID]bool); len(mbs) != 1 || !mbs["mb-snoozed"] {
+		t.Errorf("unexpected mailboxIds: %v", p["mailboxIds"])
+	}
+	if p["keywords/snoozed-until-20261101t0900z"] != true {
+		t.Errorf("expected the snooze keyword, got %v", p)
+	}
+	if v, ok := p["keywords/snoozed-until-20261020t0900z"]; !ok || v != nil {
+		t.Errorf("expected the earlier snooze keyword to be removed, got %v", p)
+	}
+	if _, ok := p["keywords/$seen"]; ok {
+		t.Errorf("expected other keywords untouched, got %v", p)
+	}
+	if len(patches["M2"]) != 2 {
+		t.Errorf("expected only mailboxIds and the snooze keyword for M2, got %v", patches["M2"])
+	}
+
+	trash := &mailbox.Mailbox{ID: "mb-trash", Name: "Trash", Role: mailbox.RoleTrash}
+	var forbidden *ErrForbidden
+	if _, _, err := c.SnoozeEmails([]string{"M3"}, trash, until); !errors.As(err, &forbidden) {
+		t.Errorf("expected the trash to be refused, got %v", err)
+	}
+	if _, ok := patches["M3"]; ok {
+		t.Error("expected nothing sent for a refused mailbox")
+	}
+}
+
+func TestSnoozedEmailsAndWake(t *testing.T) {
+	c := mailboxScanClient(t, map[jmap.ID][]*email.Email{
+		"mb-snoozed": {
+			{ID: "M1", Keywords: map[string]bool{"$seen": true, "snoozed-until-20261101t0900z": true, "snoozed-until-20261020t0900z": true}},
+			{ID: "M2", Keywords: map[string]bool{"$seen": true}},
+		},
+	})
+	snoozed, err := c.SnoozedEmails("mb-snoozed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snoozed) != 2 || snoozed[0].Until == nil || !snoozed[0].Until.Equal(time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)) ||
+		len(snoozed[0].Keywords) != 2 || snoozed[1].Until != nil {
+		t.Fatalf("unexpected snoozed emails: %+v", snoozed)
+	}
+
+	patches := make(map[jmap.ID]jmap.Patch)
+	c.doFunc = setRecorder(patches)
+	inbox := &mailbox.Mailbox{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox}
+	succeeded, errs, err := c.WakeEmails(snoozed[:1], inbox)
+	if err != nil || len(succeeded) != 1 || len(errs) != 0 {
+		t.Fatalf("WakeEmails = %v, %v, %v", succeeded, errs, err)
+	}
+	p := patches["M1"]
+	if mbs := p["mailboxIds"].(map[jmap.ID]bool); !mbs["mb-inbox"] || len(mbs) != 1 {
+		t.Errorf("unexpected mailboxIds: %v", p["mailboxIds"])
+	}
+	for _, key := range []string{"keywords/$seen", "keywords/snoozed-until-20261101t0900z", "keywords/snoozed-until-20261020t0900z"} {
+		if v, ok := p[key]; !ok || v != nil {
+			t.Errorf("expected %s to be removed, got %v", key, p)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// Trend bucket sizes.
+const (
+	TrendByDay   = "day"
+	TrendByWeek  = "week"
+	TrendByMonth = "month"
+)
+
+// Trend groupings. TrendGroupNewsletter groups by sender like
+// TrendGroupSender but keeps only senders of list mail.
+const (
+	TrendGroupNone       = ""
+	TrendGroupSender     = "sender"
+	TrendGroupDomain     = "domain"
+	TrendGroupNewsletter = "newsletter"
+)
+
+// TrendsOptions holds parameters for time-series aggregation. Emails
+// received at or after After and before Before are counted; both are
+// required.
+type TrendsOptions struct {
+	MailboxID string
+	By        string
+	Group     string
+	After     time.Time
+	Before    time.Time
+	Limit     int // groups to keep, by total; 0 keeps all
+}
+
+// trendsProperties are the Email/get properties for time-series aggregation.
+var trendsProperties = []string{"id", "from", "receivedAt"}
+
+// BucketStart returns the start of the day, week (from Monday), or month
+// containing t, in UTC.
+func BucketStart(t time.Time, by string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch by {
+	case TrendByWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case TrendByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// nextBucket returns the start of the bucket after the one starting at start.
+func nextBucket(start time.Time, by string) time.Time {
+	switch by {
+	case TrendByWeek:
+		return start.AddDate(0, 0, 7)
+	case TrendByMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// EmailTrends counts the emails received in each day, week, or month of a
+// date range, in total and per sender, domain, or newsletter sender. Every
+// bucket in the range is reported, including empty ones.
+func (c *Client) EmailTrends(opts TrendsOptions) (types.TrendsResult, error) {
+	switch opts.By {
+	case TrendByDay, TrendByWeek, TrendByMonth:
+	default:
+		return types.TrendsResult{}, fmt.Errorf("unsupported bucket size %q", opts.By)
+	}
+	switch opts.Group {
+	case TrendGroupNone, TrendGroupSender, TrendGroupDomain, TrendGroupNewsletter:
+	default:
+		return types.TrendsResult{}, fmt.Errorf("unsupported grouping %q", opts.Group)
+	}
+	if !opts.After.Before(opts.Before) {
+		return types.TrendsResult{}, fmt.Errorf("the date range is empty")
+	}
+
+	var starts []time.Time
+	for start := BucketStart(opts.After, opts.By); start.Before(opts.Before); start = nextBucket(start, opts.By) {
+		starts = append(starts, start)
+	}
+	bucketOf := func(t time.Time) int {
+		return sort.Search(len(starts), func(i int) bool { return starts[i].After(t) }) - 1
+	}
+
+	after, before := opts.After, opts.Before
+	filter := &email.FilterCondition{
+		InMailbox: jmap.ID(opts.MailboxID),
+		After:     &after,
+		Before:    &before,
+	}
+	props := trendsProperties
+	if opts.Group == TrendGroupNewsletter {
+		props = append(append([]string{}, trendsProperties...), "headers")
+	}
+
+	type groupAcc struct {
+		name       string
+		counts     []int
+		newsletter bool
+	}
+	counts := make([]int, len(starts))
+	groups := make(map[string]*groupAcc)
+	counted := 0
+
+	_, err := c.scanEmails("trends query", filter, props, nil, func(e *email.Email) {
+		if e.ReceivedAt == nil {
+			return
+		}
+		i := bucketOf(*e.ReceivedAt)
+		if i < 0 {
+			return
+		}
+		counts[i]++
+		counted++
+
+		if opts.Group == TrendGroupNone || len(e.From) == 0 || e.From[0].Email == "" {
+			return
+		}
+		key := strings.ToLower(e.From[0].Email)
+		if opts.Group == TrendGroupDomain {
+			key = extractDomain(key)
+			if key == "" {
+				return
+			}
+		}
+		acc, ok := groups[key]
+		if !ok {
+			acc = &groupAcc{counts: make([]int, len(starts))}
+			groups[key] = acc
+		}
+		acc.counts[i]++
+		if opts.Group != TrendGroupDomain && acc.name == "" {
+			acc.name = e.From[0].Name
+		}
+		if opts.Group == TrendGroupNewsletter && hasListHeaders(e.Headers) {
+			acc.newsletter = true
+		}
+	})
+	if err != nil {
+		return types.TrendsResult{}, err
+	}
+
+	result := types.TrendsResult{
+		By:      opts.By,
+		Group:   opts.Group,
+		After:   opts.After,
+		Before:  opts.Before,
+		Total:   counted,
+		Buckets: make([]types.TrendBucket, len(starts)),
+	}
+	for i, start := range starts {
+		result.Buckets[i] = types.TrendBucket{Start: start, Count: counts[i]}
+	}
+	result.Slope = trendSlope(counts)
+
+	if opts.Group == TrendGroupNone {
+		return result, nil
+	}
+	result.Groups = []types.TrendGroup{}
+	for key, acc := range groups {
+		if opts.Group == TrendGroupNewsletter && !acc.newsletter {
+			continue
+		}
+		g := types.TrendGroup{Key: key, Name: acc.name, Counts: acc.counts, Slope: trendSlope(acc.counts)}
+		for _, n := range acc.counts {
+			g.Total += n
+		}
+		result.Groups = append(result.Groups, g)
+	}
+	sort.Slice(result.Groups, func(i, j int) bool {
+		if result.Groups[i].Total != result.Groups[j].Total {
+			return result.Groups[i].Total > result.Groups[j].Total
+		}
+		return result.Groups[i].Key < result.Groups[j].Key
+	})
+	if opts.Limit > 0 && len(result.Groups) > opts.Limit {
+		result.Groups = result.Groups[:opts.Limit]
+	}
+	return result, nil
+}
+
+// trendSlope is the least-squares slope of counts, in emails per bucket,
+// rounded to two decimals. It is positive when volume is rising.
+func trendSlope(counts []int) float64 {
+	n := float64(len(counts))
+	if n < 2 {
+		return 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for i, c := range counts {
+		x, y := float64(i), float64(c)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	return math.Round(slope*100) / 100
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+// trendsTestClient returns a client whose Email/query returns emails in a
+// single page, checking the date range filter.
+func trendsTestClient(t *testing.T, emails []*email.Email, wantProps int) *Client {
+	t.Helper()
+	return &Client{
+		accountID: "test-account",
+		doFunc: scanStub(func(q *email.Query, get *email.Get) []*email.Email {
+			fc := q.Filter.(*email.FilterCondition)
+			if fc.After == nil || fc.Before == nil || fc.InMailbox != "mb-inbox" {
+				t.Errorf("unexpected filter: %+v", fc)
+			}
+			if len(get.Properties) != wantProps {
+				t.Errorf("unexpected properties: %v", get.Properties)
+			}
+			return emails
+		}),
+	}
+}
+
+func received(s string) *time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return &t
+}
+
+func TestBucketStart(t *testing.T) {
+	ts := time.Date(2026, 3, 5, 15, 30, 0, 0, time.UTC) // a Thursday
+	tests := map[string]string{
+		TrendByDay:   "2026-03-05",
+		TrendByWeek:  "2026-03-02",
+		TrendByMonth: "2026-03-01",
+	}
+	for by, want := range tests {
+		if got := BucketStart(ts, by).Format("2006-01-02"); got != want {
+			t.Errorf("BucketStart(%s) = %s, want %s", by, got, want)
+		}
+	}
+	sunday := time.Date(2026, 3, 8, 23, 0, 0, 0, time.UTC)
+	if got := BucketStart(sunday, TrendByWeek).Format("2006-01-02"); got != "2026-03-02" {
+		t.Errorf("Sunday belongs to the week starting %s, want 2026-03-02", got)
+	}
+}
+
+func TestEmailTrends_BucketsAndSenders(t *testing.T) {
+	alice := []*mail.Address{{Email: "Alice@Example.com", Name: "Alice"}}
+	bob := []*mail.Address{{Email: "bob@example.org"}}
+	c := trendsTestClient(t, []*email.Email{
+		{ID: "M1", From: alice, ReceivedAt: received("2026-03-03T10:00:00Z")},
+		{ID: "M2", From: alice, ReceivedAt: received("2026-03-03T11:00:00Z")},
+		{ID: "M3", From: bob, ReceivedAt: received("2026-03-01T09:00:00Z")},
+		{ID: "M4", From: alice, ReceivedAt: received("2026-03-01T08:00:00Z")},
+	}, len(trendsProperties))
+
+	result, err := c.EmailTrends(TrendsOptions{
+		MailboxID: "mb-inbox",
+		By:        TrendByDay,
+		Group:     TrendGroupSender,
+		After:     time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Before:    time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 4 || len(result.Buckets) != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	counts := []int{result.Buckets[0].Count, result.Buckets[1].Count, result.Buckets[2].Count}
+	if counts[0] != 2 || counts[1] != 0 || counts[2] != 2 {
+		t.Errorf("bucket counts = %v, want [2 0 2]", counts)
+	}
+	if len(result.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", result.Groups)
+	}
+	a, b := result.Groups[0], result.Groups[1]
+	if a.Key != "alice@example.com" || a.Name != "Alice" || a.Total != 3 || a.Slope != 0.5 {
+		t.Errorf("unexpected first group: %+v", a)
+	}
+	if b.Key != "bob@example.org" || b.Total != 1 || b.Slope != -0.5 {
+		t.Errorf("unexpected second group: %+v", b)
+	}
+}
+
+func TestEmailTrends_DomainsAndNewsletters(t *testing.T) {
+	list := []*email.Header{{Name: "List-Unsubscribe", Value: "<mailto:u@news.example.com>"}}
+	emails := []*email.Email{
+		{ID: "M1", From: []*mail.Address{{Email: "news@news.example.com"}}, Headers: list, ReceivedAt: received("2026-03-10T10:00:00Z")},
+		{ID: "M2", From: []*mail.Address{{Email: "friend@example.org"}}, ReceivedAt: received("2026-03-10T11:00:00Z")},
+		{ID: "M3", From: []*mail.Address{{Email: "other@example.org"}}, ReceivedAt: received("2026-02-20T11:00:00Z")},
+	}
+	opts := TrendsOptions{
+		MailboxID: "mb-inbox",
+		By:        TrendByMonth,
+		After:     time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Before:    time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	opts.Group = TrendGroupDomain
+	result, err := trendsTestClient(t, emails, len(trendsProperties)).EmailTrends(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Buckets) != 2 || result.Groups[0].Key != "example.org" || result.Groups[0].Total != 2 {
+		t.Errorf("unexpected domain trends: %+v", result)
+	}
+
+	opts.Group = TrendGroupNewsletter
+	opts.Limit = 5
+	result, err = trendsTestClient(t, emails, len(trendsProperties)+1).EmailTrends(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Groups) != 1 || result.Groups[0].Key != "news@news.example.com" {
+		t.Errorf("expected only the newsletter sender, got %+v", result.Groups)
+	}
+	if result.Total != 3 {
+		t.Errorf("expected the total to count every email, got %d", result.Total)
+	}
+}
+
+func TestEmailTrends_RejectsBadOptions(t *testing.T) {
+	c := &Client{}
+	after := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, opts := range []TrendsOptions{
+		{By: "year", After: after, Before: after.AddDate(0, 1, 0)},
+		{By: TrendByDay, Group: "subject", After: after, Before: after.AddDate(0, 1, 0)},
+		{By: TrendByDay, After: after, Before: after},
+	} {
+		if _, err := c.EmailTrends(opts); err == nil {
+			t.Errorf("expected an error for %+v", opts)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/classify"
+	"github.com/cboone/fm/internal/types"
+)
+
+// Triage categories, in the order a plan handles them.
+const (
+	TriagePersonal      = "personal"
+	TriageTimeSensitive = "time_sensitive"
+	TriageSpamLike      = "spam_like"
+	TriagePromotional   = "promotional"
+	TriageTransactional = "transactional"
+)
+
+// TriageCategories lists every triage category.
+var TriageCategories = []string{TriagePersonal, TriageTimeSensitive, TriageSpamLike, TriagePromotional, TriageTransactional}
+
+// TriageOptions holds parameters for triage suggestions. The most recent
+// Limit unread emails in MailboxID are considered; 0 considers all.
+// AccountArgs are global flags, such as --profile, added to every proposed
+// command so it acts on the same account.
+type TriageOptions struct {
+	MailboxID   string
+	Limit       int
+	Now         time.Time
+	AccountArgs []string
+}
+
+// urgentPhrases in a subject or preview mark a message as time-sensitive.
+var urgentPhrases = []string{
+	"urgent", "action required", "action needed", "asap", "deadline",
+	"overdue", "past due", "final notice", "due today", "due tomorrow", "due date",
+	"expires", "expiring", "expiration", "renewal", "renew", "reminder",
+	"verification code", "verify your", "appointment", "rsvp", "respond by",
+}
+
+// triageMessage is one message with its triage category and reasons.
+type triageMessage struct {
+	email    *email.Email
+	sender   types.Classification
+	category string
+	reasons  []string
+}
+
+// SuggestTriage classifies the unread mail in a mailbox as personal,
+// time-sensitive, spam-like, promotional, or transactional, giving the
+// rules that fired for each message, and proposes the fm commands that
+// would handle them. Nothing is changed; the commands are returned for
+// review, together in Content as a shell script.
+func (c *Client) SuggestTriage(opts TriageOptions) (types.TriagePlan, error) {
+	classifier := classify.New()
+	var emails []*email.Email
+	properties := append(slices.Clone(summaryProperties), "headers")
+	filter := &email.FilterCondition{InMailbox: jmap.ID(opts.MailboxID), NotKeyword: "$seen"}
+	_, err := c.scanEmails("triage query", filter, properties, nil, func(e *email.Email) {
+		classifier.Add(senderAddress(e), classifyHeaders(e.Headers))
+		if opts.Limit == 0 || len(emails) < opts.Limit {
+			emails = append(emails, e)
+		}
+	})
+	if err != nil {
+		return types.TriagePlan{}, err
+	}
+
+	messages := make([]triageMessage, 0, len(emails))
+	for _, e := range emails {
+		m := triageMessage{email: e, sender: classifier.Classify(senderAddress(e))}
+		m.category, m.reasons = triageCategory(e, m.sender)
+		messages = append(messages, m)
+	}
+
+	plan := types.TriagePlan{
+		GeneratedAt: opts.Now,
+		Scanned:     len(messages),
+		Messages:    []types.TriageMessage{},
+		Operations:  []types.TriageOperation{},
+	}
+	for _, cat := range TriageCategories {
+		var group []triageMessage
+		for _, m := range messages {
+			if m.category == cat {
+				group = append(group, m)
+			}
+		}
+		if len(group) == 0 {
+			continue
+		}
+		plan.Counts = append(plan.Counts, types.CategoryStat{Category: cat, Senders: countSenders(group), Count: len(group)})
+		for _, m := range group {
+			summary := convertSummaries([]*email.Email{m.email})[0]
+			summary.Category = m.sender.Category
+			plan.Messages = append(plan.Messages, types.TriageMessage{EmailSummary: summary, Triage: cat, Reasons: m.reasons})
+		}
+		plan.Operations = append(plan.Operations, triageOperations(cat, group, opts.AccountArgs)...)
+	}
+	plan.Content = triageScript(plan)
+	return plan, nil
+}
+
+// triageCategory applies the triage rules to one message, first match
+// wins: spam signals, then the sender's classification, with urgent
+// wording lifting personal and automated account mail to time-sensitive.
+func triageCategory(e *email.Email, sender types.Classification) (string, []string) {
+	if reasons := spamSignals(e); len(reasons) > 0 {
+		return TriageSpamLike, reasons
+	}
+
+	senderReason := fmt.Sprintf("sender classified %s (%.2f): %s", sender.Category, sender.Score, strings.Join(sender.Reasons, ", "))
+	switch sender.Category {
+	case classify.Newsletter, classify.MailingList:
+		return TriagePromotional, []string{senderReason}
+	}
+	if phrase := urgentPhrase(e); phrase != "" {
+		return TriageTimeSensitive, []string{fmt.Sprintf("subject or preview mentions %q", phrase), senderReason}
+	}
+	if sender.Category == classify.Personal {
+		return TriagePersonal, []string{senderReason}
+	}
+	return TriageTransactional, []string{senderReason}
+}
+
+// spamSignals returns the reasons a message looks like spam: the server's
+// $junk or $phishing keywords, a spam-filter verdict header, failed sender
+// authentication, or a display name that shows a different address.
+func spamSignals(e *email.Email) []string {
+	var reasons []string
+	for _, kw := range []string{"$junk", "$phishing"} {
+		if e.Keywords[kw] {
+			reasons = append(reasons, kw+" keyword")
+		}
+	}
+	for _, h := range e.Headers {
+		name := strings.ToLower(h.Name)
+		value := strings.ToLower(strings.TrimSpace(h.Value))
+		switch {
+		case name == "x-spam-flag" && value == "yes":
+			reasons = append(reasons, "X-Spam-Flag: YES")
+		case name == "x-spam-status" && strings.HasPrefix(value, "yes"):
+			reasons = append(reasons, "X-Spam-Status: Yes")
+		case name == "authentication-results":
+			for _, check := range []string{"dmarc=fail", "spf=fail", "dkim=fail"} {
+				if strings.Contains(value, check) {
+					reasons = append(reasons, "Authentication-Results: "+check)
+				}
+			}
+		}
+	}
+	if len(e.From) > 0 {
+		name, addr := strings.ToLower(e.From[0].Name), strings.ToLower(e.From[0].Email)
+		if strings.Contains(name, "@") && !strings.Contains(name, addr) {
+			reasons = append(reasons, fmt.Sprintf("display name %q shows a different address than %s", e.From[0].Name, e.From[0].Email))
+		}
+	}
+	return reasons
+}
+
+// urgentPhrase returns the first urgent phrase in the subject or preview.
+func urgentPhrase(e *email.Email) string {
+	text := strings.ToLower(e.Subject + " " + e.Preview)
+	for _, p := range urgentPhrases {
+		if strings.Contains(text, p) {
+			return p
+		}
+	}
+	return ""
+}
+
+func countSenders(group []triageMessage) int {
+	seen := make(map[string]bool)
+	for _, m := range group {
+		seen[senderAddress(m.email)] = true
+	}
+	return len(seen)
+}
+
+// triageOperations proposes the commands for one category, following the
+// review-email runbook: personal mail is flagged for review with a reply
+// drafted by hand, time-sensitive mail is flagged orange, spam is marked
+// read and moved to Junk, and promotional and transactional mail is marked
+// read and archived. Flagged messages are unflagged before they leave the
+// inbox.
+func triageOperations(category string, group []triageMessage, accountArgs []string) []types.TriageOperation {
+	ids := make([]string, len(group))
+	var flagged []string
+	for i, m := range group {
+		ids[i] = string(m.email.ID)
+		if m.email.Keywords["$flagged"] {
+			flagged = append(flagged, ids[i])
+		}
+	}
+	op := func(command string, args []string, ids []string, reason string) types.TriageOperation {
+		return types.TriageOperation{Category: category, Command: command, Args: args, IDs: ids, Reason: reason}
+	}
+
+	var ops []types.TriageOperation
+	switch category {
+	case TriagePersonal:
+		ops = append(ops, op("flag", nil, ids, "personal mail to review"))
+		for _, m := range group {
+			draft := op("draft", []string{"--reply-to", string(m.email.ID), "--body", "..."}, nil, "reply to "+senderAddress(m.email))
+			draft.Manual = true
+			ops = append(ops, draft)
+		}
+	case TriageTimeSensitive:
+		ops = append(ops, op("flag", []string{"--color", "orange"}, ids, "time-sensitive: upcoming commitment or deadline"))
+	case TriageSpamLike:
+		ops = append(ops, op("mark-read", nil, ids, "spam is marked read first"))
+		if len(flagged) > 0 {
+			ops = append(ops, op("unflag", nil, flagged, "spam is unflagged first"))
+		}
+		ops = append(ops, op("spam", nil, ids, "spam-like signals"))
+	case TriagePromotional, TriageTransactional:
+		ops = append(ops, op("mark-read", nil, ids, "archived mail is marked read first"))
+		if len(flagged) > 0 {
+			ops = append(ops, op("unflag", nil, flagged, "archived mail is unflagged first"))
+		}
+		ops = append(ops, op("archive", nil, ids, strings.ReplaceAll(category, "_", " ")+" mail"))
+	}
+	for i := range ops {
+		ops[i].CommandLine = triageCommandLine(ops[i], accountArgs)
+	}
+	return ops
+}
+
+// triageCommandLine renders op as an fm command line, with accountArgs
+// ahead of its own arguments. IDs are plain tokens, so only arguments need
+// quoting.
+func triageCommandLine(op types.TriageOperation, accountArgs []string) string {
+	parts := []string{"fm", op.Command}
+	for _, a := range slices.Concat(accountArgs, op.Args) {
+		parts = append(parts, shellQuote(a))
+	}
+	parts = append(parts, op.IDs...)
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes s for a POSIX shell when it holds anything but safe
+// characters.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:@=", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// triageScript renders the plan as a shell script to review and run.
+// Manual operations, such as drafting a reply, are commented out.
+func triageScript(plan types.TriagePlan) string {
+	if len(plan.Operations) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&b, "# fm triage plan for %d unread messages, %s.\n", plan.Scanned, plan.GeneratedAt.UTC().Format("2006-01-02 15:04"))
+	b.WriteString("# Review every line before running it. Commented lines need editing first.\n")
+	b.WriteString("set -e\n")
+	category := ""
+	for _, op := range plan.Operations {
+		if op.Category != category {
+			category = op.Category
+			fmt.Fprintf(&b, "\n# %s\n", category)
+		}
+		if op.Manual {
+			fmt.Fprintf(&b, "# %s  # %s\n", op.CommandLine, op.Reason)
+			continue
+		}
+		fmt.Fprintf(&b, "%s  # %s\n", op.CommandLine, op.Reason)
+	}
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func TestSuggestTriage(t *testing.T) {
+	alice := []*mail.Address{{Name: "Alice", Email: "alice@example.com"}}
+	shop := []*mail.Address{{Email: "news@shop.example"}}
+	bank := []*mail.Address{{Email: "alerts@bank.example"}}
+	promo := []*email.Header{{Name: "List-Unsubscribe", Value: "<https://shop.example/u>"}, {Name: "Precedence", Value: "bulk"}}
+
+	c := mailboxScanClient(t, map[jmap.ID][]*email.Email{
+		"mb-inbox": {
+			{ID: "M1", From: alice, Subject: "Dinner on Friday?"},
+			{ID: "M2", From: shop, Subject: "Sale expires tonight", Headers: promo},
+			{ID: "M3", From: bank, Subject: "Payment due tomorrow", Headers: []*email.Header{{Name: "Auto-Submitted", Value: "auto-generated"}}},
+			{ID: "M4", From: bank, Subject: "Statement ready", Keywords: map[string]bool{"$flagged": true}},
+			{ID: "M5", From: []*mail.Address{{Name: "support@bank.example", Email: "x@evil.example"}}, Subject: "Verify now",
+				Headers: []*email.Header{{Name: "Authentication-Results", Value: "mx.example; spf=fail smtp.mailfrom=evil.example"}}},
+			{ID: "M6", From: []*mail.Address{{Email: "bob@example.org"}}, Subject: "Renewal reminder for the club"},
+		},
+	})
+
+	plan, err := c.SuggestTriage(TriageOptions{MailboxID: "mb-inbox", Now: time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Scanned != 6 || len(plan.Messages) != 6 {
+		t.Fatalf("expected 6 messages, got %+v", plan.Messages)
+	}
+
+	triage := make(map[string]string)
+	reasons := make(map[string]string)
+	for _, m := range plan.Messages {
+		triage[m.ID] = m.Triage
+		reasons[m.ID] = strings.Join(m.Reasons, "; ")
+	}
+	want := map[string]string{
+		"M1": TriagePersonal, "M2": TriagePromotional, "M3": TriageTimeSensitive,
+		"M4": TriageTransactional, "M5": TriageSpamLike, "M6": TriageTimeSensitive,
+	}
+	for id, cat := range want {
+		if triage[id] != cat {
+			t.Errorf("%s triaged %s, want %s (%s)", id, triage[id], cat, reasons[id])
+		}
+	}
+	if !strings.Contains(reasons["M5"], "spf=fail") || !strings.Contains(reasons["M5"], "display name") {
+		t.Errorf("expected spam reasons for M5, got %q", reasons["M5"])
+	}
+	if !strings.Contains(reasons["M2"], "sender classified newsletter") {
+		t.Errorf("expected the sender classification for M2, got %q", reasons["M2"])
+	}
+
+	var lines []string
+	for _, op := range plan.Operations {
+		lines = append(lines, op.CommandLine)
+	}
+	got := strings.Join(lines, "\n")
+	for _, line := range []string{
+		"fm flag M1",
+		"fm draft --reply-to M1 --body ...",
+		"fm flag --color orange M3 M6",
+		"fm mark-read M5",
+		"fm spam M5",
+		"fm mark-read M2",
+		"fm archive M2",
+		"fm unflag M4",
+		"fm archive M4",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("expected %q in the plan:\n%s", line, got)
+		}
+	}
+
+	if !strings.HasPrefix(plan.Content, "#!/bin/sh\n") || !strings.Contains(plan.Content, "\n# fm draft --reply-to M1") ||
+		!strings.Contains(plan.Content, "\nfm spam M5  # spam-like signals\n") {
+		t.Errorf("unexpected plan script:\n%s", plan.Content)
+	}
+}
+
+func TestSuggestTriage_AccountArgs(t *testing.T) {
+	c := mailboxScanClient(t, map[jmap.ID][]*email.Email{
+		"mb-inbox": {{ID: "M1", From: []*mail.Address{{Email: "alice@example.com"}}}},
+	})
+	plan, err := c.SuggestTriage(TriageOptions{MailboxID: "mb-inbox", AccountArgs: []string{"--profile", "work", "--config", "/tmp/my fm.yaml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range plan.Operations {
+		if !strings.HasPrefix(op.CommandLine, "fm "+op.Command+" --profile work --config '/tmp/my fm.yaml' ") {
+			t.Errorf("expected the account flags in %q", op.CommandLine)
+		}
+	}
+	if !strings.Contains(plan.Content, "\nfm flag --profile work --config '/tmp/my fm.yaml' M1") {
+		t.Errorf("expected the account flags in the script:\n%s", plan.Content)
+	}
+}
+
+func TestSuggestTriage_LimitAndEmpty(t *testing.T) {
+	c := mailboxScanClient(t, map[jmap.ID][]*email.Email{
+		"mb-inbox": {
+			{ID: "M1", From: []*mail.Address{{Email: "alice@example.com"}}},
+			{ID: "M2", From: []*mail.Address{{Email: "alice@example.com"}}},
+		},
+	})
+	plan, err := c.SuggestTriage(TriageOptions{MailboxID: "mb-inbox", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Scanned != 1 || len(plan.Messages) != 1 || plan.Messages[0].ID != "M1" {
+		t.Errorf("expected only the newest message, got %+v", plan.Messages)
+	}
+
+	empty, err := mailboxScanClient(t, nil).SuggestTriage(TriageOptions{MailboxID: "mb-inbox"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Content != "" || len(empty.Operations) != 0 || empty.Messages == nil {
+		t.Errorf("unexpected empty plan: %+v", empty)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for in, want := range map[string]string{"orange": "orange", "...": "...", "it's": `'it'\''s'`, "": "''", "a b": "'a b'"} {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
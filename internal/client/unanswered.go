@@ -0,0 +1,172 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+	"git.sr.ht/~rockorager/go-jmap/mail/thread"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// UnansweredOptions holds parameters for finding threads awaiting a reply.
+// Threads with a message in MailboxID received before Now minus OlderThan,
+// and at or after After when set, are checked.
+type UnansweredOptions struct {
+	MailboxID string
+	After     *time.Time
+	OlderThan time.Duration
+	Now       time.Time
+	Limit     int // threads to keep; 0 keeps all
+}
+
+// threadBatchSize is the most threads fetched with one Thread/get call.
+const threadBatchSize = 100
+
+// Unanswered returns the latest message of every thread in the mailbox
+// whose latest message is inbound, has no $answered keyword, and has gone
+// without a reply for at least OlderThan. A message is outbound when it is
+// in the Sent mailbox or is from one of the account's own addresses.
+// Drafts are ignored, and so are threads from newsletters and mailing
+// lists. The longest waiting threads come first.
+func (c *Client) Unanswered(opts UnansweredOptions) (types.UnansweredResult, error) {
+	sent, err := c.GetMailboxByRole(mailbox.RoleSent)
+	if err != nil {
+		return types.UnansweredResult{}, fmt.Errorf("sent mailbox not found: %w", err)
+	}
+
+	cutoff := opts.Now.Add(-opts.OlderThan)
+	filter := &email.FilterCondition{InMailbox: jmap.ID(opts.MailboxID), Before: &cutoff}
+	if opts.After != nil {
+		filter.After = opts.After
+	}
+
+	// Emails arrive newest first, so the first seen per thread is the one
+	// whose headers decide whether the thread is list mail.
+	var threadIDs []jmap.ID
+	seen := make(map[jmap.ID]bool)
+	_, err = c.scanEmails("unanswered query", filter, []string{"id", "threadId", "headers"}, nil, func(e *email.Email) {
+		if e.ThreadID == "" || seen[e.ThreadID] {
+			return
+		}
+		seen[e.ThreadID] = true
+		if !hasListHeaders(e.Headers) {
+			threadIDs = append(threadIDs, e.ThreadID)
+		}
+	})
+	if err != nil {
+		return types.UnansweredResult{}, err
+	}
+
+	own := make(map[string]bool)
+	if c.jmap != nil && c.jmap.Session != nil && strings.Contains(c.jmap.Session.Username, "@") {
+		own[strings.ToLower(c.jmap.Session.Username)] = true
+	}
+
+	threads := make(map[jmap.ID][]*email.Email)
+	for batch := range slices.Chunk(threadIDs, threadBatchSize) {
+		if err := c.getThreadEmails(batch, threads); err != nil {
+			return types.UnansweredResult{}, err
+		}
+	}
+	for _, messages := range threads {
+		for _, e := range messages {
+			if e.MailboxIDs[sent.ID] {
+				for _, a := range e.From {
+					own[strings.ToLower(a.Email)] = true
+				}
+			}
+		}
+	}
+
+	outbound := func(e *email.Email) bool {
+		if e.MailboxIDs[sent.ID] {
+			return true
+		}
+		return len(e.From) > 0 && own[strings.ToLower(e.From[0].Email)]
+	}
+
+	result := types.UnansweredResult{Emails: []types.UnansweredEmail{}}
+	for _, id := range threadIDs {
+		var messages []*email.Email
+		for _, e := range threads[id] {
+			if !e.Keywords["$draft"] && e.ReceivedAt != nil {
+				messages = append(messages, e)
+			}
+		}
+		if len(messages) == 0 {
+			continue
+		}
+		sort.Slice(messages, func(i, j int) bool { return messages[i].ReceivedAt.Before(*messages[j].ReceivedAt) })
+
+		latest := messages[len(messages)-1]
+		if outbound(latest) || latest.Keywords["$answered"] || latest.ReceivedAt.After(cutoff) {
+			continue
+		}
+
+		entry := types.UnansweredEmail{
+			EmailSummary:   convertSummaries([]*email.Email{latest})[0],
+			ThreadMessages: len(messages),
+			WaitingDays:    int(opts.Now.Sub(*latest.ReceivedAt).Hours() / 24),
+		}
+		for i := len(messages) - 2; i >= 0; i-- {
+			if outbound(messages[i]) {
+				at := *messages[i].ReceivedAt
+				entry.LastReplyAt = &at
+				break
+			}
+		}
+		result.Emails = append(result.Emails, entry)
+	}
+
+	sort.SliceStable(result.Emails, func(i, j int) bool {
+		return result.Emails[i].ReceivedAt.Before(result.Emails[j].ReceivedAt)
+	})
+	result.Total = len(result.Emails)
+	if opts.Limit > 0 && len(result.Emails) > opts.Limit {
+		result.Emails = result.Emails[:opts.Limit]
+	}
+	return result, nil
+}
+
+// getThreadEmails fetches every email of the given threads with one
+// Thread/get and a back-referenced Email/get, adding them to threads.
+func (c *Client) getThreadEmails(threadIDs []jmap.ID, threads map[jmap.ID][]*email.Email) error {
+	req := &jmap.Request{}
+	threadCallID := req.Invoke(&thread.Get{
+		Account:    c.accountID,
+		IDs:        threadIDs,
+		Properties: []string{"id", "emailIds"},
+	})
+	req.Invoke(&email.Get{
+		Account:    c.accountID,
+		Properties: summaryProperties,
+		ReferenceIDs: &jmap.ResultReference{
+			ResultOf: threadCallID,
+			Name:     "Thread/get",
+			Path:     "/list/*/emailIds",
+		},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("thread/get: %w", err)
+	}
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.GetResponse:
+			for _, e := range r.List {
+				threads[e.ThreadID] = append(threads[e.ThreadID], e)
+			}
+		case *jmap.MethodError:
+			return fmt.Errorf("thread/get: %s", r.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+	"git.sr.ht/~rockorager/go-jmap/mail/thread"
+)
+
+// unansweredTestClient serves inbox as the Email/query results and the
+// emails of all as the members of their threads.
+func unansweredTestClient(t *testing.T, inbox []*email.Email, all []*email.Email) *Client {
+	t.Helper()
+	return &Client{
+		accountID: "acct-1",
+		jmap:      &jmap.Client{Session: &jmap.Session{Username: "me@example.com"}},
+		mailboxCache: []*mailbox.Mailbox{
+			{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox},
+			{ID: "mb-sent", Name: "Sent", Role: mailbox.RoleSent},
+		},
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			switch args := req.Calls[0].Args.(type) {
+			case *email.Query:
+				return scanPage(inbox), nil
+			case *thread.Get:
+				var list []*email.Email
+				for _, id := range args.IDs {
+					for _, e := range all {
+						if e.ThreadID == id {
+							list = append(list, e)
+						}
+					}
+				}
+				return &jmap.Response{Responses: []*jmap.Invocation{
+					{Name: "Thread/get", CallID: "0", Args: &thread.GetResponse{}},
+					{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: list}},
+				}}, nil
+			}
+			t.Fatalf("unexpected request: %s", req.Calls[0].Name)
+			return nil, nil
+		},
+	}
+}
+
+func TestUnanswered(t *testing.T) {
+	me := []*mail.Address{{Email: "me@example.com"}}
+	alice := []*mail.Address{{Email: "alice@example.com", Name: "Alice"}}
+	bob := []*mail.Address{{Email: "bob@example.org"}}
+	inbox := map[jmap.ID]bool{"mb-inbox": true}
+	sent := map[jmap.ID]bool{"mb-sent": true}
+	list := []*email.Header{{Name: "List-Unsubscribe", Value: "<https://news.example.com/u>"}}
+
+	all := []*email.Email{
+		// T1: alice wrote, I replied, alice wrote again: unanswered.
+		{ID: "A1", ThreadID: "T1", MailboxIDs: inbox, From: alice, Subject: "Plans", ReceivedAt: received("2026-03-01T10:00:00Z")},
+		{ID: "S1", ThreadID: "T1", MailboxIDs: sent, From: me, Subject: "Re: Plans", ReceivedAt: received("2026-03-01T12:00:00Z")},
+		{ID: "A2", ThreadID: "T1", MailboxIDs: inbox, From: alice, Subject: "Re: Plans", ReceivedAt: received("2026-03-02T09:00:00Z")},
+		// T2: bob wrote, I replied: answered.
+		{ID: "B1", ThreadID: "T2", MailboxIDs: inbox, From: bob, Subject: "Invoice", ReceivedAt: received("2026-03-01T08:00:00Z")},
+		{ID: "S2", ThreadID: "T2", MailboxIDs: sent, From: me, Subject: "Re: Invoice", ReceivedAt: received("2026-03-01T09:00:00Z")},
+		// T3: replied from another client, which set $answered.
+		{ID: "B2", ThreadID: "T3", MailboxIDs: inbox, From: bob, Subject: "Question", Keywords: map[string]bool{"$answered": true}, ReceivedAt: received("2026-03-03T08:00:00Z")},
+		// T4: a newsletter.
+		{ID: "N1", ThreadID: "T4", MailboxIDs: inbox, From: bob, Subject: "News", Headers: list, ReceivedAt: received("2026-03-03T09:00:00Z")},
+		// T5: only an unsent draft reply: unanswered.
+		{ID: "B3", ThreadID: "T5", MailboxIDs: inbox, From: bob, Subject: "Lunch", Keywords: map[string]bool{"$seen": true}, ReceivedAt: received("2026-02-20T08:00:00Z")},
+		{ID: "D1", ThreadID: "T5", MailboxIDs: map[jmap.ID]bool{"mb-drafts": true}, From: me, Keywords: map[string]bool{"$draft": true}, ReceivedAt: received("2026-02-21T08:00:00Z")},
+		// T6: alice wrote again recently, so the thread is not yet overdue.
+		{ID: "A3", ThreadID: "T6", MailboxIDs: inbox, From: alice, Subject: "Trip", ReceivedAt: received("2026-03-01T08:00:00Z")},
+		{ID: "A4", ThreadID: "T6", MailboxIDs: map[jmap.ID]bool{"mb-archive": true}, From: alice, Subject: "Re: Trip", ReceivedAt: received("2026-03-09T08:00:00Z")},
+	}
+	// The query returns inbox messages newest first.
+	query := []*email.Email{all[6], all[5], all[2], all[0], all[9], all[3], all[7]}
+
+	c := unansweredTestClient(t, query, all)
+	result, err := c.Unanswered(UnansweredOptions{
+		MailboxID: "mb-inbox",
+		OlderThan: 3 * 24 * time.Hour,
+		Now:       time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || len(result.Emails) != 2 {
+		t.Fatalf("expected 2 unanswered threads, got %+v", result.Emails)
+	}
+
+	first, second := result.Emails[0], result.Emails[1]
+	if first.ID != "B3" || first.ThreadMessages != 1 || first.LastReplyAt != nil || first.WaitingDays != 18 || first.IsUnread {
+		t.Errorf("unexpected first thread: %+v", first)
+	}
+	if second.ID != "A2" || second.ThreadMessages != 3 || second.WaitingDays != 8 {
+		t.Errorf("unexpected second thread: %+v", second)
+	}
+	if second.LastReplyAt == nil || !second.LastReplyAt.Equal(*received("2026-03-01T12:00:00Z")) {
+		t.Errorf("expected the last reply time, got %v", second.LastReplyAt)
+	}
+
+	limited, err := c.Unanswered(UnansweredOptions{
+		MailboxID: "mb-inbox",
+		OlderThan: 3 * 24 * time.Hour,
+		Now:       time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC),
+		Limit:     1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limited.Total != 2 || len(limited.Emails) != 1 || limited.Emails[0].ID != "B3" {
+		t.Errorf("unexpected limited result: %+v", limited)
+	}
+}
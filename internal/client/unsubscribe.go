@@ -0,0 +1,176 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/types"
+	"github.com/cboone/fm/internal/unsubscribe"
+)
+
+// Unsubscribe methods, in the order they are preferred.
+const (
+	UnsubscribeOneClick = "one_click"
+	UnsubscribeMailto   = "mailto"
+)
+
+// ErrNoUnsubscribe indicates that an email offers no unsubscribe method fm
+// can use.
+var ErrNoUnsubscribe = fmt.Errorf("no usable unsubscribe method")
+
+// unsubscribeProperties are the Email/get properties needed to plan an
+// unsubscribe.
+var unsubscribeProperties = []string{"id", "from", "subject", "headers"}
+
+// PlanUnsubscribe reads the List-Unsubscribe headers of an email and picks
+// how to unsubscribe: an RFC 8058 one-click POST when List-Unsubscribe-Post
+// offers one for an https: URL, otherwise a draft to the mailto: address.
+// Nothing is changed; the plan has Status "would_unsubscribe".
+func (c *Client) PlanUnsubscribe(emailID string) (types.UnsubscribeResult, error) {
+	req := &jmap.Request{}
+	req.Invoke(&email.Get{
+		Account:    c.accountID,
+		IDs:        []jmap.ID{jmap.ID(emailID)},
+		Properties: unsubscribeProperties,
+	})
+	resp, err := c.Do(req)
+	if err != nil {
+		return types.UnsubscribeResult{}, fmt.Errorf("email/get: %w", err)
+	}
+
+	var e *email.Email
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.GetResponse:
+			if len(r.List) == 0 {
+				return types.UnsubscribeResult{}, fmt.Errorf("email %s: %w", emailID, ErrNotFound)
+			}
+			e = r.List[0]
+		case *jmap.MethodError:
+			return types.UnsubscribeResult{}, fmt.Errorf("email/get: %s", r.Error())
+		}
+	}
+	if e == nil {
+		return types.UnsubscribeResult{}, fmt.Errorf("email/get: unexpected response")
+	}
+
+	var listUnsubscribe, listUnsubscribePost, listID string
+	for _, h := range e.Headers {
+		switch strings.ToLower(h.Name) {
+		case "list-unsubscribe":
+			listUnsubscribe = strings.TrimSpace(h.Value)
+		case "list-unsubscribe-post":
+			listUnsubscribePost = strings.TrimSpace(h.Value)
+		case "list-id":
+			listID = listIdentifier(h.Value)
+		}
+	}
+
+	plan := types.UnsubscribeResult{
+		EmailID: string(e.ID),
+		From:    senderAddress(e),
+		Subject: e.Subject,
+		List:    listID,
+		Status:  "would_unsubscribe",
+	}
+	if plan.List == "" {
+		plan.List = plan.From
+	}
+
+	targets := unsubscribe.Parse(listUnsubscribe)
+	switch {
+	case targets.HTTPS != "" && unsubscribe.OneClick(listUnsubscribePost):
+		plan.Method, plan.Target = UnsubscribeOneClick, targets.HTTPS
+	case targets.Mailto != "":
+		plan.Method, plan.Target = UnsubscribeMailto, targets.Mailto
+	case targets.HTTPS != "":
+		return plan, fmt.Errorf("email %s offers only a web page without one-click support (%s): %w", emailID, targets.HTTPS, ErrNoUnsubscribe)
+	case listUnsubscribe == "":
+		return plan, fmt.Errorf("email %s has no List-Unsubscribe header: %w", emailID, ErrNoUnsubscribe)
+	default:
+		return plan, fmt.Errorf("email %s offers no https: or mailto: unsubscribe (%s): %w", emailID, listUnsubscribe, ErrNoUnsubscribe)
+	}
+	return plan, nil
+}
+
+// listIdentifier returns the identifier in angle brackets from a List-Id
+// header (RFC 2919), lowercased, or the whole value when it has none.
+func listIdentifier(value string) string {
+	value = strings.TrimSpace(value)
+	if start := strings.LastIndexByte(value, '<'); start >= 0 {
+		if end := strings.IndexByte(value[start:], '>'); end > 0 {
+			value = value[start+1 : start+end]
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// Unsubscribe carries out a plan from PlanUnsubscribe. A one-click plan
+// sends the POST, which is refused in read-only mode and recorded in the
+// audit log; a mailto plan creates a draft to the unsubscribe address for
+// review and sending from Fastmail.
+func (c *Client) Unsubscribe(plan types.UnsubscribeResult) (types.UnsubscribeResult, error) {
+	switch plan.Method {
+	case UnsubscribeOneClick:
+		status, err := c.postOneClick(plan.EmailID, plan.Target)
+		plan.HTTPStatus = status
+		if err != nil {
+			return plan, err
+		}
+		plan.Status = "unsubscribed"
+		return plan, nil
+
+	case UnsubscribeMailto:
+		m, err := unsubscribe.ParseMailto(plan.Target)
+		if err != nil {
+			return plan, err
+		}
+		to := make([]types.Address, len(m.To))
+		for i, addr := range m.To {
+			to[i] = types.Address{Email: addr}
+		}
+		draft, err := c.CreateDraft(DraftOptions{Mode: DraftModeNew, To: to, Subject: m.Subject, Body: m.Body})
+		if err != nil {
+			return plan, err
+		}
+		plan.Draft = &draft
+		plan.Status = "draft_created"
+		return plan, nil
+	}
+	return plan, fmt.Errorf("unknown unsubscribe method %q", plan.Method)
+}
+
+// postOneClick sends the one-click POST for emailID and records it in the
+// audit log, when one is set.
+func (c *Client) postOneClick(emailID, target string) (int, error) {
+	entry := types.AuditEntry{Method: "List-Unsubscribe-Post", IDs: []string{emailID}, Destination: target}
+	record := func(err error) {
+		if c.audit == nil {
+			return
+		}
+		result := types.AuditResult{ID: emailID, OK: err == nil}
+		if err != nil {
+			result.Error = err.Error()
+			entry.Error = err.Error()
+		}
+		entry.Results = []types.AuditResult{result}
+		c.audit.Record(entry)
+	}
+
+	if c.readOnly {
+		err := &ErrForbidden{Operation: "unsubscribe", Reason: "read-only mode is enabled"}
+		record(err)
+		return 0, err
+	}
+
+	httpClient := c.unsubscribeHTTP
+	if httpClient == nil {
+		httpClient = unsubscribe.NewHTTPClient(unsubscribe.DefaultTimeout)
+	}
+	status, err := unsubscribe.Post(httpClient, target)
+	record(err)
+	return status, err
+}
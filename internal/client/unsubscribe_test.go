@@ -0,0 +1,134 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/audit"
+	"github.com/cboone/fm/internal/types"
+	"github.com/cboone/fm/internal/unsubscribe"
+)
+
+// unsubscribeTestClient answers Email/get with e and creates drafts as
+// "D1".
+func unsubscribeTestClient(e *email.Email) *Client {
+	return testClientForDraft(func(req *jmap.Request) (*jmap.Response, error) {
+		if _, ok := req.Calls[0].Args.(*email.Get); ok {
+			var list []*email.Email
+			if e != nil {
+				list = []*email.Email{e}
+			}
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/get", CallID: "0", Args: &email.GetResponse{List: list}},
+			}}, nil
+		}
+		return mockDraftCreateSuccess("D1")(req)
+	})
+}
+
+func newsletterEmail(headers ...*email.Header) *email.Email {
+	return &email.Email{
+		ID:      "M1",
+		From:    []*mail.Address{{Name: "Shop", Email: "News@Shop.example"}},
+		Subject: "Weekly deals",
+		Headers: headers,
+	}
+}
+
+func TestPlanUnsubscribe(t *testing.T) {
+	oneClick := &email.Header{Name: "List-Unsubscribe-Post", Value: "List-Unsubscribe=One-Click"}
+	both := &email.Header{Name: "List-Unsubscribe", Value: "<mailto:leave@shop.example>, <https://shop.example/u?id=1>"}
+
+	plan, err := unsubscribeTestClient(newsletterEmail(both, oneClick, &email.Header{Name: "List-Id", Value: "Shop Deals <Deals.Shop.example>"})).PlanUnsubscribe("M1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Method != UnsubscribeOneClick || plan.Target != "https://shop.example/u?id=1" || plan.List != "deals.shop.example" || plan.Status != "would_unsubscribe" {
+		t.Errorf("unexpected one-click plan: %+v", plan)
+	}
+
+	plan, err = unsubscribeTestClient(newsletterEmail(both)).PlanUnsubscribe("M1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Method != UnsubscribeMailto || plan.Target != "mailto:leave@shop.example" || plan.List != "news@shop.example" {
+		t.Errorf("expected a mailto plan keyed by sender without one-click, got %+v", plan)
+	}
+
+	for name, e := range map[string]*email.Email{
+		"no header":  newsletterEmail(),
+		"web page":   newsletterEmail(&email.Header{Name: "List-Unsubscribe", Value: "<https://shop.example/u>"}),
+		"plain http": newsletterEmail(&email.Header{Name: "List-Unsubscribe", Value: "<http://shop.example/u>"}, oneClick),
+	} {
+		if _, err := unsubscribeTestClient(e).PlanUnsubscribe("M1"); !errors.Is(err, ErrNoUnsubscribe) {
+			t.Errorf("%s: expected ErrNoUnsubscribe, got %v", name, err)
+		}
+	}
+
+	if _, err := unsubscribeTestClient(nil).PlanUnsubscribe("M1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUnsubscribe_OneClick(t *testing.T) {
+	posts := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := unsubscribeTestClient(nil)
+	c.unsubscribeHTTP = srv.Client()
+	c.unsubscribeHTTP.CheckRedirect = unsubscribe.CheckRedirect
+	logPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	c.SetAuditLog(&audit.Log{Path: logPath})
+
+	plan := newsletterPlan(UnsubscribeOneClick, srv.URL+"/u")
+	result, err := c.Unsubscribe(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "unsubscribed" || result.HTTPStatus != http.StatusOK || posts != 1 {
+		t.Errorf("unexpected result: %+v (posts %d)", result, posts)
+	}
+
+	c.SetReadOnly(true)
+	var forbidden *ErrForbidden
+	if _, err := c.Unsubscribe(plan); !errors.As(err, &forbidden) || posts != 1 {
+		t.Errorf("expected read-only mode to refuse the request, got %v (posts %d)", err, posts)
+	}
+
+	entries, err := audit.Read(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Method != "List-Unsubscribe-Post" || !entries[0].Results[0].OK || entries[1].Error == "" {
+		t.Errorf("unexpected audit entries: %+v", entries)
+	}
+}
+
+func TestUnsubscribe_MailtoDraft(t *testing.T) {
+	c := unsubscribeTestClient(nil)
+	result, err := c.Unsubscribe(newsletterPlan(UnsubscribeMailto, "mailto:leave@shop.example?subject=stop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != "draft_created" || result.Draft == nil || result.Draft.ID != "D1" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Draft.Subject != "stop" || len(result.Draft.To) != 1 || result.Draft.To[0].Email != "leave@shop.example" {
+		t.Errorf("unexpected draft: %+v", result.Draft)
+	}
+}
+
+func newsletterPlan(method, target string) types.UnsubscribeResult {
+	return types.UnsubscribeResult{EmailID: "M1", From: "news@shop.example", List: "news@shop.example", Method: method, Target: target, Status: "would_unsubscribe"}
+}
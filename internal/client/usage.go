@@ -0,0 +1,218 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/jmap/quota"
+	"github.com/cboone/fm/internal/types"
+)
+
+// UsageOptions holds parameters for storage usage aggregation.
+type UsageOptions struct {
+	MailboxID string // restrict to one mailbox; empty scans the whole account
+	Limit     int    // entries to keep per breakdown; 0 keeps all
+	Largest   int    // number of largest messages to list
+}
+
+// usageProperties are the Email/get properties for usage aggregation; the
+// summary properties are needed for the largest-message list.
+var usageProperties = []string{
+	"id", "threadId", "mailboxIds", "from", "to", "subject",
+	"receivedAt", "size", "keywords", "attachments",
+}
+
+// usageBodyProperties are the body part properties read for attachments.
+var usageBodyProperties = []string{"type", "size"}
+
+// hasQuotaCapability checks whether the server advertises quota support.
+func (c *Client) hasQuotaCapability() bool {
+	if c.jmap == nil || c.jmap.Session == nil {
+		return false
+	}
+	_, ok := c.jmap.Session.RawCapabilities[quota.URI]
+	return ok
+}
+
+// StorageUsage adds up message and attachment sizes by mailbox, sender,
+// sender domain, year received, and attachment MIME type, and lists the
+// largest messages. When the server supports quotas, their use and limits
+// are included.
+func (c *Client) StorageUsage(opts UsageOptions) (types.UsageResult, error) {
+	type acc struct {
+		name        string
+		count       int
+		size        uint64
+		attachments uint64
+	}
+	breakdowns := map[string]map[string]*acc{
+		"mailbox": {}, "sender": {}, "domain": {}, "year": {}, "type": {},
+	}
+	add := func(kind, key, name string, count int, size, attachments uint64) {
+		a, ok := breakdowns[kind][key]
+		if !ok {
+			a = &acc{}
+			breakdowns[kind][key] = a
+		}
+		if a.name == "" {
+			a.name = name
+		}
+		a.count += count
+		a.size += size
+		a.attachments += attachments
+	}
+
+	var result types.UsageResult
+	var largest []*email.Email
+
+	var filter email.Filter
+	if opts.MailboxID != "" {
+		filter = &email.FilterCondition{InMailbox: jmap.ID(opts.MailboxID)}
+	}
+	_, err := c.scanEmails("usage query", filter, usageProperties, usageBodyProperties, func(e *email.Email) {
+		var attachments uint64
+		for _, a := range e.Attachments {
+			attachments += a.Size
+			mime := strings.ToLower(a.Type)
+			if mime == "" {
+				mime = "application/octet-stream"
+			}
+			add("type", mime, "", 1, a.Size, a.Size)
+		}
+		result.Total++
+		result.Size += e.Size
+		result.AttachmentSize += attachments
+
+		for id := range e.MailboxIDs {
+			add("mailbox", string(id), "", 1, e.Size, attachments)
+		}
+		if len(e.From) > 0 && e.From[0].Email != "" {
+			addr := strings.ToLower(e.From[0].Email)
+			add("sender", addr, e.From[0].Name, 1, e.Size, attachments)
+			if domain := extractDomain(addr); domain != "" {
+				add("domain", domain, "", 1, e.Size, attachments)
+			}
+		}
+		if e.ReceivedAt != nil {
+			add("year", strconv.Itoa(e.ReceivedAt.UTC().Year()), "", 1, e.Size, attachments)
+		}
+
+		if opts.Largest > 0 {
+			i := sort.Search(len(largest), func(i int) bool { return largest[i].Size < e.Size })
+			if i < opts.Largest {
+				largest = slices.Insert(largest, i, e)
+				if len(largest) > opts.Largest {
+					largest = largest[:opts.Largest]
+				}
+			}
+		}
+	})
+	if err != nil {
+		return types.UsageResult{}, err
+	}
+
+	if len(breakdowns["mailbox"]) > 0 {
+		mailboxes, err := c.GetAllMailboxes()
+		if err != nil {
+			return types.UsageResult{}, err
+		}
+		for _, mb := range mailboxes {
+			if a, ok := breakdowns["mailbox"][string(mb.ID)]; ok {
+				a.name = mb.Name
+			}
+		}
+	}
+
+	stats := func(kind string) []types.UsageStat {
+		out := make([]types.UsageStat, 0, len(breakdowns[kind]))
+		for key, a := range breakdowns[kind] {
+			out = append(out, types.UsageStat{
+				Key:            key,
+				Name:           a.name,
+				Count:          a.count,
+				Size:           a.size,
+				AttachmentSize: a.attachments,
+			})
+		}
+		sort.Slice(out, func(i, j int) bool {
+			if kind == "year" {
+				return out[i].Key > out[j].Key
+			}
+			if out[i].Size != out[j].Size {
+				return out[i].Size > out[j].Size
+			}
+			return out[i].Key < out[j].Key
+		})
+		if opts.Limit > 0 && len(out) > opts.Limit {
+			out = out[:opts.Limit]
+		}
+		return out
+	}
+	result.ByMailbox = stats("mailbox")
+	result.BySender = stats("sender")
+	result.ByDomain = stats("domain")
+	result.ByYear = stats("year")
+	result.ByType = stats("type")
+	result.Largest = convertSummaries(largest)
+
+	if c.hasQuotaCapability() {
+		quotas, err := c.GetQuotas()
+		if err != nil {
+			return types.UsageResult{}, err
+		}
+		result.Quotas = quotas
+	}
+
+	return result, nil
+}
+
+// GetQuotas returns the account's quotas with Quota/get.
+func (c *Client) GetQuotas() ([]types.QuotaInfo, error) {
+	if !c.hasQuotaCapability() {
+		return nil, fmt.Errorf("server does not support quotas (missing %s capability)", quota.URI)
+	}
+
+	req := &jmap.Request{}
+	req.Invoke(&quota.Get{Account: c.accountID})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("quota/get: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *quota.GetResponse:
+			quotas := make([]types.QuotaInfo, 0, len(r.List))
+			for _, q := range r.List {
+				info := types.QuotaInfo{
+					ID:           string(q.ID),
+					Name:         q.Name,
+					ResourceType: q.ResourceType,
+					Scope:        q.Scope,
+					Types:        q.Types,
+					Used:         q.Used,
+					HardLimit:    q.HardLimit,
+					WarnLimit:    q.WarnLimit,
+					SoftLimit:    q.SoftLimit,
+				}
+				if q.HardLimit > 0 {
+					info.PercentUsed = math.Round(float64(q.Used)/float64(q.HardLimit)*1000) / 10
+				}
+				quotas = append(quotas, info)
+			}
+			return quotas, nil
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("quota/get: %s", r.Error())
+		}
+	}
+
+	return nil, fmt.Errorf("quota/get: unexpected response")
+}
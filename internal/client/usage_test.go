@@ -0,0 +1,124 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+
+	"github.com/cboone/fm/internal/jmap/quota"
+)
+
+func usageTestClient(t *testing.T, withQuota bool) *Client {
+	t.Helper()
+	alice := []*mail.Address{{Email: "alice@example.com", Name: "Alice"}}
+	shop := []*mail.Address{{Email: "orders@shop.example.org"}}
+	emails := []*email.Email{
+		{ID: "M1", From: alice, Size: 5000, ReceivedAt: received("2025-06-01T10:00:00Z"),
+			MailboxIDs: map[jmap.ID]bool{"mb-inbox": true},
+			Attachments: []*email.BodyPart{
+				{Type: "image/JPEG", Size: 3000},
+				{Type: "application/pdf", Size: 1000},
+			}},
+		{ID: "M2", From: shop, Size: 800, ReceivedAt: received("2026-01-15T10:00:00Z"),
+			MailboxIDs: map[jmap.ID]bool{"mb-inbox": true, "mb-receipts": true}},
+		{ID: "M3", From: alice, Size: 20000, ReceivedAt: received("2026-02-01T10:00:00Z"),
+			MailboxIDs:  map[jmap.ID]bool{"mb-archive": true},
+			Attachments: []*email.BodyPart{{Type: "image/jpeg", Size: 18000}}},
+	}
+	raw := map[jmap.URI]json.RawMessage{}
+	if withQuota {
+		raw[quota.URI] = json.RawMessage("{}")
+	}
+	return &Client{
+		accountID: "acct-1",
+		jmap:      &jmap.Client{Session: &jmap.Session{RawCapabilities: raw}},
+		mailboxCache: []*mailbox.Mailbox{
+			{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox},
+			{ID: "mb-archive", Name: "Archive", Role: mailbox.RoleArchive},
+			{ID: "mb-receipts", Name: "Receipts"},
+		},
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			if get, ok := req.Calls[0].Args.(*quota.Get); ok {
+				if get.Account != "acct-1" {
+					t.Errorf("unexpected account %q", get.Account)
+				}
+				warn := uint64(4 << 30)
+				return &jmap.Response{Responses: []*jmap.Invocation{
+					{Name: "Quota/get", CallID: "0", Args: &quota.GetResponse{List: []*quota.Quota{
+						{ID: "q1", Name: "Mail", ResourceType: "octets", Scope: "account", Used: 1 << 30, HardLimit: 4 << 30, WarnLimit: &warn},
+					}}},
+				}}, nil
+			}
+			if get := req.Calls[1].Args.(*email.Get); len(get.BodyProperties) == 0 {
+				t.Errorf("expected body properties for attachments")
+			}
+			return scanPage(emails), nil
+		},
+	}
+}
+
+func TestStorageUsage_Breakdowns(t *testing.T) {
+	c := usageTestClient(t, false)
+
+	result, err := c.StorageUsage(UsageOptions{Largest: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 3 || result.Size != 25800 || result.AttachmentSize != 22000 {
+		t.Errorf("unexpected totals: %+v", result)
+	}
+	if result.Quotas != nil {
+		t.Errorf("expected no quotas without the capability, got %+v", result.Quotas)
+	}
+
+	if len(result.ByMailbox) != 3 || result.ByMailbox[0].Name != "Archive" || result.ByMailbox[1].Key != "mb-inbox" || result.ByMailbox[1].Size != 5800 {
+		t.Errorf("unexpected mailbox breakdown: %+v", result.ByMailbox)
+	}
+	if s := result.BySender[0]; s.Key != "alice@example.com" || s.Name != "Alice" || s.Count != 2 || s.AttachmentSize != 22000 {
+		t.Errorf("unexpected sender breakdown: %+v", result.BySender)
+	}
+	if len(result.ByDomain) != 2 || result.ByDomain[1].Key != "shop.example.org" {
+		t.Errorf("unexpected domain breakdown: %+v", result.ByDomain)
+	}
+	if len(result.ByYear) != 2 || result.ByYear[0].Key != "2026" || result.ByYear[0].Size != 20800 {
+		t.Errorf("unexpected year breakdown: %+v", result.ByYear)
+	}
+	if len(result.ByType) != 2 || result.ByType[0].Key != "image/jpeg" || result.ByType[0].Count != 2 || result.ByType[0].Size != 21000 {
+		t.Errorf("unexpected type breakdown: %+v", result.ByType)
+	}
+	if len(result.Largest) != 2 || result.Largest[0].ID != "M3" || result.Largest[1].ID != "M1" {
+		t.Errorf("unexpected largest: %+v", result.Largest)
+	}
+
+	limited, err := c.StorageUsage(UsageOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited.ByMailbox) != 1 || len(limited.Largest) != 0 {
+		t.Errorf("unexpected limited result: %+v", limited)
+	}
+}
+
+func TestStorageUsage_Quota(t *testing.T) {
+	result, err := usageTestClient(t, true).StorageUsage(UsageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Quotas) != 1 {
+		t.Fatalf("expected 1 quota, got %+v", result.Quotas)
+	}
+	q := result.Quotas[0]
+	if q.Name != "Mail" || q.ResourceType != "octets" || q.PercentUsed != 25 || q.WarnLimit == nil {
+		t.Errorf("unexpected quota: %+v", q)
+	}
+}
+
+func TestGetQuotas_Unsupported(t *testing.T) {
+	if _, err := usageTestClient(t, false).GetQuotas(); err == nil {
+		t.Fatal("expected an error without the quota capability")
+	}
+}
@@ -0,0 +1,203 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/vacationresponse"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// vacationSingletonID is the fixed ID of the account's only VacationResponse
+// object (RFC 8621, Section 8).
+const vacationSingletonID = "singleton"
+
+// VacationOptions holds the auto-responder configuration applied by
+// SetVacationResponse. Nil dates and empty strings are sent as null, so the
+// previous configuration is fully replaced.
+type VacationOptions struct {
+	FromDate *time.Time
+	ToDate   *time.Time
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// hasVacationCapability checks whether the server advertises vacation responses.
+func (c *Client) hasVacationCapability() bool {
+	if c.jmap == nil || c.jmap.Session == nil {
+		return false
+	}
+	_, ok := c.jmap.Session.RawCapabilities[vacationresponse.URI]
+	return ok
+}
+
+// requireVacation returns an error if the server does not support vacation responses.
+func (c *Client) requireVacation() error {
+	if !c.hasVacationCapability() {
+		return fmt.Errorf("server does not support vacation responses (missing %s capability)", vacationresponse.URI)
+	}
+	return nil
+}
+
+// GetVacationResponse returns the current auto-responder configuration.
+func (c *Client) GetVacationResponse() (types.VacationResponseInfo, error) {
+	if err := c.requireVacation(); err != nil {
+		return types.VacationResponseInfo{}, err
+	}
+
+	req := &jmap.Request{}
+	req.Invoke(&vacationresponse.Get{
+		Account: c.accountID,
+		IDs:     []jmap.ID{vacationSingletonID},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return types.VacationResponseInfo{}, fmt.Errorf("getting vacation response: %w", err)
+	}
+
+	return vacationFromResponses(resp, "getting vacation response")
+}
+
+// SetVacationResponse enables the auto-responder with the given configuration.
+func (c *Client) SetVacationResponse(opts VacationOptions) (types.VacationResponseInfo, error) {
+	return c.updateVacationResponse(vacationEnablePatch(opts), "setting vacation response")
+}
+
+// DisableVacationResponse turns the auto-responder off, leaving its
+// configuration in place for later reuse.
+func (c *Client) DisableVacationResponse() (types.VacationResponseInfo, error) {
+	return c.updateVacationResponse(jmap.Patch{"isEnabled": false}, "disabling vacation response")
+}
+
+// updateVacationResponse applies a patch to the singleton and reads back the
+// resulting configuration in the same request.
+func (c *Client) updateVacationResponse(patch jmap.Patch, op string) (types.VacationResponseInfo, error) {
+	if err := c.requireVacation(); err != nil {
+		return types.VacationResponseInfo{}, err
+	}
+
+	req := &jmap.Request{}
+	req.Invoke(&vacationresponse.Set{
+		Account: c.accountID,
+		Update:  map[jmap.ID]jmap.Patch{vacationSingletonID: patch},
+	})
+	req.Invoke(&vacationresponse.Get{
+		Account: c.accountID,
+		IDs:     []jmap.ID{vacationSingletonID},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return types.VacationResponseInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *vacationresponse.SetResponse:
+			if setErr, ok := r.NotUpdated[vacationSingletonID]; ok {
+				desc := "unknown error"
+				if setErr.Description != nil {
+					desc = *setErr.Description
+				}
+				return types.VacationResponseInfo{}, fmt.Errorf("%s: %s", op, desc)
+			}
+		case *jmap.MethodError:
+			return types.VacationResponseInfo{}, fmt.Errorf("%s: %s", op, r.Error())
+		}
+	}
+
+	return vacationFromResponses(resp, op)
+}
+
+// vacationFromResponses extracts the singleton from a VacationResponse/get
+// response.
+func vacationFromResponses(resp *jmap.Response, op string) (types.VacationResponseInfo, error) {
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *vacationresponse.GetResponse:
+			if len(r.List) == 0 {
+				return types.VacationResponseInfo{}, fmt.Errorf("vacation response: %w", ErrNotFound)
+			}
+			return convertVacation(r.List[0]), nil
+		case *jmap.MethodError:
+			return types.VacationResponseInfo{}, fmt.Errorf("%s: %s", op, r.Error())
+		}
+	}
+	return types.VacationResponseInfo{}, fmt.Errorf("%s: unexpected response", op)
+}
+
+// vacationEnablePatch builds the VacationResponse/set patch for enabling the
+// auto-responder. Every property is written so stale values do not linger.
+func vacationEnablePatch(opts VacationOptions) jmap.Patch {
+	patch := jmap.Patch{
+		"isEnabled": true,
+		"fromDate":  nil,
+		"toDate":    nil,
+		"subject":   nil,
+		"textBody":  nil,
+		"htmlBody":  nil,
+	}
+	if opts.FromDate != nil {
+		patch["fromDate"] = opts.FromDate.UTC().Format(time.RFC3339)
+	}
+	if opts.ToDate != nil {
+		patch["toDate"] = opts.ToDate.UTC().Format(time.RFC3339)
+	}
+	if opts.Subject != "" {
+		patch["subject"] = opts.Subject
+	}
+	if opts.TextBody != "" {
+		patch["textBody"] = opts.TextBody
+	}
+	if opts.HTMLBody != "" {
+		patch["htmlBody"] = opts.HTMLBody
+	}
+	return patch
+}
+
+// ValidateVacationOptions checks that a vacation configuration is coherent
+// before anything is sent to the server.
+func ValidateVacationOptions(opts VacationOptions) error {
+	if opts.TextBody == "" && opts.HTMLBody == "" {
+		return fmt.Errorf("a text or HTML body is required (--body, --body-stdin, or --html-body)")
+	}
+	if opts.FromDate != nil && opts.ToDate != nil && !opts.ToDate.After(*opts.FromDate) {
+		return fmt.Errorf("--to-date must be after --from-date")
+	}
+	return nil
+}
+
+// VacationPreview returns the configuration SetVacationResponse would apply,
+// for dry-run output.
+func VacationPreview(opts VacationOptions) types.VacationResponseInfo {
+	return types.VacationResponseInfo{
+		IsEnabled: true,
+		FromDate:  opts.FromDate,
+		ToDate:    opts.ToDate,
+		Subject:   opts.Subject,
+		TextBody:  opts.TextBody,
+		HTMLBody:  opts.HTMLBody,
+	}
+}
+
+func convertVacation(v *vacationresponse.VacationResponse) types.VacationResponseInfo {
+	info := types.VacationResponseInfo{
+		IsEnabled: v.IsEnabled,
+		FromDate:  v.FromDate,
+		ToDate:    v.ToDate,
+	}
+	if v.Subject != nil {
+		info.Subject = *v.Subject
+	}
+	if v.TextBody != nil {
+		info.TextBody = *v.TextBody
+	}
+	if v.HTMLBody != nil {
+		info.HTMLBody = *v.HTMLBody
+	}
+	return info
+}
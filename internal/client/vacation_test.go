@@ -0,0 +1,224 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/vacationresponse"
+)
+
+func vacationTestClient(doFunc func(*jmap.Request) (*jmap.Response, error)) *Client {
+	return &Client{
+		jmap: &jmap.Client{
+			Session: &jmap.Session{
+				RawCapabilities: map[jmap.URI]json.RawMessage{
+					vacationresponse.URI: json.RawMessage("{}"),
+				},
+			},
+		},
+		accountID: "acct-1",
+		doFunc:    doFunc,
+	}
+}
+
+func TestGetVacationResponse(t *testing.T) {
+	subject := "Out of office"
+	body := "Back Monday."
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	c := vacationTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		return &jmap.Response{
+			Responses: []*jmap.Invocation{
+				{
+					Name: "VacationResponse/get",
+					Args: &vacationresponse.GetResponse{
+						List: []*vacationresponse.VacationResponse{
+							{ID: "singleton", IsEnabled: true, FromDate: &from, Subject: &subject, TextBody: &body},
+						},
+					},
+				},
+			},
+		}, nil
+	})
+
+	info, err := c.GetVacationResponse()
+	if err != nil {
+		t.Fatalf("GetVacationResponse() error: %v", err)
+	}
+	if !info.IsEnabled {
+		t.Error("GetVacationResponse() IsEnabled = false, want true")
+	}
+	if info.Subject != subject {
+		t.Errorf("GetVacationResponse() Subject = %q, want %q", info.Subject, subject)
+	}
+	if info.TextBody != body {
+		t.Errorf("GetVacationResponse() TextBody = %q, want %q", info.TextBody, body)
+	}
+	if info.FromDate == nil || !info.FromDate.Equal(from) {
+		t.Errorf("GetVacationResponse() FromDate = %v, want %v", info.FromDate, from)
+	}
+	if info.ToDate != nil {
+		t.Errorf("GetVacationResponse() ToDate = %v, want nil", info.ToDate)
+	}
+}
+
+func TestGetVacationResponse_NoCapability(t *testing.T) {
+	c := &Client{
+		jmap: &jmap.Client{
+			Session: &jmap.Session{
+				RawCapabilities: map[jmap.URI]json.RawMessage{},
+			},
+		},
+		accountID: "acct-1",
+	}
+
+	_, err := c.GetVacationResponse()
+	if err == nil {
+		t.Fatal("GetVacationResponse() expected error for missing capability")
+	}
+	if !strings.Contains(err.Error(), "does not support vacation responses") {
+		t.Errorf("GetVacationResponse() error = %q, want capability error", err.Error())
+	}
+}
+
+func TestSetVacationResponse_PatchReplacesAllProperties(t *testing.T) {
+	var patch jmap.Patch
+	subject := "Away"
+	body := "I am away."
+	c := vacationTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		if len(req.Calls) != 2 {
+			t.Fatalf("expected set and get calls, got %d", len(req.Calls))
+		}
+		set, ok := req.Calls[0].Args.(*vacationresponse.Set)
+		if !ok {
+			t.Fatalf("expected *vacationresponse.Set, got %T", req.Calls[0].Args)
+		}
+		patch = set.Update["singleton"]
+		return &jmap.Response{
+			Responses: []*jmap.Invocation{
+				{
+					Name: "VacationResponse/set",
+					Args: &vacationresponse.SetResponse{
+						Updated: map[jmap.ID]*vacationresponse.VacationResponse{"singleton": nil},
+					},
+				},
+				{
+					Name: "VacationResponse/get",
+					Args: &vacationresponse.GetResponse{
+						List: []*vacationresponse.VacationResponse{
+							{ID: "singleton", IsEnabled: true, Subject: &subject, TextBody: &body},
+						},
+					},
+				},
+			},
+		}, nil
+	})
+
+	to := time.Date(2026, 3, 10, 9, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+	info, err := c.SetVacationResponse(VacationOptions{ToDate: &to, Subject: subject, TextBody: body})
+	if err != nil {
+		t.Fatalf("SetVacationResponse() error: %v", err)
+	}
+	if !info.IsEnabled {
+		t.Error("SetVacationResponse() IsEnabled = false, want true")
+	}
+
+	if patch["isEnabled"] != true {
+		t.Errorf("patch isEnabled = %v, want true", patch["isEnabled"])
+	}
+	if patch["toDate"] != "2026-03-10T14:00:00Z" {
+		t.Errorf("patch toDate = %v, want UTC timestamp", patch["toDate"])
+	}
+	for _, key := range []string{"fromDate", "htmlBody"} {
+		v, ok := patch[key]
+		if !ok || v != nil {
+			t.Errorf("patch %s = %v (present=%v), want explicit null", key, v, ok)
+		}
+	}
+	if patch["subject"] != subject {
+		t.Errorf("patch subject = %v, want %q", patch["subject"], subject)
+	}
+}
+
+func TestSetVacationResponse_NotUpdated(t *testing.T) {
+	desc := "toDate is before fromDate"
+	c := vacationTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		return &jmap.Response{
+			Responses: []*jmap.Invocation{
+				{
+					Name: "VacationResponse/set",
+					Args: &vacationresponse.SetResponse{
+						NotUpdated: map[jmap.ID]*jmap.SetError{
+							"singleton": {Type: "invalidProperties", Description: &desc},
+						},
+					},
+				},
+			},
+		}, nil
+	})
+
+	_, err := c.SetVacationResponse(VacationOptions{TextBody: "away"})
+	if err == nil {
+		t.Fatal("SetVacationResponse() expected error for NotUpdated")
+	}
+	if !strings.Contains(err.Error(), desc) {
+		t.Errorf("SetVacationResponse() error = %q, want description", err.Error())
+	}
+}
+
+func TestDisableVacationResponse(t *testing.T) {
+	var patch jmap.Patch
+	subject := "Away"
+	c := vacationTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		patch = req.Calls[0].Args.(*vacationresponse.Set).Update["singleton"]
+		return &jmap.Response{
+			Responses: []*jmap.Invocation{
+				{
+					Name: "VacationResponse/set",
+					Args: &vacationresponse.SetResponse{
+						Updated: map[jmap.ID]*vacationresponse.VacationResponse{"singleton": nil},
+					},
+				},
+				{
+					Name: "VacationResponse/get",
+					Args: &vacationresponse.GetResponse{
+						List: []*vacationresponse.VacationResponse{
+							{ID: "singleton", IsEnabled: false, Subject: &subject},
+						},
+					},
+				},
+			},
+		}, nil
+	})
+
+	info, err := c.DisableVacationResponse()
+	if err != nil {
+		t.Fatalf("DisableVacationResponse() error: %v", err)
+	}
+	if info.IsEnabled {
+		t.Error("DisableVacationResponse() IsEnabled = true, want false")
+	}
+	if info.Subject != subject {
+		t.Errorf("DisableVacationResponse() Subject = %q, want configuration kept", info.Subject)
+	}
+	if len(patch) != 1 || patch["isEnabled"] != false {
+		t.Errorf("patch = %v, want only isEnabled=false", patch)
+	}
+}
+
+func TestValidateVacationOptions(t *testing.T) {
+	from := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	before := from.Add(-time.Hour)
+
+	if err := ValidateVacationOptions(VacationOptions{}); err == nil {
+		t.Error("expected error for missing body")
+	}
+	if err := ValidateVacationOptions(VacationOptions{TextBody: "x", FromDate: &from, ToDate: &before}); err == nil {
+		t.Error("expected error for to-date before from-date")
+	}
+	if err := ValidateVacationOptions(VacationOptions{HTMLBody: "<p>x</p>", FromDate: &before, ToDate: &from}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Package credentials stores API tokens outside the config file: in the
+// desktop keyring through the Secret Service API or, where that is not
+// available, in a file readable only by the current user.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// ErrNotFound is returned when no token is stored for an account.
+var ErrNotFound = errors.New("no stored token")
+
+// Backend names accepted by Open.
+const (
+	BackendAuto          = "auto"
+	BackendSecretService = "secret-service"
+	BackendFile          = "file"
+)
+
+// Store saves one token per account name. Account names are profile names,
+// or "default" when no profile is in use.
+type Store interface {
+	// Backend returns the backend name, for status output.
+	Backend() string
+	Get(account string) (string, error)
+	Set(account, token string) error
+	Delete(account string) error
+}
+
+// Open returns the store for backend. Auto uses the Secret Service when
+// secret-tool is installed and a D-Bus session is running, and the file at
+// path otherwise.
+func Open(backend, path string) (Store, error) {
+	switch backend {
+	case "", BackendAuto:
+		if secretServiceAvailable() {
+			return &SecretService{}, nil
+		}
+		return &FileStore{Path: path}, nil
+	case BackendSecretService:
+		if _, err := exec.LookPath(secretToolCommand); err != nil {
+			return nil, fmt.Errorf("secret-service backend needs %s: %w", secretToolCommand, err)
+		}
+		return &SecretService{}, nil
+	case BackendFile:
+		return &FileStore{Path: path}, nil
+	default:
+		return nil, fmt.Errorf("unknown keyring backend %q (use auto, secret-service, or file)", backend)
+	}
+}
+
+// secretServiceAvailable reports whether the Secret Service can be reached.
+func secretServiceAvailable() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath(secretToolCommand)
+	return err == nil
+}
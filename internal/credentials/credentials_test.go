@@ -0,0 +1,122 @@
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fm", "credentials.json")
+	store := &FileStore{Path: path}
+
+	if _, err := store.Get("default"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() on missing file error = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Set("default", "tok-1"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if err := store.Set("team", "tok-2"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("file mode = %o, want 600", perm)
+		}
+	}
+
+	token, err := store.Get("team")
+	if err != nil || token != "tok-2" {
+		t.Errorf("Get(team) = %q, %v; want tok-2", token, err)
+	}
+
+	if err := store.Delete("default"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if _, err := store.Get("default"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("default"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+	}
+	if token, _ := store.Get("team"); token != "tok-2" {
+		t.Errorf("other account lost after Delete: %q", token)
+	}
+}
+
+// fakeSecretTool writes a secret-tool stand-in that keeps secrets as files
+// named after the account attribute.
+func fakeSecretTool(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+dir="` + dir + `"
+cmd="$1"; shift
+account=""
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --label) shift ;;
+    account) shift; account="$1" ;;
+  esac
+  shift
+done
+case "$cmd" in
+  store) cat > "$dir/$account.secret" ;;
+  lookup) [ -f "$dir/$account.secret" ] || exit 1; cat "$dir/$account.secret" ;;
+  clear) rm -f "$dir/$account.secret" ;;
+  *) echo "unknown command" >&2; exit 2 ;;
+esac
+`
+	path := filepath.Join(dir, "secret-tool")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSecretService(t *testing.T) {
+	store := &SecretService{Command: fakeSecretTool(t)}
+
+	if _, err := store.Get("default"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() error = %v, want ErrNotFound", err)
+	}
+	if err := store.Set("default", "tok-1"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	token, err := store.Get("default")
+	if err != nil || token != "tok-1" {
+		t.Errorf("Get() = %q, %v; want tok-1", token, err)
+	}
+	if err := store.Delete("default"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if err := store.Delete("default"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	store, err := Open(BackendAuto, "creds.json")
+	if err != nil {
+		t.Fatalf("Open(auto) error: %v", err)
+	}
+	if store.Backend() != BackendFile {
+		t.Errorf("Open(auto) without D-Bus = %s, want file", store.Backend())
+	}
+
+	if _, err := Open("vault", "creds.json"); err == nil {
+		t.Error("Open() expected error for unknown backend")
+	}
+}
@@ -0,0 +1,85 @@
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/cboone/fm/internal/statefile"
+)
+
+// FileStore keeps tokens in a JSON file created with mode 0600 inside a
+// directory created with mode 0700.
+type FileStore struct {
+	Path string
+}
+
+type credentialsFile struct {
+	Tokens map[string]string `json:"tokens"`
+}
+
+// Backend implements Store.
+func (f *FileStore) Backend() string { return BackendFile }
+
+// Get implements Store.
+func (f *FileStore) Get(account string) (string, error) {
+	data, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	token, ok := data.Tokens[account]
+	if !ok || token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+// Set implements Store.
+func (f *FileStore) Set(account, token string) error {
+	data, err := f.load()
+	if err != nil {
+		return err
+	}
+	data.Tokens[account] = token
+	return f.save(data)
+}
+
+// Delete implements Store.
+func (f *FileStore) Delete(account string) error {
+	data, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := data.Tokens[account]; !ok {
+		return ErrNotFound
+	}
+	delete(data.Tokens, account)
+	return f.save(data)
+}
+
+func (f *FileStore) load() (credentialsFile, error) {
+	data := credentialsFile{Tokens: map[string]string{}}
+	raw, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return data, fmt.Errorf("reading credentials: %w", err)
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return data, fmt.Errorf("parsing %s: %w", f.Path, err)
+	}
+	if data.Tokens == nil {
+		data.Tokens = map[string]string{}
+	}
+	return data, nil
+}
+
+// save replaces the credentials file with data.
+func (f *FileStore) save(data credentialsFile) error {
+	if err := statefile.WriteJSON(f.Path, data); err != nil {
+		return fmt.Errorf("writing credentials: %w", err)
+	}
+	return nil
+}
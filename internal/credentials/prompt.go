@@ -0,0 +1,28 @@
+package credentials
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadHidden prints prompt to out and reads one line from the terminal on in
+// without echoing it. It fails if in is not a terminal.
+func ReadHidden(in *os.File, out io.Writer, prompt string) (string, error) {
+	restore, err := disableEcho(in)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(out, prompt)
+	line, readErr := bufio.NewReader(in).ReadString('\n')
+	fmt.Fprintln(out)
+	if err := restore(); err != nil {
+		return "", err
+	}
+	if readErr != nil && readErr != io.EOF {
+		return "", readErr
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,82 @@
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// secretToolCommand is the libsecret command-line client, which talks to the
+// Secret Service API (GNOME Keyring, KWallet, KeePassXC) over D-Bus.
+const secretToolCommand = "secret-tool"
+
+// secretServiceName is the service attribute fm's items are stored under.
+const secretServiceName = "fm"
+
+// SecretService stores tokens in the desktop keyring. Command overrides the
+// secret-tool executable, for tests.
+type SecretService struct {
+	Command string
+}
+
+// Backend implements Store.
+func (s *SecretService) Backend() string { return BackendSecretService }
+
+// Get implements Store.
+func (s *SecretService) Get(account string) (string, error) {
+	out, err := s.run("", "lookup", "service", secretServiceName, "account", account)
+	if err != nil {
+		// secret-tool exits 1 without output when nothing matches.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) == 0 {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	token := strings.TrimSpace(out)
+	if token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+// Set implements Store. The token is passed on stdin, never as an argument.
+func (s *SecretService) Set(account, token string) error {
+	_, err := s.run(token, "store", "--label", "fm token ("+account+")",
+		"service", secretServiceName, "account", account)
+	return err
+}
+
+// Delete implements Store.
+func (s *SecretService) Delete(account string) error {
+	if _, err := s.Get(account); err != nil {
+		return err
+	}
+	_, err := s.run("", "clear", "service", secretServiceName, "account", account)
+	return err
+}
+
+func (s *SecretService) run(stdin string, args ...string) (string, error) {
+	command := s.Command
+	if command == "" {
+		command = secretToolCommand
+	}
+	c := exec.Command(command, args...)
+	c.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitErr.Stderr = stderr.Bytes()
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("%s %s: %s: %w", secretToolCommand, args[0], msg, err)
+			}
+		}
+		return "", err
+	}
+	return string(out), nil
+}
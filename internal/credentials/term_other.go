@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package credentials
+
+import (
+	"fmt"
+	"os"
+)
+
+// disableEcho is not supported on this platform.
+func disableEcho(f *os.File) (func() error, error) {
+	return nil, fmt.Errorf("hidden input is not supported on this platform")
+}
@@ -0,0 +1,25 @@
+package credentials
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// disableEcho turns off console echo on f and returns a function that
+// restores the previous mode.
+func disableEcho(f *os.File) (func() error, error) {
+	handle := windows.Handle(f.Fd())
+	var old uint32
+	if err := windows.GetConsoleMode(handle, &old); err != nil {
+		return nil, fmt.Errorf("standard input is not a terminal")
+	}
+	hidden := old&^windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT | windows.ENABLE_LINE_INPUT
+	if err := windows.SetConsoleMode(handle, hidden); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return windows.SetConsoleMode(handle, old)
+	}, nil
+}
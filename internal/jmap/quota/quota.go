@@ -0,0 +1,60 @@
+// Package quota implements the JMAP Quota extension (RFC 9425).
+package quota
+
+import "git.sr.ht/~rockorager/go-jmap"
+
+// URI is the capability identifier for JMAP quotas.
+const URI jmap.URI = "urn:ietf:params:jmap:quota"
+
+func init() {
+	jmap.RegisterCapability(&Capability{})
+	jmap.RegisterMethod("Quota/get", newGetResponse)
+}
+
+// Capability is the JMAP capability object for urn:ietf:params:jmap:quota.
+type Capability struct{}
+
+// URI returns the quota capability URI.
+func (c *Capability) URI() jmap.URI { return URI }
+
+// New returns a new empty Capability instance.
+func (c *Capability) New() jmap.Capability { return &Capability{} }
+
+// Quota is a limit on a resource, such as storage or message count
+// (RFC 9425, Section 4.1). ResourceType is "count" or "octets".
+type Quota struct {
+	ID           jmap.ID  `json:"id,omitempty"`
+	ResourceType string   `json:"resourceType,omitempty"`
+	Used         uint64   `json:"used"`
+	HardLimit    uint64   `json:"hardLimit"`
+	Scope        string   `json:"scope,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	Types        []string `json:"types,omitempty"`
+	WarnLimit    *uint64  `json:"warnLimit,omitempty"`
+	SoftLimit    *uint64  `json:"softLimit,omitempty"`
+	Description  string   `json:"description,omitempty"`
+}
+
+// Get retrieves quotas by ID, or all quotas when IDs is nil.
+// https://datatracker.ietf.org/doc/html/rfc9425#section-4.2
+type Get struct {
+	Account    jmap.ID   `json:"accountId,omitempty"`
+	IDs        []jmap.ID `json:"ids,omitempty"`
+	Properties []string  `json:"properties,omitempty"`
+}
+
+// Name returns the JMAP method name.
+func (m *Get) Name() string { return "Quota/get" }
+
+// Requires returns the capability URIs this method depends on.
+func (m *Get) Requires() []jmap.URI { return []jmap.URI{URI} }
+
+// GetResponse is the server response to a Quota/get request.
+type GetResponse struct {
+	Account  jmap.ID   `json:"accountId,omitempty"`
+	State    string    `json:"state,omitempty"`
+	List     []*Quota  `json:"list,omitempty"`
+	NotFound []jmap.ID `json:"notFound,omitempty"`
+}
+
+func newGetResponse() jmap.MethodResponse { return &GetResponse{} }
@@ -0,0 +1,313 @@
+// Package oauth implements the OAuth 2.0 device authorization grant
+// (RFC 8628) and refresh tokens, for signing in to servers that issue
+// short-lived access tokens instead of long-lived API tokens.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// expirySkew refreshes access tokens slightly before they expire so a token
+// does not run out between the check and the request.
+const expirySkew = 30 * time.Second
+
+// ErrNoRefreshToken is returned when an access token needs replacing but the
+// server did not issue a refresh token.
+var ErrNoRefreshToken = errors.New("no refresh token; run 'fm auth oauth' again")
+
+// Config identifies the authorization server and the client registered
+// with it.
+type Config struct {
+	DeviceAuthURL string
+	TokenURL      string
+	ClientID      string
+	Scopes        []string
+
+	// HTTPClient is used for requests to the authorization server;
+	// http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+// DeviceAuth is the device authorization response: the code the user enters
+// at the verification URI, and the device code to poll with.
+type DeviceAuth struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// Token is an access token with the refresh token that replaces it.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"`
+}
+
+// Expired reports whether the access token has expired or is about to.
+// Tokens without an expiry never expire.
+func (t Token) Expired(now time.Time) bool {
+	return !t.Expiry.IsZero() && !now.Add(expirySkew).Before(t.Expiry)
+}
+
+// Encode returns the token as the JSON stored in the credential store.
+func (t Token) Encode() (string, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Decode parses a stored credential written by Encode. It reports false
+// for anything else, such as a plain API token.
+func Decode(s string) (Token, bool) {
+	if !strings.HasPrefix(s, "{") {
+		return Token{}, false
+	}
+	var t Token
+	if err := json.Unmarshal([]byte(s), &t); err != nil || t.AccessToken == "" {
+		return Token{}, false
+	}
+	return t, true
+}
+
+// Error is an error response from the authorization server.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth: %s: %s", e.Code, e.Description)
+	}
+	return "oauth: " + e.Code
+}
+
+// tokenResponse is the token endpoint's success response.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// StartDeviceAuth asks the authorization server for a device code and the
+// user code to show to the user.
+func StartDeviceAuth(ctx context.Context, cfg Config) (DeviceAuth, error) {
+	form := url.Values{"client_id": {cfg.ClientID}}
+	if len(cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+
+	var da DeviceAuth
+	if err := postForm(ctx, cfg, cfg.DeviceAuthURL, form, &da); err != nil {
+		return DeviceAuth{}, fmt.Errorf("device authorization: %w", err)
+	}
+	if da.DeviceCode == "" || da.UserCode == "" || da.VerificationURI == "" {
+		return DeviceAuth{}, fmt.Errorf("device authorization: incomplete response from server")
+	}
+	return da, nil
+}
+
+// PollToken polls the token endpoint until the user approves or denies the
+// request, the device code expires, or ctx is done. The first poll is
+// immediate; later polls wait da.Interval seconds (5 when unset), longer if
+// the server asks to slow down.
+func PollToken(ctx context.Context, cfg Config, da DeviceAuth) (Token, error) {
+	interval := time.Duration(da.Interval) * time.Second
+	if da.Interval <= 0 {
+		interval = 5 * time.Second
+	}
+	if da.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(da.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	form := url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {da.DeviceCode},
+		"client_id":   {cfg.ClientID},
+	}
+	for {
+		tok, err := requestToken(ctx, cfg, form)
+		if err == nil {
+			return tok, nil
+		}
+
+		var oerr *Error
+		if !errors.As(err, &oerr) {
+			return Token{}, err
+		}
+		switch oerr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return Token{}, err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return Token{}, &Error{Code: "expired_token", Description: "the device code expired before it was approved"}
+			}
+			return Token{}, ctx.Err()
+		}
+	}
+}
+
+// Refresh exchanges a refresh token for a new access token. When the server
+// does not rotate the refresh token, the old one is kept.
+func Refresh(ctx context.Context, cfg Config, refreshToken string) (Token, error) {
+	if refreshToken == "" {
+		return Token{}, ErrNoRefreshToken
+	}
+	tok, err := requestToken(ctx, cfg, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {cfg.ClientID},
+	})
+	if err != nil {
+		return Token{}, fmt.Errorf("refreshing token: %w", err)
+	}
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = refreshToken
+	}
+	return tok, nil
+}
+
+func requestToken(ctx context.Context, cfg Config, form url.Values) (Token, error) {
+	var tr tokenResponse
+	if err := postForm(ctx, cfg, cfg.TokenURL, form, &tr); err != nil {
+		return Token{}, err
+	}
+	if tr.AccessToken == "" {
+		return Token{}, fmt.Errorf("token response has no access_token")
+	}
+	tok := Token{
+		AccessToken:  tr.AccessToken,
+		RefreshToken: tr.RefreshToken,
+		TokenType:    tr.TokenType,
+	}
+	if tr.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return tok, nil
+}
+
+// postForm posts form to endpoint and decodes a JSON success response into
+// v, or returns the server's OAuth error.
+func postForm(ctx context.Context, cfg Config, endpoint string, form url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oerr Error
+		if json.Unmarshal(body, &oerr) == nil && oerr.Code != "" {
+			return &oerr
+		}
+		return fmt.Errorf("%s returned %d", endpoint, resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decoding response from %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// Source hands out the access token, refreshing it when it has expired or
+// the server rejects it, and saves each refreshed token with Save. It is
+// safe for concurrent use.
+type Source struct {
+	Config Config
+	Save   func(Token) error
+
+	mu    sync.Mutex
+	token Token
+}
+
+// NewSource returns a Source starting from tok.
+func NewSource(cfg Config, tok Token, save func(Token) error) *Source {
+	return &Source{Config: cfg, Save: save, token: tok}
+}
+
+// Token returns the current access token, refreshing it first if it has
+// expired.
+func (s *Source) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Expired(time.Now()) {
+		if err := s.refreshLocked(); err != nil {
+			return "", err
+		}
+	}
+	return s.token.AccessToken, nil
+}
+
+// Current returns the token the source holds now, including any refresh.
+func (s *Source) Current() Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// Refresh replaces the access token with a new one from the refresh token.
+func (s *Source) Refresh() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refreshLocked(); err != nil {
+		return "", err
+	}
+	return s.token.AccessToken, nil
+}
+
+func (s *Source) refreshLocked() error {
+	tok, err := Refresh(context.Background(), s.Config, s.token.RefreshToken)
+	if err != nil {
+		return err
+	}
+	s.token = tok
+	if s.Save != nil {
+		if err := s.Save(tok); err != nil {
+			return fmt.Errorf("saving refreshed token: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubServer is a minimal authorization server. The device code is approved
+// after pending polls; refresh tokens are exchanged for "access-N".
+type stubServer struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	pending   int
+	deny      bool
+	polls     int
+	refreshes int
+	forms     []map[string]string
+}
+
+func newStubServer(t *testing.T) *stubServer {
+	t.Helper()
+	s := &stubServer{}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		form := map[string]string{}
+		for k := range r.PostForm {
+			form[k] = r.PostForm.Get(k)
+		}
+		s.forms = append(s.forms, form)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device":
+			json.NewEncoder(w).Encode(map[string]any{
+				"device_code":      "dev-1",
+				"user_code":        "ABCD-EFGH",
+				"verification_uri": "https://auth.example.com/device",
+				"expires_in":       60,
+				"interval":         1,
+			})
+		case "/token":
+			switch r.PostForm.Get("grant_type") {
+			case deviceCodeGrantType:
+				s.polls++
+				if s.deny {
+					w.WriteHeader(http.StatusBadRequest)
+					json.NewEncoder(w).Encode(map[string]string{"error": "access_denied"})
+					return
+				}
+				if s.polls <= s.pending {
+					w.WriteHeader(http.StatusBadRequest)
+					json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]any{
+					"access_token":  "access-0",
+					"refresh_token": "refresh-0",
+					"token_type":    "Bearer",
+					"expires_in":    3600,
+				})
+			case "refresh_token":
+				s.refreshes++
+				json.NewEncoder(w).Encode(map[string]any{
+					"access_token": fmt.Sprintf("access-%d", s.refreshes),
+					"token_type":   "Bearer",
+					"expires_in":   3600,
+				})
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "unsupported_grant_type"})
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *stubServer) config() Config {
+	return Config{
+		DeviceAuthURL: s.server.URL + "/device",
+		TokenURL:      s.server.URL + "/token",
+		ClientID:      "fm-test",
+		Scopes:        []string{"urn:ietf:params:jmap:core", "urn:ietf:params:jmap:mail"},
+	}
+}
+
+func TestDeviceFlow_PendingThenApproved(t *testing.T) {
+	s := newStubServer(t)
+	s.pending = 1
+	cfg := s.config()
+
+	da, err := StartDeviceAuth(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("StartDeviceAuth: %v", err)
+	}
+	if da.UserCode != "ABCD-EFGH" || da.DeviceCode != "dev-1" {
+		t.Fatalf("unexpected device auth: %+v", da)
+	}
+	if got := s.forms[0]["scope"]; got != "urn:ietf:params:jmap:core urn:ietf:params:jmap:mail" {
+		t.Errorf("scope = %q", got)
+	}
+
+	tok, err := PollToken(context.Background(), cfg, da)
+	if err != nil {
+		t.Fatalf("PollToken: %v", err)
+	}
+	if tok.AccessToken != "access-0" || tok.RefreshToken != "refresh-0" {
+		t.Errorf("unexpected token: %+v", tok)
+	}
+	if tok.Expiry.IsZero() || tok.Expired(time.Now()) {
+		t.Errorf("expected an expiry about an hour away, got %v", tok.Expiry)
+	}
+	if s.polls != 2 {
+		t.Errorf("expected 2 polls, got %d", s.polls)
+	}
+}
+
+func TestDeviceFlow_Denied(t *testing.T) {
+	s := newStubServer(t)
+	s.deny = true
+	cfg := s.config()
+
+	_, err := PollToken(context.Background(), cfg, DeviceAuth{DeviceCode: "dev-1", Interval: 1})
+	var oerr *Error
+	if !errors.As(err, &oerr) || oerr.Code != "access_denied" {
+		t.Fatalf("expected access_denied, got %v", err)
+	}
+}
+
+func TestRefresh_KeepsRefreshTokenWhenNotRotated(t *testing.T) {
+	s := newStubServer(t)
+
+	tok, err := Refresh(context.Background(), s.config(), "refresh-0")
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if tok.AccessToken != "access-1" || tok.RefreshToken != "refresh-0" {
+		t.Errorf("unexpected token: %+v", tok)
+	}
+	if s.forms[0]["client_id"] != "fm-test" || s.forms[0]["refresh_token"] != "refresh-0" {
+		t.Errorf("unexpected refresh form: %v", s.forms[0])
+	}
+}
+
+func TestRefresh_NoRefreshToken(t *testing.T) {
+	if _, err := Refresh(context.Background(), Config{}, ""); !errors.Is(err, ErrNoRefreshToken) {
+		t.Fatalf("expected ErrNoRefreshToken, got %v", err)
+	}
+}
+
+func TestSource_RefreshesExpiredTokenAndSaves(t *testing.T) {
+	s := newStubServer(t)
+	var saved []Token
+	src := NewSource(s.config(), Token{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		Expiry:       time.Now().Add(-time.Minute),
+	}, func(tok Token) error {
+		saved = append(saved, tok)
+		return nil
+	})
+
+	got, err := src.Token()
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if got != "access-1" {
+		t.Errorf("expected refreshed token access-1, got %q", got)
+	}
+	if got, _ := src.Token(); got != "access-1" || s.refreshes != 1 {
+		t.Errorf("expected the fresh token to be reused, got %q after %d refreshes", got, s.refreshes)
+	}
+
+	got, err = src.Refresh()
+	if err != nil || got != "access-2" {
+		t.Fatalf("Refresh = %q, %v", got, err)
+	}
+	if len(saved) != 2 || saved[1].AccessToken != "access-2" || saved[1].RefreshToken != "refresh-0" {
+		t.Errorf("unexpected saved tokens: %+v", saved)
+	}
+	if cur := src.Current(); cur != saved[1] {
+		t.Errorf("Current = %+v, want the last saved token %+v", cur, saved[1])
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tok := Token{AccessToken: "a", RefreshToken: "r", Expiry: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)}
+	encoded, err := tok.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := Decode(encoded)
+	if !ok || got.AccessToken != "a" || got.RefreshToken != "r" || !got.Expiry.Equal(tok.Expiry) {
+		t.Errorf("round trip failed: %+v, %v", got, ok)
+	}
+
+	for _, s := range []string{"fmu1-plain-api-token", "{}", "{not json"} {
+		if _, ok := Decode(s); ok {
+			t.Errorf("Decode(%q) reported an OAuth token", s)
+		}
+	}
+}
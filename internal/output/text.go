@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/cboone/fm/internal/types"
 	"github.com/mattn/go-runewidth"
@@ -25,8 +26,16 @@ func (f *TextFormatter) Format(w io.Writer, v any) error {
 	switch val := v.(type) {
 	case types.SessionInfo:
 		return f.formatSession(w, val)
+	case types.ProfileListResult:
+		return f.formatProfileList(w, val)
+	case types.AuthStatus:
+		return f.formatAuthStatus(w, val)
+	case types.AuthResult:
+		return f.formatAuthResult(w, val)
 	case []types.MailboxInfo:
 		return f.formatMailboxes(w, val)
+	case []types.MailboxNode:
+		return f.formatMailboxTree(w, val)
 	case types.EmailListResult:
 		return f.formatEmailList(w, val)
 	case types.EmailDetail:
@@ -49,14 +58,50 @@ func (f *TextFormatter) Format(w io.Writer, v any) error {
 		return f.formatSieveScriptDetail(w, val)
 	case types.SieveCreateResult:
 		return f.formatSieveCreateResult(w, val)
+	case types.SieveUpdateResult:
+		return f.formatSieveUpdateResult(w, val)
 	case types.SieveDeleteResult:
 		return f.formatSieveDeleteResult(w, val)
 	case types.SieveActivateResult:
 		return f.formatSieveActivateResult(w, val)
 	case types.SieveValidateResult:
 		return f.formatSieveValidateResult(w, val)
+	case types.SieveBackupResult:
+		return f.formatSieveBackupResult(w, val)
+	case types.SieveRestoreResult:
+		return f.formatSieveRestoreResult(w, val)
+	case types.SieveDiffResult:
+		return f.formatSieveDiffResult(w, val)
+	case types.SieveSuggestResult:
+		return f.formatSieveSuggestResult(w, val)
 	case types.SieveDryRunResult:
 		return f.formatSieveDryRunResult(w, val)
+	case types.SieveTestResult:
+		return f.formatSieveTestResult(w, val)
+	case types.VacationResponseInfo:
+		return f.formatVacation(w, val)
+	case types.VacationDryRunResult:
+		return f.formatVacationDryRunResult(w, val)
+	case types.AuditLogResult:
+		return f.formatAuditLog(w, val)
+	case types.TrendsResult:
+		return f.formatTrends(w, val)
+	case types.UsageResult:
+		return f.formatUsage(w, val)
+	case types.CorrespondentsResult:
+		return f.formatCorrespondents(w, val)
+	case types.UnansweredResult:
+		return f.formatUnanswered(w, val)
+	case types.TriagePlan:
+		return f.formatTriagePlan(w, val)
+	case types.UnsubscribeResult:
+		return f.formatUnsubscribe(w, val)
+	case types.SnoozeWakeResult:
+		return f.formatSnoozeWake(w, val)
+	case types.DuplicatesResult:
+		return f.formatDuplicates(w, val)
+	case types.BudgetResult:
+		return f.formatBudget(w, val)
 	default:
 		// Fall back to JSON formatter for unknown types.
 		return (&JSONFormatter{}).Format(w, v)
@@ -71,7 +116,60 @@ func (f *TextFormatter) FormatError(w io.Writer, code string, message string, hi
 	return nil
 }
 
+func (f *TextFormatter) formatProfileList(w io.Writer, r types.ProfileListResult) error {
+	if len(r.Profiles) == 0 {
+		fmt.Fprintln(w, "No profiles configured")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tTOKEN\tACCOUNT\tSESSION URL")
+	for _, p := range r.Profiles {
+		marker := " "
+		if p.Active {
+			marker = "*"
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\n", marker, p.Name, p.TokenSource, p.AccountID, p.SessionURL)
+	}
+	return tw.Flush()
+}
+
+func (f *TextFormatter) formatAuthStatus(w io.Writer, s types.AuthStatus) error {
+	if s.Profile != "" {
+		fmt.Fprintf(w, "Profile: %s\n", s.Profile)
+	}
+	fmt.Fprintf(w, "Token source: %s\n", s.Source)
+	if s.Backend != "" {
+		fmt.Fprintf(w, "Keyring backend: %s\n", s.Backend)
+	}
+	if s.Authenticated {
+		fmt.Fprintf(w, "Authenticated: yes (%s)\n", s.Username)
+	} else {
+		fmt.Fprintln(w, "Authenticated: no")
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatAuthResult(w io.Writer, r types.AuthResult) error {
+	switch r.Action {
+	case "login", "oauth":
+		kind := "token"
+		if r.Action == "oauth" {
+			kind = "OAuth tokens"
+		}
+		fmt.Fprintf(w, "Stored %s for %s in %s\n", kind, r.Account, r.Backend)
+		if r.Username != "" {
+			fmt.Fprintf(w, "Username: %s\n", r.Username)
+		}
+	default:
+		fmt.Fprintf(w, "Removed token for %s from %s\n", r.Account, r.Backend)
+	}
+	return nil
+}
+
 func (f *TextFormatter) formatSession(w io.Writer, s types.SessionInfo) error {
+	if s.Profile != "" {
+		fmt.Fprintf(w, "Profile: %s\n", s.Profile)
+	}
 	fmt.Fprintf(w, "Username: %s\n", s.Username)
 	fmt.Fprintf(w, "Capabilities: %s\n", strings.Join(s.Capabilities, ", "))
 	ids := make([]string, 0, len(s.Accounts))
@@ -87,6 +185,9 @@ func (f *TextFormatter) formatSession(w io.Writer, s types.SessionInfo) error {
 		}
 		fmt.Fprintf(w, "Account: %s - %s%s\n", id, acct.Name, personal)
 	}
+	if s.ReadOnly {
+		fmt.Fprintln(w, "Read-only: yes (changes are refused)")
+	}
 	return nil
 }
 
@@ -97,12 +198,49 @@ func (f *TextFormatter) formatMailboxes(w io.Writer, mailboxes []types.MailboxIn
 		if mb.Role != "" {
 			role = fmt.Sprintf("[%s]", mb.Role)
 		}
+		name := mb.Path
+		if name == "" {
+			name = mb.Name
+		}
 		fmt.Fprintf(tw, "%s\t%s\ttotal:%d\tunread:%d\t%s\n",
-			mb.Name, mb.ID, mb.TotalEmails, mb.UnreadEmails, role)
+			name, mb.ID, mb.TotalEmails, mb.UnreadEmails, role)
 	}
 	return tw.Flush()
 }
 
+// mailboxLabel names a mailbox by its full path, or by its name when the
+// path is not known.
+func mailboxLabel(d *types.DestinationInfo) string {
+	if d.Path != "" {
+		return d.Path
+	}
+	return d.Name
+}
+
+// formatMailboxTree indents each mailbox under its parent. A mailbox with
+// children also shows the counts of its whole subtree.
+func (f *TextFormatter) formatMailboxTree(w io.Writer, nodes []types.MailboxNode) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	var walk func([]types.MailboxNode, int)
+	walk = func(nodes []types.MailboxNode, depth int) {
+		for _, mb := range nodes {
+			role := ""
+			if mb.Role != "" {
+				role = fmt.Sprintf("[%s]", mb.Role)
+			}
+			subtree := ""
+			if len(mb.Children) > 0 {
+				subtree = fmt.Sprintf("(with subfolders total:%d unread:%d)", mb.SubtreeTotal, mb.SubtreeUnread)
+			}
+			fmt.Fprintf(tw, "%s%s\t%s\ttotal:%d\tunread:%d\t%s\t%s\n",
+				strings.Repeat("  ", depth), mb.Name, mb.ID, mb.TotalEmails, mb.UnreadEmails, role, subtree)
+			walk(mb.Children, depth+1)
+		}
+	}
+	walk(nodes, 0)
+	return tw.Flush()
+}
+
 func (f *TextFormatter) formatEmailList(w io.Writer, result types.EmailListResult) error {
 	fmt.Fprintf(w, "Total: %d (showing %d from offset %d)\n\n", result.Total, len(result.Emails), result.Offset)
 
@@ -222,8 +360,14 @@ func (f *TextFormatter) formatMoveResult(w io.Writer, r types.MoveResult) error
 	if len(r.Moved) > 0 {
 		fmt.Fprintf(w, "Moved: %s\n", strings.Join(r.Moved, ", "))
 	}
+	if len(r.Snoozed) > 0 {
+		fmt.Fprintf(w, "Snoozed: %s\n", strings.Join(r.Snoozed, ", "))
+	}
+	if r.Until != nil {
+		fmt.Fprintf(w, "Until: %s\n", r.Until.Local().Format("2006-01-02 15:04"))
+	}
 	if r.Destination != nil {
-		fmt.Fprintf(w, "Destination: %s (%s)\n", r.Destination.Name, r.Destination.ID)
+		fmt.Fprintf(w, "Destination: %s (%s)\n", mailboxLabel(r.Destination), r.Destination.ID)
 	}
 	if len(r.Errors) > 0 {
 		fmt.Fprintf(w, "Errors:\n")
@@ -283,7 +427,7 @@ func (f *TextFormatter) formatDryRunResult(w io.Writer, r types.DryRunResult) er
 	}
 
 	if r.Destination != nil {
-		fmt.Fprintf(w, "\nDestination: %s (%s)\n", r.Destination.Name, r.Destination.ID)
+		fmt.Fprintf(w, "\nDestination: %s (%s)\n", mailboxLabel(r.Destination), r.Destination.ID)
 	}
 
 	if len(r.NotFound) > 0 {
@@ -337,10 +481,14 @@ func (f *TextFormatter) formatSummary(w io.Writer, r types.SummaryResult) error
 		}
 		countWidth := len(fmt.Sprintf("%d", maxCount))
 		for _, s := range r.TopSenders {
+			category := ""
+			if s.Category != "" {
+				category = "  [" + s.Category + "]"
+			}
 			if s.Name != "" {
-				fmt.Fprintf(w, "%*d  %s  %s\n", countWidth, s.Count, s.Email, s.Name)
+				fmt.Fprintf(w, "%*d  %s  %s%s\n", countWidth, s.Count, s.Email, s.Name, category)
 			} else {
-				fmt.Fprintf(w, "%*d  %s\n", countWidth, s.Count, s.Email)
+				fmt.Fprintf(w, "%*d  %s%s\n", countWidth, s.Count, s.Email, category)
 			}
 			for _, subj := range s.Subjects {
 				fmt.Fprintf(w, "%*s  %s\n", countWidth, "", "  "+subj)
@@ -363,7 +511,7 @@ func (f *TextFormatter) formatSummary(w io.Writer, r types.SummaryResult) error
 	}
 
 	if len(r.Newsletters) > 0 {
-		fmt.Fprintln(w, "\nNewsletters / mailing lists:")
+		fmt.Fprintln(w, "\nNewsletters:")
 		maxCount := 0
 		for _, s := range r.Newsletters {
 			if s.Count > maxCount {
@@ -373,9 +521,9 @@ func (f *TextFormatter) formatSummary(w io.Writer, r types.SummaryResult) error
 		countWidth := len(fmt.Sprintf("%d", maxCount))
 		for _, s := range r.Newsletters {
 			if s.Name != "" {
-				fmt.Fprintf(w, "%*d  %s  %s\n", countWidth, s.Count, s.Email, s.Name)
+				fmt.Fprintf(w, "%*d  %s  %s  (%.2f)\n", countWidth, s.Count, s.Email, s.Name, s.Score)
 			} else {
-				fmt.Fprintf(w, "%*d  %s\n", countWidth, s.Count, s.Email)
+				fmt.Fprintf(w, "%*d  %s  (%.2f)\n", countWidth, s.Count, s.Email, s.Score)
 			}
 			for _, subj := range s.Subjects {
 				fmt.Fprintf(w, "%*s  %s\n", countWidth, "", "  "+subj)
@@ -383,6 +531,17 @@ func (f *TextFormatter) formatSummary(w io.Writer, r types.SummaryResult) error
 		}
 	}
 
+	if len(r.Categories) > 0 {
+		fmt.Fprintln(w, "\nCategories:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, c := range r.Categories {
+			fmt.Fprintf(tw, "  %s\t%d messages\t%d senders\n", c.Category, c.Count, c.Senders)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -398,11 +557,20 @@ func (f *TextFormatter) formatDraftResult(w io.Writer, r types.DraftResult) erro
 	}
 	fmt.Fprintf(w, "Subject: %s\n", r.Subject)
 	if r.Mailbox != nil {
-		fmt.Fprintf(w, "Mailbox: %s (%s)\n", r.Mailbox.Name, r.Mailbox.ID)
+		fmt.Fprintf(w, "Mailbox: %s (%s)\n", mailboxLabel(r.Mailbox), r.Mailbox.ID)
 	}
 	if r.InReplyTo != "" {
 		fmt.Fprintf(w, "In-Reply-To: %s\n", r.InReplyTo)
 	}
+	if r.Replaced != "" {
+		fmt.Fprintf(w, "Replaced: %s\n", r.Replaced)
+	}
+	if r.ArchivedTo != nil {
+		fmt.Fprintf(w, "Previous draft moved to: %s (%s)\n", mailboxLabel(r.ArchivedTo), r.ArchivedTo.ID)
+	}
+	if r.ArchiveError != "" {
+		fmt.Fprintf(w, "Archive error: %s\n", r.ArchiveError)
+	}
 	return nil
 }
 
@@ -465,6 +633,18 @@ func (f *TextFormatter) formatSieveCreateResult(w io.Writer, r types.SieveCreate
 	return nil
 }
 
+func (f *TextFormatter) formatSieveUpdateResult(w io.Writer, r types.SieveUpdateResult) error {
+	active := "no"
+	if r.IsActive {
+		active = "yes"
+	}
+	fmt.Fprintf(w, "Updated sieve script: %s\n", r.ID)
+	fmt.Fprintf(w, "Name: %s\n", r.Name)
+	fmt.Fprintf(w, "Active: %s\n", active)
+	fmt.Fprintf(w, "Blob: %s\n", r.BlobID)
+	return nil
+}
+
 func (f *TextFormatter) formatSieveDeleteResult(w io.Writer, r types.SieveDeleteResult) error {
 	fmt.Fprintf(w, "Deleted sieve script: %s\n", r.ID)
 	return nil
@@ -486,6 +666,90 @@ func (f *TextFormatter) formatSieveValidateResult(w io.Writer, r types.SieveVali
 		fmt.Fprintln(w, "Valid: no")
 		fmt.Fprintf(w, "Error: %s\n", r.Error)
 	}
+	for _, d := range r.Diagnostics {
+		if d.Line > 0 {
+			fmt.Fprintf(w, "  %d:%d: %s: %s\n", d.Line, d.Column, d.Severity, d.Message)
+		} else {
+			fmt.Fprintf(w, "  %s: %s\n", d.Severity, d.Message)
+		}
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatSieveBackupResult(w io.Writer, r types.SieveBackupResult) error {
+	fmt.Fprintf(w, "Backed up %d sieve scripts to %s\n", r.Total, r.Directory)
+	for _, s := range r.Scripts {
+		marker := " "
+		if s.IsActive {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s %s -> %s\n", marker, s.Name, s.File)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatSieveRestoreResult(w io.Writer, r types.SieveRestoreResult) error {
+	if r.DryRun {
+		fmt.Fprintf(w, "Dry run: would restore from %s\n", r.Directory)
+	} else {
+		fmt.Fprintf(w, "Restored from %s\n", r.Directory)
+	}
+	fmt.Fprintf(w, "Created: %d, updated: %d, unchanged: %d\n", r.Created, r.Updated, r.Unchanged)
+	if r.Activated != "" {
+		fmt.Fprintf(w, "Activated: %s\n", r.Activated)
+	}
+	for _, s := range r.Scripts {
+		fmt.Fprintf(w, "  %-9s %s\n", s.Action, s.Name)
+	}
+	for _, s := range r.Scripts {
+		if s.Diff != "" {
+			fmt.Fprintln(w, strings.Repeat("-", 72))
+			fmt.Fprint(w, s.Diff)
+		}
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(w, "Error: %s\n", e)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatSieveDiffResult(w io.Writer, r types.SieveDiffResult) error {
+	if r.Identical {
+		fmt.Fprintf(w, "No differences between script %s and %s\n", r.Script, r.File)
+		return nil
+	}
+	fmt.Fprint(w, r.Diff)
+	return nil
+}
+
+func (f *TextFormatter) formatSieveSuggestResult(w io.Writer, r types.SieveSuggestResult) error {
+	fmt.Fprintf(w, "Scanned: %d messages\n", r.Scanned)
+	if len(r.Suggestions) == 0 {
+		fmt.Fprintln(w, "No rules to suggest")
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SENDER\tACTION\tMATCHED")
+	for _, s := range r.Suggestions {
+		sender := s.From
+		if s.FromDomain != "" {
+			sender = "@" + s.FromDomain
+		}
+		action := s.Action
+		if s.Mailbox != "" {
+			action += " " + s.Mailbox
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d (%.0f%%)\n", sender, action, s.Count, s.Total, s.Confidence*100)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nDry run: would create script")
+	fmt.Fprintln(w, strings.Repeat("-", 72))
+	fmt.Fprint(w, r.Content)
 	return nil
 }
 
@@ -496,7 +760,11 @@ func (f *TextFormatter) formatSieveDryRunResult(w io.Writer, r types.SieveDryRun
 	}
 	fmt.Fprintln(w)
 
-	if r.Content != "" {
+	switch {
+	case r.Diff != "":
+		fmt.Fprintln(w, strings.Repeat("-", 72))
+		fmt.Fprint(w, r.Diff)
+	case r.Content != "":
 		fmt.Fprintln(w, strings.Repeat("-", 72))
 		fmt.Fprint(w, r.Content)
 	}
@@ -512,6 +780,529 @@ func (f *TextFormatter) formatSieveDryRunResult(w io.Writer, r types.SieveDryRun
 	return nil
 }
 
+func (f *TextFormatter) formatSieveTestResult(w io.Writer, r types.SieveTestResult) error {
+	fmt.Fprintf(w, "Script: %s\n", r.Script)
+	fmt.Fprintf(w, "Mailbox: %s\n", r.Mailbox)
+	fmt.Fprintf(w, "Evaluated: %d messages (%d matched a rule)\n", r.Total, r.Matched)
+	if len(r.Unsupported) > 0 {
+		fmt.Fprintf(w, "Not evaluated (treated as false): %s\n", strings.Join(r.Unsupported, ", "))
+	}
+
+	if len(r.Summary) > 0 {
+		fmt.Fprintln(w, "\nSummary:")
+		maxCount := 0
+		for _, s := range r.Summary {
+			if s.Count > maxCount {
+				maxCount = s.Count
+			}
+		}
+		countWidth := len(fmt.Sprintf("%d", maxCount))
+		for _, s := range r.Summary {
+			if s.Destination != "" {
+				fmt.Fprintf(w, "%*d  %s %s\n", countWidth, s.Count, s.Action, s.Destination)
+			} else {
+				fmt.Fprintf(w, "%*d  %s\n", countWidth, s.Count, s.Action)
+			}
+		}
+	}
+
+	if len(r.Messages) > 0 {
+		fmt.Fprintln(w, "\nMessages:")
+		for _, m := range r.Messages {
+			from := ""
+			if len(m.From) > 0 {
+				from = truncate(formatAddr(m.From[0]), maxFromWidth)
+			}
+			fmt.Fprintf(w, "%s  %s  %s  %s\n", m.ID, m.ReceivedAt.Format("2006-01-02 15:04"), from, truncate(m.Subject, maxSubjectWidth))
+
+			rule := "no rule matched"
+			if len(m.Rules) > 0 {
+				lines := make([]string, len(m.Rules))
+				for i, l := range m.Rules {
+					lines[i] = fmt.Sprintf("%d", l)
+				}
+				rule = "line " + strings.Join(lines, ", ")
+			}
+			actions := make([]string, len(m.Actions))
+			for i, a := range m.Actions {
+				actions[i] = formatSieveTestAction(a)
+			}
+			fmt.Fprintf(w, "    %s: %s\n", rule, strings.Join(actions, "; "))
+		}
+	}
+	return nil
+}
+
+func formatSieveTestAction(a types.SieveTestAction) string {
+	s := a.Action
+	if a.Copy {
+		s += " :copy"
+	}
+	if a.Target != "" {
+		s += " " + a.Target
+	}
+	if len(a.Flags) > 0 {
+		s += " [" + strings.Join(a.Flags, " ") + "]"
+	}
+	if a.Implicit {
+		s += " (implicit)"
+	}
+	return s
+}
+
+func (f *TextFormatter) formatVacation(w io.Writer, v types.VacationResponseInfo) error {
+	enabled := "no"
+	if v.IsEnabled {
+		enabled = "yes"
+	}
+	fmt.Fprintf(w, "Enabled: %s\n", enabled)
+	if v.FromDate != nil {
+		fmt.Fprintf(w, "From: %s\n", v.FromDate.Format("2006-01-02 15:04:05 -0700"))
+	}
+	if v.ToDate != nil {
+		fmt.Fprintf(w, "To: %s\n", v.ToDate.Format("2006-01-02 15:04:05 -0700"))
+	}
+	if v.Subject != "" {
+		fmt.Fprintf(w, "Subject: %s\n", v.Subject)
+	}
+	if v.TextBody != "" {
+		fmt.Fprintln(w, strings.Repeat("-", 72))
+		fmt.Fprintln(w, v.TextBody)
+	}
+	if v.HTMLBody != "" {
+		fmt.Fprintln(w, strings.Repeat("-", 72))
+		fmt.Fprintln(w, "HTML body:")
+		fmt.Fprintln(w, v.HTMLBody)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatVacationDryRunResult(w io.Writer, r types.VacationDryRunResult) error {
+	fmt.Fprintf(w, "Dry run: would %s vacation auto-responder\n", r.Operation)
+	if r.Operation == "disable" {
+		return nil
+	}
+	fmt.Fprintln(w)
+	return f.formatVacation(w, r.Vacation)
+}
+
+func (f *TextFormatter) formatAuditLog(w io.Writer, r types.AuditLogResult) error {
+	if len(r.Entries) == 0 {
+		fmt.Fprintln(w, "No audit log entries.")
+		return nil
+	}
+	if len(r.Entries) < r.Total {
+		fmt.Fprintf(w, "Showing the last %d of %d entries\n\n", len(r.Entries), r.Total)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tPROFILE\tOPERATION\tIDS\tDESTINATION\tRESULT")
+	for _, e := range r.Entries {
+		profile := e.Profile
+		if profile == "" {
+			profile = "-"
+		}
+		dest := e.Destination
+		if dest == "" {
+			dest = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Time.Local().Format("2006-01-02 15:04:05"), profile, e.Operation,
+			auditIDList(e.IDs), dest, auditOutcome(e))
+	}
+	return tw.Flush()
+}
+
+func (f *TextFormatter) formatTrends(w io.Writer, r types.TrendsResult) error {
+	fmt.Fprintf(w, "%d emails received %s to %s (trend %+.2f/%s)\n\n", r.Total,
+		r.After.UTC().Format("2006-01-02"), r.Before.UTC().Format("2006-01-02"), r.Slope, r.By)
+
+	maxCount := 0
+	for _, b := range r.Buckets {
+		maxCount = max(maxCount, b.Count)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tCOUNT\t\n", strings.ToUpper(r.By))
+	for _, b := range r.Buckets {
+		label := b.Start.UTC().Format("2006-01-02")
+		if r.By == "month" {
+			label = b.Start.UTC().Format("2006-01")
+		}
+		bar := ""
+		if maxCount > 0 {
+			bar = strings.Repeat("#", (b.Count*40+maxCount-1)/maxCount)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", label, b.Count, bar)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if r.Groups == nil {
+		return nil
+	}
+	fmt.Fprintln(w)
+	if len(r.Groups) == 0 {
+		fmt.Fprintf(w, "No %s groups in this range.\n", r.Group)
+		return nil
+	}
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tTOTAL\tTREND\tVOLUME\n", strings.ToUpper(r.Group))
+	for _, g := range r.Groups {
+		key := g.Key
+		if g.Name != "" {
+			key += " (" + g.Name + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%+.2f\t%s\n", key, g.Total, g.Slope, sparkline(g.Counts))
+	}
+	return tw.Flush()
+}
+
+// sparkline draws counts as a row of block characters scaled to the largest.
+func sparkline(counts []int) string {
+	levels := []rune(" ▁▂▃▄▅▆▇█")
+	peak := 0
+	for _, n := range counts {
+		peak = max(peak, n)
+	}
+	var b strings.Builder
+	for _, n := range counts {
+		i := 0
+		if peak > 0 && n > 0 {
+			i = (n*(len(levels)-1) + peak - 1) / peak
+		}
+		b.WriteRune(levels[i])
+	}
+	return b.String()
+}
+
+func (f *TextFormatter) formatUsage(w io.Writer, r types.UsageResult) error {
+	fmt.Fprintf(w, "%d emails, %s (%s in attachments)\n", r.Total, humanBytes(r.Size), humanBytes(r.AttachmentSize))
+
+	if len(r.Quotas) > 0 {
+		fmt.Fprintln(w, "\nQuotas:")
+		for _, q := range r.Quotas {
+			used, limit := fmt.Sprintf("%d", q.Used), fmt.Sprintf("%d", q.HardLimit)
+			if q.ResourceType == "octets" {
+				used, limit = humanBytes(q.Used), humanBytes(q.HardLimit)
+			}
+			fmt.Fprintf(w, "  %s: %s of %s (%.1f%%)\n", q.Name, used, limit, q.PercentUsed)
+		}
+	}
+
+	for _, section := range []struct {
+		title, column string
+		stats         []types.UsageStat
+	}{
+		{"By mailbox", "MAILBOX", r.ByMailbox},
+		{"By sender", "SENDER", r.BySender},
+		{"By domain", "DOMAIN", r.ByDomain},
+		{"By year", "YEAR", r.ByYear},
+		{"By attachment type", "TYPE", r.ByType},
+	} {
+		if len(section.stats) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "SIZE\tATTACHMENTS\tCOUNT\t%s\n", section.column)
+		for _, st := range section.stats {
+			label := st.Key
+			if st.Name != "" {
+				if section.column == "MAILBOX" {
+					label = st.Name
+				} else {
+					label += " (" + st.Name + ")"
+				}
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", humanBytes(st.Size), humanBytes(st.AttachmentSize), st.Count, label)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Largest) > 0 {
+		fmt.Fprintln(w, "\nLargest messages:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SIZE\tDATE\tFROM\tSUBJECT\tID")
+		for _, e := range r.Largest {
+			from := ""
+			if len(e.From) > 0 {
+				from = truncate(formatAddr(e.From[0]), maxFromWidth)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", humanBytes(e.Size), e.ReceivedAt.Format("2006-01-02"),
+				from, truncate(e.Subject, maxSubjectWidth), e.ID)
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatUnanswered(w io.Writer, r types.UnansweredResult) error {
+	if len(r.Emails) == 0 {
+		fmt.Fprintln(w, "No unanswered threads.")
+		return nil
+	}
+	if len(r.Emails) < r.Total {
+		fmt.Fprintf(w, "Showing %d of %d unanswered threads\n\n", len(r.Emails), r.Total)
+	} else {
+		fmt.Fprintf(w, "%d unanswered threads\n\n", r.Total)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RECEIVED\tWAITING\tFROM\tSUBJECT\tMESSAGES\tID")
+	for _, e := range r.Emails {
+		from := ""
+		if len(e.From) > 0 {
+			from = truncate(formatAddr(e.From[0]), maxFromWidth)
+		}
+		fmt.Fprintf(tw, "%s\t%dd\t%s\t%s\t%d\t%s\n", e.ReceivedAt.Format("2006-01-02"), e.WaitingDays,
+			from, truncate(e.Subject, maxSubjectWidth), e.ThreadMessages, e.ID)
+	}
+	return tw.Flush()
+}
+
+func (f *TextFormatter) formatTriagePlan(w io.Writer, r types.TriagePlan) error {
+	fmt.Fprintf(w, "Scanned: %d unread messages\n", r.Scanned)
+	if len(r.Messages) == 0 {
+		fmt.Fprintln(w, "Nothing to triage")
+		return nil
+	}
+
+	category := ""
+	for _, m := range r.Messages {
+		if m.Triage != category {
+			category = m.Triage
+			for _, c := range r.Counts {
+				if c.Category == category {
+					fmt.Fprintf(w, "\n%s (%d):\n", category, c.Count)
+				}
+			}
+		}
+		from := ""
+		if len(m.From) > 0 {
+			from = truncate(formatAddr(m.From[0]), maxFromWidth)
+		}
+		fmt.Fprintf(w, "  %s  %s  %s\n", m.ID, from, truncate(m.Subject, maxSubjectWidth))
+		for _, reason := range m.Reasons {
+			fmt.Fprintf(w, "      %s\n", reason)
+		}
+	}
+
+	fmt.Fprintln(w, "\nPlan (not applied):")
+	fmt.Fprintln(w, strings.Repeat("-", 72))
+	fmt.Fprint(w, r.Content)
+	return nil
+}
+
+func (f *TextFormatter) formatCorrespondents(w io.Writer, r types.CorrespondentsResult) error {
+	if len(r.Correspondents) == 0 {
+		fmt.Fprintf(w, "No correspondents since %s.\n", r.After.UTC().Format("2006-01-02"))
+		return nil
+	}
+	if len(r.Correspondents) < r.Total {
+		fmt.Fprintf(w, "Showing %d of %d correspondents since %s\n\n", len(r.Correspondents), r.Total, r.After.UTC().Format("2006-01-02"))
+	} else {
+		fmt.Fprintf(w, "%d correspondents since %s\n\n", r.Total, r.After.UTC().Format("2006-01-02"))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CORRESPONDENT\tRECEIVED\tSENT\tLAST CONTACT\tMEDIAN REPLY\tUNANSWERED")
+	for _, p := range r.Correspondents {
+		addr := formatAddr(types.Address{Name: p.Name, Email: p.Email})
+		median := "-"
+		if p.MedianReplySeconds != nil {
+			median = humanDuration(time.Duration(*p.MedianReplySeconds) * time.Second)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%d\n", truncate(addr, maxFromWidth), p.Received, p.Sent,
+			p.LastContact.Format("2006-01-02"), median, len(p.Unanswered))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	header := false
+	for _, p := range r.Correspondents {
+		for _, u := range p.Unanswered {
+			if !header {
+				fmt.Fprintln(w, "\nUnanswered:")
+				header = true
+			}
+			fmt.Fprintf(w, "  %s  %dd  %s  %s  (%s)\n", u.ReceivedAt.Format("2006-01-02"), u.WaitingDays,
+				p.Email, truncate(u.Subject, maxSubjectWidth), u.EmailID)
+		}
+	}
+	return nil
+}
+
+// humanDuration formats d coarsely: 45m, 5h, 3d.
+func humanDuration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// humanBytes formats a byte count with a binary unit: 512 B, 1.5 KB, 3.2 GB.
+func humanBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func (f *TextFormatter) formatSnoozeWake(w io.Writer, r types.SnoozeWakeResult) error {
+	fmt.Fprintf(w, "Due: %d, Processed: %d, Failed: %d\n", r.Due, r.Processed, r.Failed)
+	if len(r.Woken) > 0 {
+		fmt.Fprintf(w, "Woken: %s\n", strings.Join(r.Woken, ", "))
+	}
+	if r.Destination != nil && len(r.Woken) > 0 {
+		fmt.Fprintf(w, "Destination: %s (%s)\n", mailboxLabel(r.Destination), r.Destination.ID)
+	}
+	fmt.Fprintf(w, "Still snoozed: %d", r.Pending)
+	if r.NextWake != nil {
+		fmt.Fprintf(w, " (next due %s)", r.NextWake.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintln(w)
+	if len(r.Errors) > 0 {
+		fmt.Fprintf(w, "Errors:\n")
+		for _, e := range r.Errors {
+			fmt.Fprintf(w, "  - %s\n", e)
+		}
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatDuplicates(w io.Writer, r types.DuplicatesResult) error {
+	fmt.Fprintf(w, "Scanned %d emails: %d groups, %d duplicate copies\n", r.Scanned, r.GroupCount, r.DuplicateCount)
+	if len(r.Groups) < r.GroupCount {
+		fmt.Fprintf(w, "(showing %d of %d groups)\n", len(r.Groups), r.GroupCount)
+	}
+
+	for i, g := range r.Groups {
+		fmt.Fprintf(w, "\n[%d] %s - %s (by %s)\n", i+1, g.From, g.Subject, strings.ReplaceAll(g.MatchedBy, "_", "-"))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		row := func(label string, d types.DuplicateCopy) {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", label, d.ID, d.ReceivedAt.Local().Format("2006-01-02 15:04"), strings.Join(d.Mailboxes, ", "))
+		}
+		row("keep", g.Keep)
+		for _, d := range g.Duplicates {
+			row("copy", d)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if r.Destination != nil {
+		fmt.Fprintf(w, "\nMoved %d to %s (%s)\n", len(r.Moved), mailboxLabel(r.Destination), r.Destination.ID)
+	}
+	if len(r.Errors) > 0 {
+		fmt.Fprintf(w, "Errors:\n")
+		for _, e := range r.Errors {
+			fmt.Fprintf(w, "  - %s\n", e)
+		}
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatUnsubscribe(w io.Writer, r types.UnsubscribeResult) error {
+	switch r.Status {
+	case "already_unsubscribed":
+		fmt.Fprintf(w, "Already unsubscribed from %s", r.List)
+		if r.Previous != nil {
+			fmt.Fprintf(w, " on %s (%s)", r.Previous.Time.Local().Format("2006-01-02"), r.Previous.Method)
+		}
+		fmt.Fprintln(w, "; use --force to unsubscribe again.")
+	case "unsubscribed":
+		fmt.Fprintf(w, "Unsubscribed from %s (HTTP %d).\n", r.List, r.HTTPStatus)
+	case "draft_created":
+		fmt.Fprintf(w, "Unsubscribe draft created for %s. It is NOT sent; review and send it from Fastmail.\n", r.List)
+	default:
+		fmt.Fprintf(w, "Would unsubscribe from %s.\n", r.List)
+	}
+	fmt.Fprintf(w, "Email: %s\n", r.EmailID)
+	fmt.Fprintf(w, "From: %s\n", r.From)
+	fmt.Fprintf(w, "Subject: %s\n", r.Subject)
+	fmt.Fprintf(w, "Method: %s\n", r.Method)
+	fmt.Fprintf(w, "Target: %s\n", r.Target)
+	if r.Draft != nil {
+		fmt.Fprintf(w, "Draft: %s\n", r.Draft.ID)
+	}
+	if r.Previous != nil && r.Status != "already_unsubscribed" {
+		outcome := "succeeded"
+		if !r.Previous.OK {
+			outcome = "failed: " + r.Previous.Error
+		}
+		fmt.Fprintf(w, "Previous attempt: %s, %s\n", r.Previous.Time.Local().Format("2006-01-02 15:04"), outcome)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatBudget(w io.Writer, r types.BudgetResult) error {
+	if !r.Enabled {
+		fmt.Fprintf(w, "No mutation budget is set for profile %s.\n", r.Profile)
+		return nil
+	}
+	fmt.Fprintf(w, "Mutation budget for profile %s\n\n", r.Profile)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WINDOW\tLIMIT\tUSED\tREMAINING\tFREES UP")
+	for _, win := range r.Windows {
+		limit, remaining := "none", "-"
+		if win.Limit > 0 {
+			limit = fmt.Sprintf("%d", win.Limit)
+			remaining = fmt.Sprintf("%d", win.Remaining)
+		}
+		resets := "-"
+		if win.ResetsAt != nil {
+			resets = win.ResetsAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", win.Window, limit, win.Used, remaining, resets)
+	}
+	return tw.Flush()
+}
+
+// auditIDList shows up to three IDs and a count of the rest.
+func auditIDList(ids []string) string {
+	if len(ids) == 0 {
+		return "-"
+	}
+	if len(ids) <= 3 {
+		return strings.Join(ids, ",")
+	}
+	return fmt.Sprintf("%s +%d more", strings.Join(ids[:3], ","), len(ids)-3)
+}
+
+// auditOutcome summarizes an audit entry: dry run, ok, or the failures.
+func auditOutcome(e types.AuditEntry) string {
+	if e.DryRun {
+		return "dry run"
+	}
+	if e.Error != "" {
+		return "failed: " + truncate(e.Error, 60)
+	}
+	failed := 0
+	for _, r := range e.Results {
+		if !r.OK {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return "ok"
+	}
+	return fmt.Sprintf("%d ok, %d failed", len(e.Results)-failed, failed)
+}
+
 // truncate shortens s to maxWidth display columns, replacing the end with
 // "..." if truncation is needed. If maxWidth < 4, it returns s unchanged.
 func truncate(s string, maxWidth int) string {
@@ -36,6 +36,18 @@ func TestTextFormatter_Session(t *testing.T) {
 	if !strings.Contains(out, "urn:ietf:params:jmap:core") {
 		t.Errorf("expected capability in output, got: %s", out)
 	}
+	if strings.Contains(out, "Read-only") {
+		t.Errorf("expected no read-only line, got: %s", out)
+	}
+
+	buf.Reset()
+	s.ReadOnly = true
+	if err := f.Format(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Read-only: yes") {
+		t.Errorf("expected read-only line, got: %s", buf.String())
+	}
 }
 
 func TestTextFormatter_Mailboxes(t *testing.T) {
@@ -63,6 +75,57 @@ func TestTextFormatter_Mailboxes(t *testing.T) {
 	}
 }
 
+func TestTextFormatter_MailboxesShowsPath(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	mailboxes := []types.MailboxInfo{
+		{ID: "mb-r2025", Name: "2025", Path: "Receipts/2025", TotalEmails: 30, ParentID: "mb-receipts"},
+	}
+
+	if err := f.Format(&buf, mailboxes); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := buf.String(); !strings.Contains(out, "Receipts/2025  mb-r2025") {
+		t.Errorf("expected full path in output, got: %s", out)
+	}
+}
+
+func TestTextFormatter_MailboxTree(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	nodes := []types.MailboxNode{
+		{
+			MailboxInfo:   types.MailboxInfo{ID: "mb-receipts", Name: "Receipts", Path: "Receipts", TotalEmails: 10},
+			SubtreeTotal:  40,
+			SubtreeUnread: 2,
+			Children: []types.MailboxNode{{
+				MailboxInfo:   types.MailboxInfo{ID: "mb-r2025", Name: "2025", Path: "Receipts/2025", TotalEmails: 30, UnreadEmails: 2},
+				SubtreeTotal:  30,
+				SubtreeUnread: 2,
+				Children:      []types.MailboxNode{},
+			}},
+		},
+	}
+
+	if err := f.Format(&buf, nodes); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got: %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], "Receipts ") || !strings.Contains(lines[0], "(with subfolders total:40 unread:2)") {
+		t.Errorf("expected Receipts with subtree counts, got: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "  2025 ") || strings.Contains(lines[1], "subfolders") {
+		t.Errorf("expected indented 2025 without subtree counts, got: %q", lines[1])
+	}
+}
+
 func TestTextFormatter_EmailList(t *testing.T) {
 	f := &TextFormatter{}
 	var buf bytes.Buffer
@@ -576,6 +639,245 @@ func TestTextFormatter_DryRunResult_WithNotFound(t *testing.T) {
 	}
 }
 
+func TestTextFormatter_DraftResultEdit(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.DraftResult{
+		ID:         "M-revised",
+		Mode:       "edit",
+		Mailbox:    &types.DestinationInfo{ID: "mb-drafts", Name: "Drafts"},
+		To:         []types.Address{{Email: "alice@example.com"}},
+		Subject:    "Plans",
+		Replaced:   "M-old",
+		ArchivedTo: &types.DestinationInfo{ID: "mb-rev", Name: "Draft Revisions"},
+	}
+
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "Draft created: M-revised") {
+		t.Errorf("expected new draft ID, got: %s", out)
+	}
+	if !strings.Contains(out, "Replaced: M-old") {
+		t.Errorf("expected replaced draft ID, got: %s", out)
+	}
+	if !strings.Contains(out, "Previous draft moved to: Draft Revisions (mb-rev)") {
+		t.Errorf("expected archive destination, got: %s", out)
+	}
+	if strings.Contains(out, "Archive error") {
+		t.Errorf("expected no archive error line, got: %s", out)
+	}
+}
+
+func TestTextFormatter_SieveTestResult(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.SieveTestResult{
+		Script:  "S1",
+		Mailbox: "Inbox",
+		Total:   2,
+		Matched: 1,
+		Summary: []types.SieveTestSummary{
+			{Action: "fileinto", Destination: "Receipts", Count: 1},
+			{Action: "keep", Destination: "INBOX", Count: 1},
+		},
+		Messages: []types.SieveTestMessage{
+			{
+				ID:         "M1",
+				From:       []types.Address{{Email: "orders@shop.example"}},
+				Subject:    "Receipt",
+				ReceivedAt: time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC),
+				Rules:      []int{3},
+				Actions:    []types.SieveTestAction{{Action: "fileinto", Target: "Receipts", Flags: []string{`\Seen`}}},
+			},
+			{
+				ID:         "M2",
+				Subject:    "Hello",
+				ReceivedAt: time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC),
+				Actions:    []types.SieveTestAction{{Action: "keep", Target: "INBOX", Implicit: true}},
+			},
+		},
+	}
+
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Evaluated: 2 messages (1 matched a rule)",
+		"1  fileinto Receipts",
+		"    line 3: fileinto Receipts [\\Seen]",
+		"    no rule matched: keep INBOX (implicit)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_SieveDryRunDiff(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	valid := true
+	r := types.SieveDryRunResult{
+		Operation: "add-rule",
+		Script:    "S1",
+		Content:   "keep;\n\ndiscard;\n",
+		Diff:      "--- a/S1\n+++ b/S1\n@@ -1 +1,3 @@\n keep;\n+\n+discard;\n",
+		Valid:     &valid,
+	}
+
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `Dry run: would add-rule script "S1"`) {
+		t.Errorf("expected dry-run header, got: %s", out)
+	}
+	if !strings.Contains(out, "+discard;\n") {
+		t.Errorf("expected diff in output, got: %s", out)
+	}
+	if strings.Contains(out, "\ndiscard;\n") {
+		t.Errorf("expected diff instead of full content, got: %s", out)
+	}
+	if !strings.Contains(out, "Validation: passed") {
+		t.Errorf("expected validation line, got: %s", out)
+	}
+}
+
+func TestTextFormatter_SieveRestoreResultDryRun(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.SieveRestoreResult{
+		Directory: "backup",
+		DryRun:    true,
+		Created:   1,
+		Updated:   1,
+		Activated: "Main",
+		Scripts: []types.SieveRestoreScript{
+			{Name: "Main", File: "Main.sieve", Action: "create"},
+			{Name: "Lists", ID: "S2", File: "Lists.sieve", Action: "update", Diff: "--- a\n+++ b\n@@ -1 +1 @@\n-keep;\n+stop;\n"},
+		},
+		Errors: []string{},
+	}
+
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Dry run: would restore from backup",
+		"Created: 1, updated: 1, unchanged: 0",
+		"Activated: Main",
+		"  create    Main",
+		"  update    Lists",
+		"+stop;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_SieveSuggestResult(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.SieveSuggestResult{
+		Scanned: 120,
+		Suggestions: []types.SieveSuggestion{
+			{FromDomain: "shop.example", Action: "fileinto", Mailbox: "Receipts", Count: 19, Total: 20, Confidence: 0.95},
+			{From: "spam@bad.example", Action: "junk", Count: 5, Total: 5, Confidence: 1},
+		},
+		Content: "require [\"fileinto\"];\n\nif address :domain :is \"from\" \"shop.example\" {\n",
+	}
+
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Scanned: 120 messages",
+		"@shop.example",
+		"fileinto Receipts",
+		"19/20 (95%)",
+		"spam@bad.example",
+		"Dry run: would create script",
+		"require [\"fileinto\"];",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_SieveUpdateResult(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.SieveUpdateResult{ID: "S1", Name: "Filters", BlobID: "B2", IsActive: true}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"Updated sieve script: S1", "Active: yes", "Blob: B2"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_Vacation(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	to := time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)
+	v := types.VacationResponseInfo{
+		IsEnabled: true,
+		ToDate:    &to,
+		Subject:   "Out of office",
+		TextBody:  "Back on Monday.",
+	}
+
+	if err := f.Format(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"Enabled: yes", "To: 2026-01-04 00:00:00 +0000", "Subject: Out of office", "Back on Monday."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "From:") {
+		t.Errorf("expected no From line without a start date, got: %s", out)
+	}
+}
+
+func TestTextFormatter_VacationDryRunDisable(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	if err := f.Format(&buf, types.VacationDryRunResult{Operation: "disable"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != "Dry run: would disable vacation auto-responder\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
+
 func TestTextFormatter_ErrorWithHint(t *testing.T) {
 	f := &TextFormatter{}
 	var buf bytes.Buffer
@@ -997,3 +1299,323 @@ func TestNew_Empty(t *testing.T) {
 		t.Errorf("expected JSONFormatter for empty string, got %T", f)
 	}
 }
+
+func TestTextFormatter_AuditLog(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.AuditLogResult{
+		Path:  "/tmp/audit.jsonl",
+		Total: 5,
+		Entries: []types.AuditEntry{
+			{Operation: "archive", IDs: []string{"M1", "M2", "M3", "M4"}, Destination: "mb-archive",
+				Results: []types.AuditResult{{ID: "M1", OK: true}, {ID: "M2", OK: true}, {ID: "M3", OK: true}, {ID: "M4", Error: "locked"}}},
+			{Operation: "move", Profile: "agent", IDs: []string{"M5"}, DryRun: true},
+		},
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"last 2 of 5", "M1,M2,M3 +1 more", "3 ok, 1 failed", "dry run", "agent"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_Budget(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.BudgetResult{
+		Profile: "agent",
+		Enabled: true,
+		Windows: []types.BudgetWindow{
+			{Window: "run", Limit: 200, Remaining: 200},
+			{Window: "hour", Used: 12},
+		},
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"profile agent", "REMAINING", "200", "none"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := f.Format(&buf, types.BudgetResult{Profile: "default"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No mutation budget") {
+		t.Errorf("unexpected output for a disabled budget: %s", buf.String())
+	}
+}
+
+func TestTextFormatter_Trends(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	week := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	r := types.TrendsResult{
+		By:     "week",
+		Group:  "sender",
+		After:  week,
+		Before: week.AddDate(0, 0, 14),
+		Total:  6,
+		Slope:  2,
+		Buckets: []types.TrendBucket{
+			{Start: week, Count: 2},
+			{Start: week.AddDate(0, 0, 7), Count: 4},
+		},
+		Groups: []types.TrendGroup{{Key: "a@example.com", Name: "Alice", Total: 6, Counts: []int{2, 4}, Slope: 2}},
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"6 emails received 2026-03-02 to 2026-03-16 (trend +2.00/week)", "2026-03-09  4", "a@example.com (Alice)", "+2.00", "▄█"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_Usage(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.UsageResult{
+		Total:          2,
+		Size:           3 << 20,
+		AttachmentSize: 2 << 20,
+		ByMailbox:      []types.UsageStat{{Key: "mb-archive", Name: "Archive", Count: 2, Size: 3 << 20, AttachmentSize: 2 << 20}},
+		BySender:       []types.UsageStat{{Key: "a@example.com", Name: "Alice", Count: 2, Size: 3 << 20}},
+		Largest: []types.EmailSummary{{ID: "M1", Subject: "Photos", Size: 2 << 20,
+			From: []types.Address{{Email: "a@example.com"}}}},
+		Quotas: []types.QuotaInfo{{Name: "Mail", ResourceType: "octets", Used: 1 << 30, HardLimit: 4 << 30, PercentUsed: 25}},
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"2 emails, 3.0 MB (2.0 MB in attachments)", "Mail: 1.0 GB of 4.0 GB (25.0%)",
+		"Archive", "a@example.com (Alice)", "Largest messages:", "Photos", "M1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	tests := map[uint64]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KB", 5 << 30: "5.0 GB"}
+	for n, want := range tests {
+		if got := humanBytes(n); got != want {
+			t.Errorf("humanBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestTextFormatter_Correspondents(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	median := int64(7200)
+	r := types.CorrespondentsResult{
+		After: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Total: 2,
+		Correspondents: []types.CorrespondentStat{{
+			Email: "alice@example.com", Name: "Alice", Received: 3, Sent: 1,
+			LastContact: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), Replies: 1, MedianReplySeconds: &median,
+			Unanswered: []types.UnansweredThread{{ThreadID: "T1", EmailID: "M9", Subject: "Lunch?",
+				ReceivedAt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), WaitingDays: 8}},
+		}},
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Showing 1 of 2 correspondents since 2026-01-01", "Alice <alice@example.com>", "2h", "Unanswered:", "8d", "Lunch?", "(M9)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_Unanswered(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.UnansweredResult{
+		Total: 3,
+		Emails: []types.UnansweredEmail{{
+			EmailSummary: types.EmailSummary{
+				ID: "M9", From: []types.Address{{Name: "Alice", Email: "alice@example.com"}}, Subject: "Re: Plans",
+				ReceivedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+			},
+			ThreadMessages: 3,
+			WaitingDays:    8,
+		}},
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Showing 1 of 3 unanswered threads", "2026-03-02", "8d", "Alice <alice@example.com>", "Re: Plans", "M9"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := f.Format(&buf, types.UnansweredResult{Emails: []types.UnansweredEmail{}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No unanswered threads.") {
+		t.Errorf("unexpected empty output: %q", buf.String())
+	}
+}
+
+func TestTextFormatter_SummaryCategories(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.SummaryResult{
+		Total:  3,
+		Unread: 1,
+		TopSenders: []types.SenderStat{
+			{Email: "news@shop.example", Count: 2, Category: "newsletter", Score: 0.71},
+			{Email: "alice@example.com", Name: "Alice", Count: 1, Category: "personal", Score: 0.75},
+		},
+		Newsletters: []types.SenderStat{{Email: "news@shop.example", Count: 2, Category: "newsletter", Score: 0.71}},
+		Categories: []types.CategoryStat{
+			{Category: "newsletter", Senders: 1, Count: 2},
+			{Category: "personal", Senders: 1, Count: 1},
+		},
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"alice@example.com  Alice  [personal]", "Newsletters:", "news@shop.example  (0.71)", "Categories:", "newsletter  2 messages  1 senders"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_TriagePlan(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.TriagePlan{
+		Scanned: 1,
+		Counts:  []types.CategoryStat{{Category: "spam_like", Senders: 1, Count: 1}},
+		Messages: []types.TriageMessage{{
+			EmailSummary: types.EmailSummary{ID: "M5", From: []types.Address{{Email: "x@evil.example"}}, Subject: "Verify now"},
+			Triage:       "spam_like",
+			Reasons:      []string{"Authentication-Results: spf=fail"},
+		}},
+		Content: "#!/bin/sh\nfm spam M5  # spam-like signals\n",
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Scanned: 1 unread messages", "spam_like (1):", "M5  x@evil.example  Verify now", "spf=fail", "Plan (not applied):", "fm spam M5"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_Unsubscribe(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	r := types.UnsubscribeResult{
+		EmailID: "M1", From: "news@shop.example", Subject: "Deals", List: "deals.shop.example",
+		Method: "mailto", Target: "mailto:leave@shop.example", Status: "draft_created",
+		Draft: &types.DraftResult{ID: "D1"},
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"draft created for deals.shop.example", "NOT sent", "Target: mailto:leave@shop.example", "Draft: D1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	r = types.UnsubscribeResult{List: "deals.shop.example", Method: "one_click", Status: "already_unsubscribed",
+		Previous: &types.UnsubscribeRecord{Method: "one_click", OK: true}}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Already unsubscribed from deals.shop.example") || !strings.Contains(buf.String(), "--force") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestTextFormatter_SnoozeWake(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	next := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	r := types.SnoozeWakeResult{
+		Due: 2, Processed: 2, Failed: 1, Woken: []string{"M1"},
+		Destination: &types.DestinationInfo{ID: "mb-inbox", Name: "Inbox"},
+		Pending:     3, NextWake: &next, Errors: []string{"M2: notFound"},
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Due: 2, Processed: 2, Failed: 1", "Woken: M1", "Inbox (mb-inbox)", "Still snoozed: 3 (next due", "M2: notFound"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_Duplicates(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	received := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	r := types.DuplicatesResult{
+		Scanned: 10, GroupCount: 2, DuplicateCount: 3,
+		Groups: []types.DuplicateGroup{{
+			MatchedBy: "message_id", From: "billing@example.com", Subject: "Invoice",
+			Keep:       types.DuplicateCopy{ID: "M1", Mailboxes: []string{"Receipts/2025"}, ReceivedAt: received},
+			Duplicates: []types.DuplicateCopy{{ID: "M2", Mailboxes: []string{"Inbox", "Archive"}, ReceivedAt: received}},
+		}},
+		Moved:       []string{"M2"},
+		Destination: &types.DestinationInfo{ID: "mb-dup", Name: "Duplicates", Path: "Duplicates"},
+	}
+	if err := f.Format(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Scanned 10 emails: 2 groups, 3 duplicate copies",
+		"(showing 1 of 2 groups)",
+		"[1] billing@example.com - Invoice (by message-id)",
+		"Receipts/2025",
+		"Inbox, Archive",
+		"Moved 1 to Duplicates (mb-dup)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(out, "  keep  M1") || !strings.Contains(out, "  copy  M2") {
+		t.Errorf("expected keep and copy rows, got:\n%s", out)
+	}
+}
@@ -0,0 +1,112 @@
+// Package sieve parses and lints Sieve filtering scripts (RFC 5228) locally,
+// without a round trip to the server.
+package sieve
+
+import "fmt"
+
+// Position is a 1-based line and column in the script source. Columns count
+// characters, not bytes.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Script is a parsed sieve script: a sequence of top-level commands.
+type Script struct {
+	Commands []*Command
+}
+
+// Command is a single sieve command such as require, if, fileinto, or stop.
+// Block is nil for commands terminated by a semicolon. End is the position
+// of the terminating semicolon or closing brace.
+type Command struct {
+	Name  string
+	Pos   Position
+	End   Position
+	Args  []*Argument
+	Tests []*Test
+	Block []*Command
+}
+
+// HasBlock reports whether the command was followed by a { ... } block.
+func (c *Command) HasBlock() bool {
+	return c.Block != nil
+}
+
+// Test is a sieve test such as header, address, allof, or not. Tests holds
+// nested tests for allof, anyof, and not.
+type Test struct {
+	Name  string
+	Pos   Position
+	Args  []*Argument
+	Tests []*Test
+}
+
+// ArgKind identifies the type of an argument.
+type ArgKind int
+
+// Argument kinds.
+const (
+	ArgString ArgKind = iota
+	ArgStringList
+	ArgNumber
+	ArgTag
+)
+
+// Argument is a positional or tagged argument to a command or test. Strings
+// holds the value of string and string-list arguments, Number the value of
+// numbers after quantifier expansion, and Tag the name of a tag without its
+// leading colon.
+type Argument struct {
+	Kind    ArgKind
+	Pos     Position
+	Strings []string
+	Number  uint64
+	Tag     string
+}
+
+// tagged reports whether args contain the tag with the given name.
+func tagged(args []*Argument, name string) bool {
+	for _, a := range args {
+		if a.Kind == ArgTag && a.Tag == name {
+			return true
+		}
+	}
+	return false
+}
+
+// positional returns the non-tag arguments, skipping the value that follows a
+// tag taking a parameter (such as :comparator "i;octet").
+func positional(args []*Argument) []*Argument {
+	var out []*Argument
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a.Kind == ArgTag {
+			if tagTakesValue[a.Tag] && i+1 < len(args) {
+				i++
+			}
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// tagTakesValue lists tags that consume the following argument.
+var tagTakesValue = map[string]bool{
+	"comparator": true,
+	"count":      true,
+	"value":      true,
+	"flags":      true,
+	"days":       true,
+	"seconds":    true,
+	"subject":    true,
+	"from":       true,
+	"addresses":  true,
+	"handle":     true,
+	"zone":       true,
+}
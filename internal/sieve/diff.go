@@ -0,0 +1,127 @@
+package sieve
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// UnifiedDiff returns a unified diff from a to b with the given file labels,
+// or an empty string when the texts are identical. Scripts are small, so a
+// plain LCS table is used.
+func UnifiedDiff(a, b, fromLabel, toLabel string) string {
+	if a == b {
+		return ""
+	}
+	x, y := splitLines(a), splitLines(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type edit struct {
+		op   byte // ' ', '-', or '+'
+		line string
+		ai   int // index in x of this or the next old line
+		bi   int // index in y of this or the next new line
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			edits = append(edits, edit{' ', x[i], i, j})
+			i++
+			j++
+		case j < len(y) && (i == len(x) || lcs[i][j+1] > lcs[i+1][j]):
+			edits = append(edits, edit{'+', y[j], i, j})
+			j++
+		default:
+			edits = append(edits, edit{'-', x[i], i, j})
+			i++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromLabel, toLabel)
+
+	for start := 0; start < len(edits); {
+		// Find the next change.
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+
+		// Extend the hunk while changes are within 2*context lines.
+		end := start
+		for k := start; k < len(edits); k++ {
+			if edits[k].op != ' ' {
+				end = k
+				continue
+			}
+			if k-end > 2*diffContext {
+				break
+			}
+		}
+
+		lo := max(start-diffContext, 0)
+		for lo < start && edits[lo].op != ' ' {
+			lo++
+		}
+		hi := min(end+diffContext, len(edits)-1)
+
+		oldCount, newCount := 0, 0
+		for _, e := range edits[lo : hi+1] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(edits[lo].ai, oldCount), hunkRange(edits[lo].bi, newCount))
+		for _, e := range edits[lo : hi+1] {
+			fmt.Fprintf(&out, "%c%s\n", e.op, e.line)
+		}
+
+		start = hi + 1
+	}
+
+	return out.String()
+}
+
+// hunkRange formats a hunk's start line and length. An empty range refers to
+// the line before it, as in diff -u.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits text into lines without their terminators.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
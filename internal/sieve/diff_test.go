@@ -0,0 +1,51 @@
+package sieve
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "identical",
+			a:    "keep;\n",
+			b:    "keep;\n",
+			want: "",
+		},
+		{
+			name: "appended rule",
+			a:    "require [\"fileinto\"];\n\nif true {\n    keep;\n}\n",
+			b:    "require [\"fileinto\"];\n\nif true {\n    keep;\n}\n\nif false {\n    stop;\n}\n",
+			want: "--- a\n+++ b\n@@ -3,3 +3,7 @@\n if true {\n     keep;\n }\n+\n+if false {\n+    stop;\n+}\n",
+		},
+		{
+			name: "changed line in the middle",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "a\n1\n2\n3\n4\n5\n6\n7\n8\nb\n",
+			b:    "A\n1\n2\n3\n4\n5\n6\n7\n8\nB\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -7,4 +7,4 @@\n 6\n 7\n 8\n-b\n+B\n",
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "keep;\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+keep;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff(tt.a, tt.b, "a", "b")
+			if got != tt.want {
+				t.Errorf("UnifiedDiff() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,515 @@
+package sieve
+
+import (
+	"mime"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Header is a single message header field. Value is the unfolded, decoded
+// field body.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Message is the part of a message visible to Evaluate: its header fields
+// and size in octets. It carries no flags, as at delivery time; hasflag sees
+// only the flags set by the script itself.
+type Message struct {
+	Headers []Header
+	Size    uint64
+}
+
+// Action is one action taken by a script. Target is the mailbox for fileinto
+// and the address for redirect. Flags are the IMAP flags the stored message
+// would carry.
+type Action struct {
+	Name     string
+	Target   string
+	Copy     bool
+	Flags    []string
+	Implicit bool
+}
+
+// Outcome is the result of running a script against one message.
+type Outcome struct {
+	// Actions in execution order. An implicit keep is appended when no
+	// action cancelled it.
+	Actions []Action
+	// Rules holds the positions of the if, elsif, or else commands whose
+	// blocks ran.
+	Rules []Position
+	// Unsupported lists tests that cannot be evaluated from headers alone
+	// (such as body or envelope); they are treated as false.
+	Unsupported []string
+}
+
+// Evaluate runs a parsed script against a message without side effects.
+func Evaluate(script *Script, msg Message) Outcome {
+	e := &evaluator{
+		msg:         msg,
+		flags:       []string{},
+		implicit:    true,
+		unsupported: map[string]bool{},
+	}
+	e.block(script.Commands)
+
+	if e.implicit {
+		e.out.Actions = append(e.out.Actions, Action{
+			Name:     "keep",
+			Flags:    copyFlags(e.flags),
+			Implicit: true,
+		})
+	}
+	for name := range e.unsupported {
+		e.out.Unsupported = append(e.out.Unsupported, name)
+	}
+	sort.Strings(e.out.Unsupported)
+	return e.out
+}
+
+type evaluator struct {
+	msg         Message
+	flags       []string
+	implicit    bool
+	stopped     bool
+	unsupported map[string]bool
+	out         Outcome
+}
+
+func (e *evaluator) block(commands []*Command) {
+	matched := false
+	for _, cmd := range commands {
+		if e.stopped {
+			return
+		}
+		switch cmd.Name {
+		case "if":
+			matched = e.branch(cmd)
+		case "elsif":
+			if !matched {
+				matched = e.branch(cmd)
+			}
+		case "else":
+			if !matched {
+				e.out.Rules = append(e.out.Rules, cmd.Pos)
+				e.block(cmd.Block)
+			}
+		default:
+			e.action(cmd)
+		}
+	}
+}
+
+// branch runs an if or elsif block when its test holds and reports whether
+// it did.
+func (e *evaluator) branch(cmd *Command) bool {
+	if len(cmd.Tests) != 1 || !e.test(cmd.Tests[0]) {
+		return false
+	}
+	e.out.Rules = append(e.out.Rules, cmd.Pos)
+	e.block(cmd.Block)
+	return true
+}
+
+func (e *evaluator) action(cmd *Command) {
+	args := positional(cmd.Args)
+	first := ""
+	if len(args) > 0 && len(args[0].Strings) > 0 {
+		first = args[0].Strings[0]
+	}
+	isCopy := tagged(cmd.Args, "copy")
+
+	switch cmd.Name {
+	case "stop":
+		e.stopped = true
+	case "keep":
+		e.out.Actions = append(e.out.Actions, Action{Name: "keep", Flags: e.actionFlags(cmd)})
+		e.implicit = false
+	case "discard":
+		e.out.Actions = append(e.out.Actions, Action{Name: "discard"})
+		e.implicit = false
+	case "fileinto":
+		e.out.Actions = append(e.out.Actions, Action{Name: "fileinto", Target: first, Copy: isCopy, Flags: e.actionFlags(cmd)})
+		if !isCopy {
+			e.implicit = false
+		}
+	case "redirect":
+		e.out.Actions = append(e.out.Actions, Action{Name: "redirect", Target: first, Copy: isCopy})
+		if !isCopy {
+			e.implicit = false
+		}
+	case "reject", "ereject":
+		e.out.Actions = append(e.out.Actions, Action{Name: cmd.Name})
+		e.implicit = false
+	case "vacation":
+		e.out.Actions = append(e.out.Actions, Action{Name: "vacation"})
+	case "setflag":
+		e.flags = flagList(args)
+	case "addflag":
+		for _, f := range flagList(args) {
+			if !containsFold(e.flags, f) {
+				e.flags = append(e.flags, f)
+			}
+		}
+	case "removeflag":
+		remove := flagList(args)
+		kept := e.flags[:0]
+		for _, f := range e.flags {
+			if !containsFold(remove, f) {
+				kept = append(kept, f)
+			}
+		}
+		e.flags = kept
+	}
+}
+
+// actionFlags returns the flags for a keep or fileinto: the :flags argument
+// when present, otherwise the current internal flags.
+func (e *evaluator) actionFlags(cmd *Command) []string {
+	for i, a := range cmd.Args {
+		if a.Kind == ArgTag && a.Tag == "flags" && i+1 < len(cmd.Args) {
+			return flagList(cmd.Args[i+1 : i+2])
+		}
+	}
+	return copyFlags(e.flags)
+}
+
+// flagList splits flag arguments on whitespace (RFC 5232, Section 3).
+func flagList(args []*Argument) []string {
+	var flags []string
+	for _, a := range args {
+		if a.Kind == ArgTag {
+			continue
+		}
+		for _, s := range a.Strings {
+			flags = append(flags, strings.Fields(s)...)
+		}
+	}
+	return flags
+}
+
+func copyFlags(flags []string) []string {
+	if len(flags) == 0 {
+		return nil
+	}
+	return append([]string(nil), flags...)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *evaluator) test(t *Test) bool {
+	args := positional(t.Args)
+	switch t.Name {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "not":
+		return len(t.Tests) == 1 && !e.test(t.Tests[0])
+	case "allof":
+		for _, sub := range t.Tests {
+			if !e.test(sub) {
+				return false
+			}
+		}
+		return len(t.Tests) > 0
+	case "anyof":
+		for _, sub := range t.Tests {
+			if e.test(sub) {
+				return true
+			}
+		}
+		return false
+	case "exists":
+		if len(args) < 1 {
+			return false
+		}
+		for _, name := range args[0].Strings {
+			if len(e.headerValues(name)) == 0 {
+				return false
+			}
+		}
+		return true
+	case "size":
+		if len(args) < 1 || args[0].Kind != ArgNumber {
+			return false
+		}
+		if tagged(t.Args, "over") {
+			return e.msg.Size > args[0].Number
+		}
+		if tagged(t.Args, "under") {
+			return e.msg.Size < args[0].Number
+		}
+		return false
+	case "header":
+		if len(args) < 2 {
+			return false
+		}
+		var values []string
+		for _, name := range args[0].Strings {
+			values = append(values, e.headerValues(name)...)
+		}
+		return newMatcher(t.Args).test(values, args[1].Strings)
+	case "address":
+		if len(args) < 2 {
+			return false
+		}
+		var values []string
+		for _, name := range args[0].Strings {
+			for _, v := range e.headerValues(name) {
+				values = append(values, addressParts(v, t.Args)...)
+			}
+		}
+		return newMatcher(t.Args).test(values, args[1].Strings)
+	case "hasflag":
+		// Only flags set earlier in the script count: at delivery time a
+		// message has no flags of its own.
+		if len(args) < 1 {
+			return false
+		}
+		return newMatcher(t.Args).test(e.flags, args[len(args)-1].Strings)
+	default:
+		e.unsupported[t.Name] = true
+		return false
+	}
+}
+
+func (e *evaluator) headerValues(name string) []string {
+	var values []string
+	for _, h := range e.msg.Headers {
+		if strings.EqualFold(h.Name, name) {
+			values = append(values, h.Value)
+		}
+	}
+	return values
+}
+
+// addressParts extracts the requested part (:all, :localpart, or :domain) of
+// each address in a header value. Unparseable values are used whole.
+func addressParts(value string, args []*Argument) []string {
+	var addrs []string
+	if list, err := mail.ParseAddressList(value); err == nil {
+		for _, a := range list {
+			addrs = append(addrs, a.Address)
+		}
+	} else {
+		addrs = []string{strings.TrimSpace(value)}
+	}
+
+	for i, a := range addrs {
+		at := strings.LastIndex(a, "@")
+		switch {
+		case tagged(args, "localpart"):
+			if at >= 0 {
+				addrs[i] = a[:at]
+			}
+		case tagged(args, "domain"):
+			if at >= 0 {
+				addrs[i] = a[at+1:]
+			} else {
+				addrs[i] = ""
+			}
+		}
+	}
+	return addrs
+}
+
+// matcher applies a match type (:is, :contains, :matches, :regex, or the
+// relational :count and :value of RFC 5231) with the comparator given in the
+// arguments.
+type matcher struct {
+	kind       string
+	relation   string
+	comparator string
+}
+
+func newMatcher(args []*Argument) matcher {
+	m := matcher{kind: "is", comparator: "i;ascii-casemap"}
+	for i, a := range args {
+		if a.Kind != ArgTag {
+			continue
+		}
+		switch a.Tag {
+		case "is", "contains", "matches", "regex":
+			m.kind = a.Tag
+		case "count", "value":
+			m.kind = a.Tag
+			if i+1 < len(args) && len(args[i+1].Strings) > 0 {
+				m.relation = strings.ToLower(args[i+1].Strings[0])
+			}
+		case "comparator":
+			if i+1 < len(args) && len(args[i+1].Strings) > 0 {
+				m.comparator = strings.ToLower(args[i+1].Strings[0])
+			}
+		}
+	}
+	return m
+}
+
+// test reports whether any of values matches any of keys. With :count, the
+// number of values is compared with each key instead.
+func (m matcher) test(values, keys []string) bool {
+	if m.kind == "count" {
+		n := strconv.Itoa(len(values))
+		for _, k := range keys {
+			if m.relate(compareNumeric(n, k)) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range values {
+		if m.any(v, keys) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m matcher) any(value string, keys []string) bool {
+	for _, k := range keys {
+		if m.match(value, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m matcher) match(value, key string) bool {
+	caseless := m.comparator != "i;octet"
+	if m.kind == "value" {
+		if m.comparator == "i;ascii-numeric" {
+			return m.relate(compareNumeric(value, key))
+		}
+		if caseless {
+			value, key = strings.ToLower(value), strings.ToLower(key)
+		}
+		return m.relate(strings.Compare(value, key))
+	}
+	if caseless && m.kind != "regex" {
+		value = strings.ToLower(value)
+		key = strings.ToLower(key)
+	}
+	switch m.kind {
+	case "contains":
+		return strings.Contains(value, key)
+	case "matches":
+		return wildcardRegexp(key, caseless).MatchString(value)
+	case "regex":
+		prefix := ""
+		if caseless {
+			prefix = "(?i)"
+		}
+		re, err := regexp.Compile(prefix + key)
+		return err == nil && re.MatchString(value)
+	default:
+		return value == key
+	}
+}
+
+// relate applies the relational match's operator (gt, ge, lt, le, eq, or
+// ne) to the result of a three-way comparison. An unknown operator never
+// matches.
+func (m matcher) relate(cmp int) bool {
+	switch m.relation {
+	case "gt":
+		return cmp > 0
+	case "ge":
+		return cmp >= 0
+	case "lt":
+		return cmp < 0
+	case "le":
+		return cmp <= 0
+	case "eq":
+		return cmp == 0
+	case "ne":
+		return cmp != 0
+	default:
+		return false
+	}
+}
+
+// compareNumeric compares two strings with the i;ascii-numeric comparator
+// (RFC 4790): by the number their leading digits spell, with a string that
+// does not start with a digit counting as positive infinity.
+func compareNumeric(a, b string) int {
+	da, db := leadingDigits(a), leadingDigits(b)
+	switch {
+	case da == "" && db == "":
+		return 0
+	case da == "":
+		return 1
+	case db == "":
+		return -1
+	}
+	da, db = strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+	if len(da) != len(db) {
+		if len(da) < len(db) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(da, db)
+}
+
+func leadingDigits(s string) string {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
+
+// wildcardRegexp converts a :matches pattern (* and ?, with backslash
+// escapes) into an anchored regular expression.
+func wildcardRegexp(pattern string, caseless bool) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	if caseless {
+		b.WriteString("(?i)")
+	}
+	b.WriteString("(?s)")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '*':
+			b.WriteString(".*")
+		case r == '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+var headerDecoder = new(mime.WordDecoder)
+
+// DecodeHeaderValue unfolds a raw header value and decodes RFC 2047 encoded
+// words, as a sieve implementation would before comparing it.
+func DecodeHeaderValue(raw string) string {
+	unfolded := strings.NewReplacer("\r\n", "", "\n", "").Replace(raw)
+	unfolded = strings.TrimSpace(unfolded)
+	if decoded, err := headerDecoder.DecodeHeader(unfolded); err == nil {
+		return decoded
+	}
+	return unfolded
+}
@@ -0,0 +1,123 @@
+package sieve
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mustParse(t *testing.T, src string) *Script {
+	t.Helper()
+	script, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	return script
+}
+
+func testMessage() Message {
+	return Message{
+		Headers: []Header{
+			{Name: "From", Value: "Shop <Orders@Shop.Example>"},
+			{Name: "To", Value: "me@example.com, team@example.com"},
+			{Name: "Subject", Value: "Your receipt #1234"},
+			{Name: "List-Id", Value: "<news.shop.example>"},
+			{Name: "X-Priority", Value: "9"},
+		},
+		Size: 2048,
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		wantActions []Action
+		wantRules   []int
+	}{
+		{
+			name:        "no rules keeps implicitly",
+			src:         "",
+			wantActions: []Action{{Name: "keep", Implicit: true}},
+		},
+		{
+			name:        "address domain match files and stops",
+			src:         "if address :domain :is \"from\" \"shop.example\" {\n  fileinto \"Receipts\";\n  stop;\n}\nfileinto \"Other\";\n",
+			wantActions: []Action{{Name: "fileinto", Target: "Receipts"}},
+			wantRules:   []int{1},
+		},
+		{
+			name:        "elsif chain takes first matching branch",
+			src:         "if header :contains \"subject\" \"invoice\" { discard; }\nelsif header :matches \"subject\" \"your RECEIPT #*\" { keep; }\nelse { fileinto \"X\"; }\n",
+			wantActions: []Action{{Name: "keep"}},
+			wantRules:   []int{2},
+		},
+		{
+			name:        "copy leaves implicit keep and flags carry over",
+			src:         "if allof (exists \"list-id\", size :over 1K) {\n  setflag \"\\\\Seen\";\n  addflag \"$list\";\n  fileinto :copy \"Lists\";\n}\n",
+			wantActions: []Action{{Name: "fileinto", Target: "Lists", Copy: true, Flags: []string{`\Seen`, "$list"}}, {Name: "keep", Implicit: true, Flags: []string{`\Seen`, "$list"}}},
+			wantRules:   []int{1},
+		},
+		{
+			name:        "anyof over address list and not",
+			src:         "if anyof (address :is \"to\" \"nobody@example.com\", not address :localpart :is \"to\" \"sales\") { discard; }\n",
+			wantActions: []Action{{Name: "discard"}},
+			wantRules:   []int{1},
+		},
+		{
+			name:        "hasflag sees only flags the script set",
+			src:         "if hasflag \"\\\\Seen\" { discard; }\naddflag \"$list\";\nif hasflag :is \"$LIST\" { fileinto \"Lists\"; }\n",
+			wantActions: []Action{{Name: "fileinto", Target: "Lists", Flags: []string{"$list"}}},
+			wantRules:   []int{3},
+		},
+		{
+			name:        "relational count compares the number of values",
+			src:         "if address :count \"ge\" :comparator \"i;ascii-numeric\" \"to\" \"2\" { fileinto \"Group\"; }\nif header :count \"gt\" :comparator \"i;ascii-numeric\" \"to\" \"1\" { discard; }\n",
+			wantActions: []Action{{Name: "fileinto", Target: "Group"}},
+			wantRules:   []int{1},
+		},
+		{
+			name:        "relational value compares numerically and by string",
+			src:         "if header :value \"lt\" :comparator \"i;ascii-numeric\" \"x-priority\" \"10\" { fileinto \"Low\"; }\nif header :value \"gt\" \"subject\" \"zzz\" { discard; }\n",
+			wantActions: []Action{{Name: "fileinto", Target: "Low"}},
+			wantRules:   []int{1},
+		},
+		{
+			name:        "no match falls through",
+			src:         "if size :over 10M { discard; }\n",
+			wantActions: []Action{{Name: "keep", Implicit: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Evaluate(mustParse(t, tt.src), testMessage())
+			if !reflect.DeepEqual(out.Actions, tt.wantActions) {
+				t.Errorf("actions = %+v, want %+v", out.Actions, tt.wantActions)
+			}
+			var lines []int
+			for _, p := range out.Rules {
+				lines = append(lines, p.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.wantRules) {
+				t.Errorf("rules = %v, want %v", lines, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestEvaluate_UnsupportedTests(t *testing.T) {
+	out := Evaluate(mustParse(t, "require \"body\";\nif body :contains \"x\" { discard; }\n"), testMessage())
+	if !reflect.DeepEqual(out.Unsupported, []string{"body"}) {
+		t.Errorf("unsupported = %v, want [body]", out.Unsupported)
+	}
+	if len(out.Actions) != 1 || !out.Actions[0].Implicit {
+		t.Errorf("expected implicit keep, got %+v", out.Actions)
+	}
+}
+
+func TestDecodeHeaderValue(t *testing.T) {
+	got := DecodeHeaderValue(" =?UTF-8?Q?Caf=C3=A9?=\r\n news")
+	if got != "Café news" {
+		t.Errorf("DecodeHeaderValue() = %q, want %q", got, "Café news")
+	}
+}
@@ -0,0 +1,323 @@
+package sieve
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity classifies a diagnostic.
+type Severity string
+
+// Diagnostic severities. Errors make a script invalid; warnings flag
+// constructs that are legal but likely mistakes.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a script. Pos is zero for diagnostics that
+// do not belong to a specific location.
+type Diagnostic struct {
+	Severity Severity
+	Pos      Position
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Pos.Line == 0 {
+		return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", d.Pos, d.Severity, d.Message)
+}
+
+// LintOptions configures optional checks.
+type LintOptions struct {
+	// Mailboxes lists the full paths of the account's mailboxes, with "/"
+	// between levels (for example "Receipts/2025"). When non-nil, fileinto
+	// targets are checked against it.
+	Mailboxes []string
+}
+
+// HasErrors reports whether any diagnostic is an error.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Check parses and lints a script. A syntax error is returned as the only
+// diagnostic.
+func Check(src string, opts LintOptions) []Diagnostic {
+	script, err := Parse(src)
+	if err != nil {
+		if perr, ok := err.(*Error); ok {
+			return []Diagnostic{{Severity: SeverityError, Pos: perr.Pos, Message: perr.Msg}}
+		}
+		return []Diagnostic{{Severity: SeverityError, Message: err.Error()}}
+	}
+	return Lint(script, opts)
+}
+
+// commandExtensions maps known commands to the extension that provides them.
+// Core commands map to "".
+var commandExtensions = map[string]string{
+	"require":    "",
+	"if":         "",
+	"elsif":      "",
+	"else":       "",
+	"stop":       "",
+	"keep":       "",
+	"discard":    "",
+	"redirect":   "",
+	"fileinto":   "fileinto",
+	"reject":     "reject",
+	"ereject":    "ereject",
+	"addflag":    "imap4flags",
+	"setflag":    "imap4flags",
+	"removeflag": "imap4flags",
+	"vacation":   "vacation",
+	"set":        "variables",
+	"notify":     "enotify",
+	"include":    "include",
+	"return":     "include",
+	"global":     "include",
+}
+
+// testExtensions maps known tests to the extension that provides them.
+var testExtensions = map[string]string{
+	"address":       "",
+	"allof":         "",
+	"anyof":         "",
+	"exists":        "",
+	"false":         "",
+	"true":          "",
+	"header":        "",
+	"not":           "",
+	"size":          "",
+	"envelope":      "envelope",
+	"body":          "body",
+	"hasflag":       "imap4flags",
+	"date":          "date",
+	"currentdate":   "date",
+	"string":        "variables",
+	"duplicate":     "duplicate",
+	"mailboxexists": "mailbox",
+	"spamtest":      "spamtest",
+	"virustest":     "virustest",
+}
+
+// tagExtensions maps tags to the extension that provides them.
+var tagExtensions = map[string]string{
+	"copy":   "copy",
+	"regex":  "regex",
+	"create": "mailbox",
+	"flags":  "imap4flags",
+	"count":  "relational",
+	"value":  "relational",
+	"mime":   "mime",
+}
+
+// controlCommands take a block rather than ending in a semicolon.
+var controlCommands = map[string]bool{
+	"if":    true,
+	"elsif": true,
+	"else":  true,
+}
+
+type linter struct {
+	opts     LintOptions
+	required map[string]bool
+	reported map[string]bool
+	diags    []Diagnostic
+}
+
+// Lint checks a parsed script for semantic errors and common mistakes.
+func Lint(script *Script, opts LintOptions) []Diagnostic {
+	l := &linter{
+		opts:     opts,
+		required: map[string]bool{},
+		reported: map[string]bool{},
+	}
+
+	seenOther := false
+	for _, cmd := range script.Commands {
+		if cmd.Name != "require" {
+			seenOther = true
+			continue
+		}
+		if seenOther {
+			l.errorf(cmd.Pos, "require must come before all other commands")
+		}
+		for _, arg := range positional(cmd.Args) {
+			for _, ext := range arg.Strings {
+				l.required[ext] = true
+			}
+		}
+	}
+
+	l.block(script.Commands, true)
+	return l.diags
+}
+
+func (l *linter) errorf(pos Position, format string, args ...any) {
+	l.diags = append(l.diags, Diagnostic{Severity: SeverityError, Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) warnf(pos Position, format string, args ...any) {
+	l.diags = append(l.diags, Diagnostic{Severity: SeverityWarning, Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// needs reports a missing require for ext, once per extension.
+func (l *linter) needs(pos Position, ext, what string) {
+	if ext == "" || l.required[ext] || l.reported[ext] {
+		return
+	}
+	l.reported[ext] = true
+	l.warnf(pos, "%s needs require %q", what, ext)
+}
+
+func (l *linter) block(commands []*Command, topLevel bool) {
+	var stopPos *Position
+	unreachableReported := false
+	prev := ""
+
+	for _, cmd := range commands {
+		if stopPos != nil && !unreachableReported {
+			l.warnf(cmd.Pos, "unreachable: %s follows stop on line %d", cmd.Name, stopPos.Line)
+			unreachableReported = true
+		}
+		if cmd.Name == "require" && !topLevel {
+			l.errorf(cmd.Pos, "require is only allowed at the top level")
+		}
+		if (cmd.Name == "elsif" || cmd.Name == "else") && prev != "if" && prev != "elsif" {
+			l.errorf(cmd.Pos, "%s must follow if or elsif", cmd.Name)
+		}
+
+		l.command(cmd)
+
+		if cmd.Name == "stop" && stopPos == nil {
+			pos := cmd.Pos
+			stopPos = &pos
+		}
+		prev = cmd.Name
+	}
+}
+
+func (l *linter) command(cmd *Command) {
+	ext, known := commandExtensions[cmd.Name]
+	if !known {
+		l.errorf(cmd.Pos, "unknown command %q", cmd.Name)
+		return
+	}
+	l.needs(cmd.Pos, ext, cmd.Name)
+	l.tags(cmd.Args)
+
+	if controlCommands[cmd.Name] {
+		switch {
+		case cmd.Name == "else" && len(cmd.Tests) > 0:
+			l.errorf(cmd.Pos, "else does not take a test")
+		case cmd.Name != "else" && len(cmd.Tests) != 1:
+			l.errorf(cmd.Pos, "%s requires exactly one test", cmd.Name)
+		}
+		if !cmd.HasBlock() {
+			l.errorf(cmd.Pos, "%s requires a block", cmd.Name)
+		}
+	} else {
+		if len(cmd.Tests) > 0 {
+			l.errorf(cmd.Pos, "%s does not take a test", cmd.Name)
+		}
+		if cmd.HasBlock() {
+			l.errorf(cmd.Pos, "%s does not take a block", cmd.Name)
+		}
+	}
+
+	for _, t := range cmd.Tests {
+		l.test(t)
+	}
+
+	switch cmd.Name {
+	case "fileinto":
+		l.fileinto(cmd)
+	case "discard":
+		l.warnf(cmd.Pos, "discard silently deletes matching messages; consider fileinto \"Junk\" or a review folder instead, so mistakes can be recovered")
+	}
+
+	if cmd.HasBlock() {
+		l.block(cmd.Block, false)
+	}
+}
+
+func (l *linter) test(t *Test) {
+	ext, known := testExtensions[t.Name]
+	if !known {
+		l.errorf(t.Pos, "unknown test %q", t.Name)
+		return
+	}
+	l.needs(t.Pos, ext, t.Name)
+	l.tags(t.Args)
+
+	switch t.Name {
+	case "allof", "anyof":
+		if len(t.Tests) == 0 {
+			l.errorf(t.Pos, "%s requires a test list", t.Name)
+		}
+	case "not":
+		if len(t.Tests) != 1 {
+			l.errorf(t.Pos, "not requires exactly one test")
+		}
+	default:
+		if len(t.Tests) > 0 {
+			l.errorf(t.Pos, "%s does not take nested tests", t.Name)
+		}
+	}
+
+	for _, sub := range t.Tests {
+		l.test(sub)
+	}
+}
+
+func (l *linter) tags(args []*Argument) {
+	for _, a := range args {
+		if a.Kind != ArgTag {
+			continue
+		}
+		l.needs(a.Pos, tagExtensions[a.Tag], ":"+a.Tag)
+	}
+}
+
+func (l *linter) fileinto(cmd *Command) {
+	args := positional(cmd.Args)
+	if len(args) != 1 || args[0].Kind != ArgString {
+		l.errorf(cmd.Pos, "fileinto requires a single mailbox name")
+		return
+	}
+	if l.opts.Mailboxes == nil || tagged(cmd.Args, "create") {
+		return
+	}
+
+	target := args[0].Strings[0]
+	if strings.EqualFold(target, "INBOX") {
+		return
+	}
+	for _, name := range l.opts.Mailboxes {
+		if name == target {
+			return
+		}
+	}
+	for _, name := range l.opts.Mailboxes {
+		if strings.EqualFold(name, target) {
+			l.warnf(args[0].Pos, "fileinto target %q does not match an existing mailbox (did you mean %q?)", target, name)
+			return
+		}
+	}
+	for _, name := range l.opts.Mailboxes {
+		if i := strings.LastIndex(name, "/"); i >= 0 && strings.EqualFold(name[i+1:], target) {
+			l.warnf(args[0].Pos, "fileinto target %q does not match an existing mailbox (did you mean %q?)", target, name)
+			return
+		}
+	}
+	l.warnf(args[0].Pos, "fileinto target %q does not match an existing mailbox", target)
+}
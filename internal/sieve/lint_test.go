@@ -0,0 +1,117 @@
+package sieve
+
+import (
+	"strings"
+	"testing"
+)
+
+func diagStrings(diags []Diagnostic) []string {
+	out := make([]string, len(diags))
+	for i, d := range diags {
+		out[i] = d.String()
+	}
+	return out
+}
+
+func TestCheck_CleanScript(t *testing.T) {
+	src := "require [\"fileinto\"];\n\nif address :is \"from\" \"a@example.com\" {\n    fileinto \"Receipts\";\n    stop;\n}\n"
+
+	diags := Check(src, LintOptions{Mailboxes: []string{"Inbox", "Receipts"}})
+	if len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagStrings(diags))
+	}
+}
+
+func TestCheck_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts LintOptions
+		want []string
+	}{
+		{
+			name: "syntax error",
+			src:  "keep",
+			want: []string{"1:5: error: expected \";\" or \"{\", found end of script"},
+		},
+		{
+			name: "missing require",
+			src:  "fileinto :copy \"Archive\";\naddflag \"$seen\";\nfileinto \"Other\";",
+			want: []string{
+				"1:1: warning: fileinto needs require \"fileinto\"",
+				"1:10: warning: :copy needs require \"copy\"",
+				"2:1: warning: addflag needs require \"imap4flags\"",
+			},
+		},
+		{
+			name: "unreachable after stop",
+			src:  "if true {\n    stop;\n    keep;\n    keep;\n}\n",
+			want: []string{"3:5: warning: unreachable: keep follows stop on line 2"},
+		},
+		{
+			name: "discard",
+			src:  "if header :contains \"subject\" \"lottery\" { discard; }",
+			want: []string{"1:43: warning: discard silently deletes matching messages; consider fileinto \"Junk\" or a review folder instead, so mistakes can be recovered"},
+		},
+		{
+			name: "unknown fileinto target",
+			src:  "require \"fileinto\";\nfileinto \"receipts\";\nfileinto \"Nowhere\";\nfileinto \"INBOX\";\n",
+			opts: LintOptions{Mailboxes: []string{"Inbox", "Receipts"}},
+			want: []string{
+				"2:10: warning: fileinto target \"receipts\" does not match an existing mailbox (did you mean \"Receipts\"?)",
+				"3:10: warning: fileinto target \"Nowhere\" does not match an existing mailbox",
+			},
+		},
+		{
+			name: "nested fileinto target needs its full path",
+			src:  "require \"fileinto\";\nfileinto \"Finance/Receipts\";\nfileinto \"Receipts\";\n",
+			opts: LintOptions{Mailboxes: []string{"Inbox", "Finance", "Finance/Receipts"}},
+			want: []string{
+				"3:10: warning: fileinto target \"Receipts\" does not match an existing mailbox (did you mean \"Finance/Receipts\"?)",
+			},
+		},
+		{
+			name: "fileinto with create skips mailbox check",
+			src:  "require [\"fileinto\", \"mailbox\"];\nfileinto :create \"New\";\n",
+			opts: LintOptions{Mailboxes: []string{"Inbox"}},
+			want: nil,
+		},
+		{
+			name: "structural errors",
+			src:  "keep;\nrequire \"fileinto\";\nelse { keep; }\nif true;\nfrobnicate;\nif nosuchtest { stop; }\n",
+			want: []string{
+				"2:1: error: require must come before all other commands",
+				"3:1: error: else must follow if or elsif",
+				"4:1: error: if requires a block",
+				"5:1: error: unknown command \"frobnicate\"",
+				"6:4: error: unknown test \"nosuchtest\"",
+			},
+		},
+		{
+			name: "test arity",
+			src:  "if not (true, false) { keep; }\nif size :over 1M (true) { keep; }\n",
+			want: []string{
+				"1:4: error: not requires exactly one test",
+				"2:4: error: size does not take nested tests",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diagStrings(Check(tt.src, tt.opts))
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Check() diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	if HasErrors([]Diagnostic{{Severity: SeverityWarning}}) {
+		t.Error("HasErrors() = true for warnings only")
+	}
+	if !HasErrors([]Diagnostic{{Severity: SeverityWarning}, {Severity: SeverityError}}) {
+		t.Error("HasErrors() = false with an error present")
+	}
+}
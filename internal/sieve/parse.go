@@ -0,0 +1,472 @@
+package sieve
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error is a syntax error at a position in the script.
+type Error struct {
+	Pos Position
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Pos.Line, e.Pos.Column, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokTag
+	tokNumber
+	tokString
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	pos  Position
+	text string // identifier/tag name, punctuation, or decoded string
+	num  uint64
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of script"
+	case tokIdent:
+		return fmt.Sprintf("identifier %q", t.text)
+	case tokTag:
+		return fmt.Sprintf("tag :%s", t.text)
+	case tokNumber:
+		return fmt.Sprintf("number %d", t.num)
+	case tokString:
+		return "string"
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// lexer splits script source into tokens, tracking line and column.
+type lexer struct {
+	src  string
+	off  int
+	line int
+	col  int
+}
+
+func (l *lexer) pos() Position {
+	return Position{Line: l.line, Column: l.col}
+}
+
+func (l *lexer) peekRune() rune {
+	if l.off >= len(l.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.off:])
+	return r
+}
+
+func (l *lexer) nextRune() rune {
+	if l.off >= len(l.src) {
+		return -1
+	}
+	r, size := utf8.DecodeRuneInString(l.src[l.off:])
+	l.off += size
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+// skipSpace skips whitespace, hash comments, and bracket comments.
+func (l *lexer) skipSpace() error {
+	for {
+		switch r := l.peekRune(); {
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			l.nextRune()
+		case r == '#':
+			for r := l.peekRune(); r != '\n' && r != -1; r = l.peekRune() {
+				l.nextRune()
+			}
+		case r == '/' && strings.HasPrefix(l.src[l.off:], "/*"):
+			start := l.pos()
+			l.nextRune()
+			l.nextRune()
+			for !strings.HasPrefix(l.src[l.off:], "*/") {
+				if l.nextRune() == -1 {
+					return &Error{Pos: start, Msg: "unterminated comment"}
+				}
+			}
+			l.nextRune()
+			l.nextRune()
+		default:
+			return nil
+		}
+	}
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || (r >= '0' && r <= '9')
+}
+
+func (l *lexer) ident() string {
+	start := l.off
+	for isIdentPart(l.peekRune()) {
+		l.nextRune()
+	}
+	return l.src[start:l.off]
+}
+
+func (l *lexer) next() (token, error) {
+	if err := l.skipSpace(); err != nil {
+		return token{}, err
+	}
+
+	pos := l.pos()
+	r := l.peekRune()
+	switch {
+	case r == -1:
+		return token{kind: tokEOF, pos: pos}, nil
+	case strings.ContainsRune("[](){},;", r):
+		l.nextRune()
+		return token{kind: tokPunct, pos: pos, text: string(r)}, nil
+	case r == ':':
+		l.nextRune()
+		if !isIdentStart(l.peekRune()) {
+			return token{}, &Error{Pos: pos, Msg: "expected tag name after ':'"}
+		}
+		return token{kind: tokTag, pos: pos, text: strings.ToLower(l.ident())}, nil
+	case r == '"':
+		s, err := l.quotedString(pos)
+		return token{kind: tokString, pos: pos, text: s}, err
+	case r >= '0' && r <= '9':
+		return l.number(pos)
+	case isIdentStart(r):
+		name := l.ident()
+		if strings.EqualFold(name, "text") && l.peekRune() == ':' {
+			l.nextRune()
+			s, err := l.multiLine(pos)
+			return token{kind: tokString, pos: pos, text: s}, err
+		}
+		return token{kind: tokIdent, pos: pos, text: strings.ToLower(name)}, nil
+	default:
+		return token{}, &Error{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+	}
+}
+
+func (l *lexer) quotedString(start Position) (string, error) {
+	l.nextRune()
+	var b strings.Builder
+	for {
+		r := l.nextRune()
+		switch r {
+		case -1:
+			return "", &Error{Pos: start, Msg: "unterminated string"}
+		case '"':
+			return b.String(), nil
+		case '\\':
+			esc := l.nextRune()
+			if esc == -1 {
+				return "", &Error{Pos: start, Msg: "unterminated string"}
+			}
+			b.WriteRune(esc)
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// multiLine reads a text: ... . block (RFC 5228, Section 2.4.2).
+func (l *lexer) multiLine(start Position) (string, error) {
+	for r := l.peekRune(); r == ' ' || r == '\t'; r = l.peekRune() {
+		l.nextRune()
+	}
+	if l.peekRune() == '#' {
+		for r := l.peekRune(); r != '\n' && r != -1; r = l.peekRune() {
+			l.nextRune()
+		}
+	}
+	if l.peekRune() == '\r' {
+		l.nextRune()
+	}
+	if l.nextRune() != '\n' {
+		return "", &Error{Pos: start, Msg: "expected line break after text:"}
+	}
+
+	var b strings.Builder
+	for {
+		if l.off >= len(l.src) {
+			return "", &Error{Pos: start, Msg: "unterminated multi-line string"}
+		}
+		end := strings.IndexByte(l.src[l.off:], '\n')
+		var line string
+		if end < 0 {
+			line = l.src[l.off:]
+		} else {
+			line = l.src[l.off : l.off+end]
+		}
+		for range line {
+			l.nextRune()
+		}
+		if end >= 0 {
+			l.nextRune()
+		}
+		line = strings.TrimSuffix(line, "\r")
+		if line == "." {
+			return b.String(), nil
+		}
+		if strings.HasPrefix(line, "..") {
+			line = line[1:]
+		}
+		b.WriteString(line)
+		b.WriteString("\r\n")
+	}
+}
+
+func (l *lexer) number(pos Position) (token, error) {
+	start := l.off
+	for r := l.peekRune(); r >= '0' && r <= '9'; r = l.peekRune() {
+		l.nextRune()
+	}
+	n, err := strconv.ParseUint(l.src[start:l.off], 10, 64)
+	if err != nil {
+		return token{}, &Error{Pos: pos, Msg: "number out of range"}
+	}
+	shift := 0
+	switch l.peekRune() {
+	case 'K', 'k':
+		shift = 10
+	case 'M', 'm':
+		shift = 20
+	case 'G', 'g':
+		shift = 30
+	}
+	if shift > 0 {
+		if n > math.MaxUint64>>shift {
+			return token{}, &Error{Pos: pos, Msg: "number out of range"}
+		}
+		n <<= shift
+		l.nextRune()
+	}
+	if isIdentPart(l.peekRune()) {
+		return token{}, &Error{Pos: pos, Msg: "invalid number"}
+	}
+	return token{kind: tokNumber, pos: pos, num: n}, nil
+}
+
+// parser builds an AST from tokens with one token of lookahead.
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// Parse parses a sieve script. Errors are returned as *Error with the position
+// of the offending token.
+func Parse(src string) (*Script, error) {
+	p := &parser{lex: &lexer{src: src, line: 1, col: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	commands, err := p.commands()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.unexpected("command")
+	}
+	return &Script{Commands: commands}, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) isPunct(s string) bool {
+	return p.tok.kind == tokPunct && p.tok.text == s
+}
+
+func (p *parser) unexpected(want string) error {
+	return &Error{Pos: p.tok.pos, Msg: fmt.Sprintf("expected %s, found %s", want, p.tok.describe())}
+}
+
+func (p *parser) expect(s string) error {
+	if !p.isPunct(s) {
+		return p.unexpected(fmt.Sprintf("%q", s))
+	}
+	return p.advance()
+}
+
+// commands parses commands until end of input or a closing brace.
+func (p *parser) commands() ([]*Command, error) {
+	commands := []*Command{}
+	for p.tok.kind == tokIdent {
+		cmd, err := p.command()
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+func (p *parser) command() (*Command, error) {
+	cmd := &Command{Name: p.tok.text, Pos: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	args, tests, err := p.arguments()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Args = args
+	cmd.Tests = tests
+
+	switch {
+	case p.isPunct(";"):
+		cmd.End = p.tok.pos
+		return cmd, p.advance()
+	case p.isPunct("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		block, err := p.commands()
+		if err != nil {
+			return nil, err
+		}
+		cmd.End = p.tok.pos
+		if err := p.expect("}"); err != nil {
+			return nil, err
+		}
+		cmd.Block = block
+		return cmd, nil
+	default:
+		return nil, p.unexpected("\";\" or \"{\"")
+	}
+}
+
+// arguments parses arguments followed by an optional test or test list.
+func (p *parser) arguments() ([]*Argument, []*Test, error) {
+	var args []*Argument
+	for {
+		arg, err := p.argument()
+		if err != nil {
+			return nil, nil, err
+		}
+		if arg == nil {
+			break
+		}
+		args = append(args, arg)
+	}
+
+	switch {
+	case p.tok.kind == tokIdent:
+		test, err := p.test()
+		if err != nil {
+			return nil, nil, err
+		}
+		return args, []*Test{test}, nil
+	case p.isPunct("("):
+		tests, err := p.testList()
+		return args, tests, err
+	default:
+		return args, nil, nil
+	}
+}
+
+// argument parses one argument, or returns nil if the current token does not
+// start one.
+func (p *parser) argument() (*Argument, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokString:
+		return &Argument{Kind: ArgString, Pos: tok.pos, Strings: []string{tok.text}}, p.advance()
+	case tok.kind == tokNumber:
+		return &Argument{Kind: ArgNumber, Pos: tok.pos, Number: tok.num}, p.advance()
+	case tok.kind == tokTag:
+		return &Argument{Kind: ArgTag, Pos: tok.pos, Tag: tok.text}, p.advance()
+	case p.isPunct("["):
+		return p.stringList()
+	default:
+		return nil, nil
+	}
+}
+
+func (p *parser) stringList() (*Argument, error) {
+	arg := &Argument{Kind: ArgStringList, Pos: p.tok.pos, Strings: []string{}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	for {
+		if p.tok.kind != tokString {
+			return nil, p.unexpected("string")
+		}
+		arg.Strings = append(arg.Strings, p.tok.text)
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.isPunct("]") {
+			return arg, p.advance()
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) test() (*Test, error) {
+	if p.tok.kind != tokIdent {
+		return nil, p.unexpected("test")
+	}
+	t := &Test{Name: p.tok.text, Pos: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	args, tests, err := p.arguments()
+	if err != nil {
+		return nil, err
+	}
+	t.Args = args
+	t.Tests = tests
+	return t, nil
+}
+
+func (p *parser) testList() ([]*Test, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var tests []*Test
+	for {
+		t, err := p.test()
+		if err != nil {
+			return nil, err
+		}
+		tests = append(tests, t)
+		if p.isPunct(")") {
+			return tests, p.advance()
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package sieve
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse_GeneratedTemplate(t *testing.T) {
+	src := "require [\"fileinto\", \"imap4flags\"];\n\n" +
+		"if allof (\n    address :domain :is \"from\" \"example.com\",\n    size :over 500K\n) {\n" +
+		"    setflag \"\\\\Seen\";\n    fileinto \"Receipts\";\n    stop;\n}\n"
+
+	script, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(script.Commands) != 2 {
+		t.Fatalf("expected 2 top-level commands, got %d", len(script.Commands))
+	}
+
+	req := script.Commands[0]
+	if req.Name != "require" || len(req.Args) != 1 || req.Args[0].Kind != ArgStringList {
+		t.Fatalf("unexpected require command: %+v", req)
+	}
+	if got := req.Args[0].Strings; len(got) != 2 || got[1] != "imap4flags" {
+		t.Errorf("require extensions = %v", got)
+	}
+
+	ifCmd := script.Commands[1]
+	if ifCmd.Pos != (Position{Line: 3, Column: 1}) {
+		t.Errorf("if position = %v, want 3:1", ifCmd.Pos)
+	}
+	if len(ifCmd.Tests) != 1 || ifCmd.Tests[0].Name != "allof" || len(ifCmd.Tests[0].Tests) != 2 {
+		t.Fatalf("unexpected if test: %+v", ifCmd.Tests)
+	}
+	size := ifCmd.Tests[0].Tests[1]
+	if size.Args[1].Kind != ArgNumber || size.Args[1].Number != 500*1024 {
+		t.Errorf("size argument = %+v, want 512000", size.Args[1])
+	}
+	if len(ifCmd.Block) != 3 {
+		t.Fatalf("expected 3 commands in block, got %d", len(ifCmd.Block))
+	}
+	if got := ifCmd.Block[0].Args[0].Strings[0]; got != `\Seen` {
+		t.Errorf("setflag argument = %q, want %q", got, `\Seen`)
+	}
+	if pos := ifCmd.Block[1].Pos; pos != (Position{Line: 8, Column: 5}) {
+		t.Errorf("fileinto position = %v, want 8:5", pos)
+	}
+}
+
+func TestParse_CommentsAndMultiLineStrings(t *testing.T) {
+	src := "# leading comment\n" +
+		"/* bracket\n   comment */\n" +
+		"require \"vacation\";\n" +
+		"vacation :days 7 text:\n" +
+		"Away until Monday.\n" +
+		"..dot-stuffed\n" +
+		".\n;\n"
+
+	script, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	vacation := script.Commands[1]
+	if vacation.Pos.Line != 5 {
+		t.Errorf("vacation line = %d, want 5", vacation.Pos.Line)
+	}
+	body := vacation.Args[2].Strings[0]
+	if body != "Away until Monday.\r\n.dot-stuffed\r\n" {
+		t.Errorf("multi-line body = %q", body)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		pos  Position
+		msg  string
+	}{
+		{
+			name: "missing semicolon",
+			src:  "keep\n",
+			pos:  Position{Line: 2, Column: 1},
+			msg:  "expected \";\" or \"{\", found end of script",
+		},
+		{
+			name: "unterminated string",
+			src:  "fileinto \"Junk;\n",
+			pos:  Position{Line: 1, Column: 10},
+			msg:  "unterminated string",
+		},
+		{
+			name: "unclosed block",
+			src:  "if true {\n  keep;\n",
+			pos:  Position{Line: 3, Column: 1},
+			msg:  "expected \"}\", found end of script",
+		},
+		{
+			name: "bad string list",
+			src:  "require [\"fileinto\" \"copy\"];",
+			pos:  Position{Line: 1, Column: 21},
+			msg:  "expected \",\", found string",
+		},
+		{
+			name: "quantifier overflow",
+			src:  "if size :over 17179869184G { keep; }",
+			pos:  Position{Line: 1, Column: 15},
+			msg:  "number out of range",
+		},
+		{
+			name: "unexpected character",
+			src:  "keep;\n  @stop;",
+			pos:  Position{Line: 2, Column: 3},
+			msg:  "unexpected character '@'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			var perr *Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse() error = %v, want *Error", err)
+			}
+			if perr.Pos != tt.pos {
+				t.Errorf("error position = %v, want %v", perr.Pos, tt.pos)
+			}
+			if perr.Msg != tt.msg {
+				t.Errorf("error message = %q, want %q", perr.Msg, tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package snooze keeps the local record of snoozed emails: when each one
+// was snoozed and when it is due back in the Inbox.
+package snooze
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/cboone/fm/internal/statefile"
+	"github.com/cboone/fm/internal/types"
+)
+
+// State is the record of snoozed emails for one account, kept in the JSON
+// file at Path and keyed by email ID.
+type State struct {
+	Path    string
+	Account string
+}
+
+type stateFile struct {
+	Accounts map[string]map[string]types.SnoozeEntry `json:"accounts"`
+}
+
+// Entries returns the snoozed emails recorded for the account.
+func (s *State) Entries() (map[string]types.SnoozeEntry, error) {
+	state, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	entries := state.Accounts[s.Account]
+	if entries == nil {
+		entries = map[string]types.SnoozeEntry{}
+	}
+	return entries, nil
+}
+
+// Update records set and forgets the emails in remove, in one write.
+func (s *State) Update(set map[string]types.SnoozeEntry, remove []string) error {
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+	entries := state.Accounts[s.Account]
+	if entries == nil {
+		entries = map[string]types.SnoozeEntry{}
+	}
+	for id, e := range set {
+		entries[id] = e
+	}
+	for _, id := range remove {
+		delete(entries, id)
+	}
+	if len(entries) == 0 {
+		delete(state.Accounts, s.Account)
+	} else {
+		state.Accounts[s.Account] = entries
+	}
+
+	if err := statefile.WriteJSON(s.Path, state); err != nil {
+		return fmt.Errorf("writing snooze state: %w", err)
+	}
+	return nil
+}
+
+func (s *State) load() (stateFile, error) {
+	state := stateFile{Accounts: map[string]map[string]types.SnoozeEntry{}}
+	raw, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("reading snooze state: %w", err)
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return state, fmt.Errorf("parsing %s: %w", s.Path, err)
+	}
+	if state.Accounts == nil {
+		state.Accounts = map[string]map[string]types.SnoozeEntry{}
+	}
+	return state, nil
+}
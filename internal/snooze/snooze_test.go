@@ -0,0 +1,67 @@
+package snooze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "snooze.json")
+	s := &State{Path: path, Account: "agent"}
+
+	entries, err := s.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries yet, got %v, %v", entries, err)
+	}
+
+	until := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	at := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	if err := s.Update(map[string]types.SnoozeEntry{
+		"M1": {Until: until, SnoozedAt: at},
+		"M2": {Until: until, SnoozedAt: at},
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(nil, []string{"M1", "M9"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err = s.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries["M2"].Until != until || entries["M2"].SnoozedAt != at {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	other := &State{Path: path, Account: "work"}
+	if entries, _ := other.Entries(); len(entries) != 0 {
+		t.Errorf("expected entries to be kept per account, got %+v", entries)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("state file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestState_BadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snooze.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := &State{Path: path, Account: "agent"}
+	if _, err := s.Entries(); err == nil {
+		t.Error("expected an error for a corrupt state file")
+	}
+	if err := s.Update(nil, []string{"M1"}); err == nil {
+		t.Error("expected Update to refuse to overwrite a corrupt state file")
+	}
+}
@@ -0,0 +1,55 @@
+package statefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "state.json")
+
+	if err := WriteJSON(path, map[string]int{"a": 1}); err != nil {
+		t.Fatalf("WriteJSON() error: %v", err)
+	}
+	if err := WriteJSON(path, map[string]int{"b": 2}); err != nil {
+		t.Fatalf("WriteJSON() replace error: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != "{\n  \"b\": 2\n}\n" {
+		t.Errorf("content = %q", raw)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files left behind, got %v", entries)
+	}
+}
+
+func TestWriteJSON_EncodingErrorLeavesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := WriteJSON(path, map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteJSON(path, func() {}); err == nil {
+		t.Fatal("expected an encoding error")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil || string(raw) != "{\n  \"a\": 1\n}\n" {
+		t.Errorf("expected the previous file intact, got %q, %v", raw, err)
+	}
+}
@@ -19,12 +19,24 @@ type Attachment struct {
 type MailboxInfo struct {
 	ID           string `json:"id"`
 	Name         string `json:"name"`
+	Path         string `json:"path"`
 	Role         string `json:"role,omitempty"`
 	TotalEmails  uint64 `json:"total_emails"`
 	UnreadEmails uint64 `json:"unread_emails"`
 	ParentID     string `json:"parent_id,omitempty"`
 }
 
+// MailboxNode is a mailbox in the tree shown by fm mailboxes --tree, with
+// the mailboxes below it. The subtree counts add the mailbox's own counts
+// to those of every mailbox below it; an email in several of them is
+// counted in each.
+type MailboxNode struct {
+	MailboxInfo
+	SubtreeTotal  uint64        `json:"subtree_total_emails"`
+	SubtreeUnread uint64        `json:"subtree_unread_emails"`
+	Children      []MailboxNode `json:"children"`
+}
+
 // EmailSummary is a brief view of an email for list/search results.
 type EmailSummary struct {
 	ID         string    `json:"id"`
@@ -38,6 +50,7 @@ type EmailSummary struct {
 	IsFlagged  bool      `json:"is_flagged"`
 	Preview    string    `json:"preview"`
 	Snippet    string    `json:"snippet,omitempty"`
+	Category   string    `json:"category,omitempty"`
 }
 
 // EmailListResult wraps a paginated email list.
@@ -91,11 +104,52 @@ type ThreadView struct {
 	Thread []ThreadEmail `json:"thread"`
 }
 
-// SessionInfo is a simplified session for output.
+// SessionInfo is a simplified session for output. Profile is the name of the
+// configuration profile in use, if any.
 type SessionInfo struct {
+	Profile      string                 `json:"profile,omitempty"`
 	Username     string                 `json:"username"`
 	Accounts     map[string]AccountInfo `json:"accounts"`
 	Capabilities []string               `json:"capabilities"`
+	ReadOnly     bool                   `json:"read_only"`
+}
+
+// ProfileListResult lists the named profiles in the config file.
+type ProfileListResult struct {
+	Active   string        `json:"active,omitempty"`
+	Profiles []ProfileInfo `json:"profiles"`
+}
+
+// ProfileInfo describes one configured profile. Tokens are never included;
+// TokenSource says where the token comes from: token, token_command, or none.
+type ProfileInfo struct {
+	Name        string `json:"name"`
+	Active      bool   `json:"active"`
+	TokenSource string `json:"token_source"`
+	SessionURL  string `json:"session_url,omitempty"`
+	AccountID   string `json:"account_id,omitempty"`
+	Format      string `json:"format,omitempty"`
+}
+
+// AuthStatus reports where the token comes from and whether it works. The
+// token itself is never included. Source is flag, env, config, token_command,
+// keyring, or none.
+type AuthStatus struct {
+	Profile       string `json:"profile,omitempty"`
+	Source        string `json:"source"`
+	Backend       string `json:"backend,omitempty"`
+	Authenticated bool   `json:"authenticated"`
+	Username      string `json:"username,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// AuthResult reports a token stored by auth login or removed by auth logout.
+// Account is the name the token is filed under in the backend.
+type AuthResult struct {
+	Action   string `json:"action"`
+	Account  string `json:"account"`
+	Backend  string `json:"backend"`
+	Username string `json:"username,omitempty"`
 }
 
 // AccountInfo is a simplified account for output.
@@ -115,6 +169,8 @@ type MoveResult struct {
 	MarkedAsRead []string         `json:"marked_as_read,omitempty"`
 	Flagged      []string         `json:"flagged,omitempty"`
 	Unflagged    []string         `json:"unflagged,omitempty"`
+	Snoozed      []string         `json:"snoozed,omitempty"`
+	Until        *time.Time       `json:"until,omitempty"`
 	Destination  *DestinationInfo `json:"destination,omitempty"`
 	Errors       []string         `json:"errors"`
 }
@@ -123,6 +179,7 @@ type MoveResult struct {
 type DestinationInfo struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
 }
 
 // DryRunResult previews the emails that would be affected by a mutating command.
@@ -140,6 +197,10 @@ type SenderStat struct {
 	Name     string   `json:"name"`
 	Count    int      `json:"count"`
 	Subjects []string `json:"subjects,omitempty"`
+
+	// Category and Score are set when senders were classified.
+	Category string  `json:"category,omitempty"`
+	Score    float64 `json:"score,omitempty"`
 }
 
 // StatsResult wraps an aggregated sender distribution.
@@ -156,11 +217,223 @@ type DomainStat struct {
 
 // SummaryResult wraps a triage-oriented inbox summary with sender and domain aggregation.
 type SummaryResult struct {
-	Total       uint64       `json:"total"`
-	Unread      uint64       `json:"unread"`
-	TopSenders  []SenderStat `json:"top_senders"`
-	TopDomains  []DomainStat `json:"top_domains"`
-	Newsletters []SenderStat `json:"newsletters,omitempty"`
+	Total       uint64         `json:"total"`
+	Unread      uint64         `json:"unread"`
+	TopSenders  []SenderStat   `json:"top_senders"`
+	TopDomains  []DomainStat   `json:"top_domains"`
+	Newsletters []SenderStat   `json:"newsletters,omitempty"`
+	Categories  []CategoryStat `json:"categories,omitempty"`
+}
+
+// Classification is the category of a sender, its score between 0 and 1,
+// and the signals that produced it.
+type Classification struct {
+	Category string   `json:"category"`
+	Score    float64  `json:"score"`
+	Reasons  []string `json:"reasons"`
+}
+
+// CategoryStat counts the senders and messages in one category.
+type CategoryStat struct {
+	Category string `json:"category"`
+	Senders  int    `json:"senders"`
+	Count    int    `json:"count"`
+}
+
+// TrendBucket is the number of emails received in one day, week, or month
+// starting at Start (UTC).
+type TrendBucket struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// TrendGroup is the per-bucket volume of one sender or domain. Counts lines
+// up with TrendsResult.Buckets. Slope is the least-squares trend in emails
+// per bucket: positive when the group is ramping up.
+type TrendGroup struct {
+	Key    string  `json:"key"`
+	Name   string  `json:"name,omitempty"`
+	Total  int     `json:"total"`
+	Counts []int   `json:"counts"`
+	Slope  float64 `json:"slope"`
+}
+
+// TrendsResult is mailbox volume over time, from fm trends. Emails received
+// at or after After and before Before are counted.
+type TrendsResult struct {
+	By      string        `json:"by"`
+	Group   string        `json:"group,omitempty"`
+	After   time.Time     `json:"after"`
+	Before  time.Time     `json:"before"`
+	Total   int           `json:"total"`
+	Slope   float64       `json:"slope"`
+	Buckets []TrendBucket `json:"buckets"`
+	Groups  []TrendGroup  `json:"groups,omitzero"`
+}
+
+// UsageStat is the storage used by the emails in one mailbox, from one
+// sender or domain, received in one year, or, in UsageResult.ByType, by the
+// attachments of one MIME type. Key is the mailbox ID, address, domain,
+// year, or MIME type; Name is the mailbox or sender name.
+type UsageStat struct {
+	Key            string `json:"key"`
+	Name           string `json:"name,omitempty"`
+	Count          int    `json:"count"`
+	Size           uint64 `json:"size"`
+	AttachmentSize uint64 `json:"attachment_size"`
+}
+
+// QuotaInfo is one account quota from Quota/get. ResourceType is "octets"
+// for storage or "count" for a number of objects.
+type QuotaInfo struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	ResourceType string   `json:"resource_type"`
+	Scope        string   `json:"scope"`
+	Types        []string `json:"types,omitempty"`
+	Used         uint64   `json:"used"`
+	HardLimit    uint64   `json:"hard_limit"`
+	WarnLimit    *uint64  `json:"warn_limit,omitempty"`
+	SoftLimit    *uint64  `json:"soft_limit,omitempty"`
+	PercentUsed  float64  `json:"percent_used"`
+}
+
+// UsageResult reports where storage goes, from fm usage. Sizes are in
+// bytes. An email in several mailboxes counts toward each of them.
+// Quotas is omitted when the server does not support JMAP quotas.
+type UsageResult struct {
+	Total          int            `json:"total"`
+	Size           uint64         `json:"size"`
+	AttachmentSize uint64         `json:"attachment_size"`
+	ByMailbox      []UsageStat    `json:"by_mailbox"`
+	BySender       []UsageStat    `json:"by_sender"`
+	ByDomain       []UsageStat    `json:"by_domain"`
+	ByYear         []UsageStat    `json:"by_year"`
+	ByType         []UsageStat    `json:"by_type"`
+	Largest        []EmailSummary `json:"largest"`
+	Quotas         []QuotaInfo    `json:"quotas,omitzero"`
+}
+
+// DuplicateCopy is one copy of a duplicated email, with the full paths of
+// the mailboxes it is in.
+type DuplicateCopy struct {
+	ID         string    `json:"id"`
+	Mailboxes  []string  `json:"mailboxes"`
+	ReceivedAt time.Time `json:"received_at"`
+	Size       uint64    `json:"size"`
+}
+
+// DuplicateGroup is a set of emails that are copies of one message.
+// MatchedBy is "message_id" when they share a Message-ID, or "fingerprint"
+// when they have none and share size, sent time, sender, and subject. Keep
+// is the copy left in place; Duplicates are the others.
+type DuplicateGroup struct {
+	MatchedBy  string          `json:"matched_by"`
+	MessageID  string          `json:"message_id,omitempty"`
+	From       string          `json:"from"`
+	Subject    string          `json:"subject"`
+	SentAt     *time.Time      `json:"sent_at,omitempty"`
+	Keep       DuplicateCopy   `json:"keep"`
+	Duplicates []DuplicateCopy `json:"duplicates"`
+}
+
+// DuplicatesResult is the output of fm duplicates. Groups holds up to the
+// requested number of groups, most copies first; DuplicateIDs lists every
+// copy in those groups that --move would move. GroupCount and
+// DuplicateCount cover all groups. The move fields are set only by --move.
+type DuplicatesResult struct {
+	Scanned        int              `json:"scanned"`
+	GroupCount     int              `json:"group_count"`
+	DuplicateCount int              `json:"duplicate_count"`
+	Groups         []DuplicateGroup `json:"groups"`
+	DuplicateIDs   []string         `json:"duplicate_ids"`
+	Moved          []string         `json:"moved,omitempty"`
+	Failed         int              `json:"failed,omitempty"`
+	Errors         []string         `json:"errors,omitempty"`
+	Destination    *DestinationInfo `json:"destination,omitempty"`
+}
+
+// UnansweredThread is a thread whose latest message is from a
+// correspondent and has had no reply. EmailID is that latest message.
+type UnansweredThread struct {
+	ThreadID    string    `json:"thread_id"`
+	EmailID     string    `json:"email_id"`
+	Subject     string    `json:"subject"`
+	ReceivedAt  time.Time `json:"received_at"`
+	WaitingDays int       `json:"waiting_days"`
+}
+
+// CorrespondentStat summarizes the mail exchanged with one address.
+// Replies counts the replies whose latency was measured, and
+// MedianReplySeconds is their median, omitted when there were none.
+type CorrespondentStat struct {
+	Email              string             `json:"email"`
+	Name               string             `json:"name,omitempty"`
+	Received           int                `json:"received"`
+	Sent               int                `json:"sent"`
+	LastContact        time.Time          `json:"last_contact"`
+	Replies            int                `json:"replies"`
+	MedianReplySeconds *int64             `json:"median_reply_seconds,omitempty"`
+	Unanswered         []UnansweredThread `json:"unanswered"`
+}
+
+// CorrespondentsResult is the output of fm correspondents, most owed first.
+// Total counts the correspondents before any limit.
+type CorrespondentsResult struct {
+	After          time.Time           `json:"after"`
+	Total          int                 `json:"total"`
+	Correspondents []CorrespondentStat `json:"correspondents"`
+}
+
+// UnansweredEmail is the latest message of a thread still awaiting a reply,
+// with the thread's message count and the time of the last reply in it,
+// omitted when it was never replied to.
+type UnansweredEmail struct {
+	EmailSummary
+	ThreadMessages int        `json:"thread_messages"`
+	LastReplyAt    *time.Time `json:"last_reply_at,omitempty"`
+	WaitingDays    int        `json:"waiting_days"`
+}
+
+// UnansweredResult is the output of fm unanswered, longest waiting first.
+// Total counts the threads before any limit.
+type UnansweredResult struct {
+	Total  int               `json:"total"`
+	Emails []UnansweredEmail `json:"emails"`
+}
+
+// TriageMessage is one unread message with its triage category and the
+// rules that put it there. Category on the summary is the sender's
+// classification.
+type TriageMessage struct {
+	EmailSummary
+	Triage  string   `json:"triage"`
+	Reasons []string `json:"reasons"`
+}
+
+// TriageOperation is one proposed fm command. CommandLine is the command
+// ready to run; Manual operations need editing first, such as a draft
+// reply's body, and are commented out in the plan script.
+type TriageOperation struct {
+	Category    string   `json:"category"`
+	Command     string   `json:"command"`
+	Args        []string `json:"args,omitempty"`
+	IDs         []string `json:"ids,omitempty"`
+	Reason      string   `json:"reason"`
+	Manual      bool     `json:"manual,omitempty"`
+	CommandLine string   `json:"command_line"`
+}
+
+// TriagePlan is the output of fm triage suggest. Nothing in it has been
+// applied; Content holds every operation as a shell script to review and
+// run.
+type TriagePlan struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Scanned     int               `json:"scanned"`
+	Counts      []CategoryStat    `json:"counts"`
+	Messages    []TriageMessage   `json:"messages"`
+	Operations  []TriageOperation `json:"operations"`
+	Content     string            `json:"content,omitempty"`
 }
 
 // DraftResult reports the outcome of a draft creation.
@@ -173,6 +446,13 @@ type DraftResult struct {
 	CC        []Address        `json:"cc,omitempty"`
 	Subject   string           `json:"subject"`
 	InReplyTo string           `json:"in_reply_to,omitempty"`
+
+	// Replaced, ArchivedTo, and ArchiveError are set only when editing a
+	// draft: the ID of the superseded draft, where it was moved, and why
+	// moving it failed (the revised draft exists either way).
+	Replaced     string           `json:"replaced,omitempty"`
+	ArchivedTo   *DestinationInfo `json:"archived_to,omitempty"`
+	ArchiveError string           `json:"archive_error,omitempty"`
 }
 
 // SieveScriptInfo is a summary view of a sieve script for list output.
@@ -189,11 +469,14 @@ type SieveScriptListResult struct {
 }
 
 // SieveScriptDetail is a full view of a sieve script including content.
+// State is the server's SieveScript state when the script was read, for use
+// with sieve update --if-in-state.
 type SieveScriptDetail struct {
 	ID       string `json:"id"`
 	Name     string `json:"name"`
 	BlobID   string `json:"blob_id"`
 	IsActive bool   `json:"is_active"`
+	State    string `json:"state,omitempty"`
 	Content  string `json:"content"`
 }
 
@@ -206,6 +489,15 @@ type SieveCreateResult struct {
 	Content  string `json:"content"`
 }
 
+// SieveUpdateResult reports the outcome of replacing a script's content.
+type SieveUpdateResult struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	BlobID   string `json:"blob_id"`
+	IsActive bool   `json:"is_active"`
+	Content  string `json:"content"`
+}
+
 // SieveDeleteResult reports the outcome of sieve script deletion.
 type SieveDeleteResult struct {
 	ID   string `json:"id"`
@@ -221,9 +513,127 @@ type SieveActivateResult struct {
 
 // SieveValidateResult reports the outcome of script validation.
 type SieveValidateResult struct {
-	Valid   bool   `json:"valid"`
-	Error   string `json:"error,omitempty"`
-	Content string `json:"content"`
+	Valid       bool              `json:"valid"`
+	Error       string            `json:"error,omitempty"`
+	Diagnostics []SieveDiagnostic `json:"diagnostics,omitempty"`
+	Content     string            `json:"content"`
+}
+
+// SieveDiagnostic is an error or warning from local sieve validation.
+// Line and column are omitted for diagnostics without a source position.
+type SieveDiagnostic struct {
+	Severity string `json:"severity"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// SieveTestResult reports what a sieve script would have done to existing
+// messages, evaluated locally.
+type SieveTestResult struct {
+	Script      string             `json:"script"`
+	Mailbox     string             `json:"mailbox"`
+	Total       int                `json:"total"`
+	Matched     int                `json:"matched"`
+	Summary     []SieveTestSummary `json:"summary"`
+	Messages    []SieveTestMessage `json:"messages"`
+	Unsupported []string           `json:"unsupported,omitempty"`
+}
+
+// SieveTestSummary counts messages per resulting action and destination.
+type SieveTestSummary struct {
+	Action      string `json:"action"`
+	Destination string `json:"destination,omitempty"`
+	Count       int    `json:"count"`
+}
+
+// SieveTestMessage is the simulated outcome for one message. Rules holds the
+// line numbers of the if/elsif/else commands whose blocks ran; it is empty
+// when no rule matched.
+type SieveTestMessage struct {
+	ID         string            `json:"id"`
+	From       []Address         `json:"from"`
+	Subject    string            `json:"subject"`
+	ReceivedAt time.Time         `json:"received_at"`
+	Rules      []int             `json:"rules,omitempty"`
+	Actions    []SieveTestAction `json:"actions"`
+}
+
+// SieveTestAction is one action the script would take. Target is the mailbox
+// for fileinto and keep, or the address for redirect.
+type SieveTestAction struct {
+	Action   string   `json:"action"`
+	Target   string   `json:"target,omitempty"`
+	Copy     bool     `json:"copy,omitempty"`
+	Flags    []string `json:"flags,omitempty"`
+	Implicit bool     `json:"implicit,omitempty"`
+}
+
+// SieveBackupResult reports the scripts written by sieve backup.
+type SieveBackupResult struct {
+	Directory string              `json:"directory"`
+	Total     int                 `json:"total"`
+	Scripts   []SieveBackupScript `json:"scripts"`
+}
+
+// SieveBackupScript is one script in a backup directory. File is relative
+// to the backup directory.
+type SieveBackupScript struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	IsActive bool   `json:"is_active"`
+}
+
+// SieveRestoreResult reports what sieve restore did, or would do in a dry run.
+type SieveRestoreResult struct {
+	Directory string               `json:"directory"`
+	DryRun    bool                 `json:"dry_run,omitempty"`
+	Created   int                  `json:"created"`
+	Updated   int                  `json:"updated"`
+	Unchanged int                  `json:"unchanged"`
+	Activated string               `json:"activated,omitempty"`
+	Scripts   []SieveRestoreScript `json:"scripts"`
+	Errors    []string             `json:"errors"`
+}
+
+// SieveRestoreScript is the restore action for one backed-up script: create,
+// update, or unchanged. Diff is set for updates in a dry run.
+type SieveRestoreScript struct {
+	Name   string `json:"name"`
+	ID     string `json:"id,omitempty"`
+	File   string `json:"file"`
+	Action string `json:"action"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// SieveDiffResult compares a script on the server with a local file.
+type SieveDiffResult struct {
+	Script    string `json:"script"`
+	File      string `json:"file"`
+	Identical bool   `json:"identical"`
+	Diff      string `json:"diff,omitempty"`
+}
+
+// SieveSuggestResult proposes sieve rules from where mail from each sender
+// currently sits. Content is a ready-to-create script holding every rule.
+type SieveSuggestResult struct {
+	Scanned     int               `json:"scanned"`
+	Suggestions []SieveSuggestion `json:"suggestions"`
+	Content     string            `json:"content,omitempty"`
+}
+
+// SieveSuggestion is one proposed rule: mail from From (or any address at
+// FromDomain) goes to Mailbox. Count of the sender's Total scanned messages
+// are already there; Confidence is Count / Total.
+type SieveSuggestion struct {
+	From       string  `json:"from,omitempty"`
+	FromDomain string  `json:"from_domain,omitempty"`
+	Action     string  `json:"action"`
+	Mailbox    string  `json:"mailbox,omitempty"`
+	Count      int     `json:"count"`
+	Total      int     `json:"total"`
+	Confidence float64 `json:"confidence"`
 }
 
 // SieveDryRunResult previews a sieve mutation without executing it.
@@ -231,9 +641,134 @@ type SieveDryRunResult struct {
 	Operation string `json:"operation"`
 	Script    string `json:"script,omitempty"`
 	Content   string `json:"content,omitempty"`
+	Diff      string `json:"diff,omitempty"`
 	Valid     *bool  `json:"valid,omitempty"`
 }
 
+// VacationResponseInfo is the auto-responder configuration.
+type VacationResponseInfo struct {
+	IsEnabled bool       `json:"is_enabled"`
+	FromDate  *time.Time `json:"from_date,omitempty"`
+	ToDate    *time.Time `json:"to_date,omitempty"`
+	Subject   string     `json:"subject,omitempty"`
+	TextBody  string     `json:"text_body,omitempty"`
+	HTMLBody  string     `json:"html_body,omitempty"`
+}
+
+// VacationDryRunResult previews a vacation mutation without executing it.
+type VacationDryRunResult struct {
+	Operation string               `json:"operation"`
+	Vacation  VacationResponseInfo `json:"vacation"`
+}
+
+// AuditEntry is one line of the audit log: a mutating JMAP call made by fm,
+// or a dry run of one. Operation is the fm command (for example "archive" or
+// "sieve update") and Method the JMAP method; Method is empty for dry runs.
+// Command is the full command line with tokens redacted. Error is set when
+// the whole call failed; per-ID outcomes are in Results.
+type AuditEntry struct {
+	Time        time.Time     `json:"time"`
+	Profile     string        `json:"profile,omitempty"`
+	Command     string        `json:"command"`
+	Operation   string        `json:"operation"`
+	Method      string        `json:"method,omitempty"`
+	IDs         []string      `json:"ids"`
+	Destination string        `json:"destination,omitempty"`
+	DryRun      bool          `json:"dry_run"`
+	Results     []AuditResult `json:"results,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// AuditResult is the outcome of an audited call for one object ID.
+type AuditResult struct {
+	ID    string `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// AuditLogResult holds audit log entries returned by audit tail or search,
+// oldest first. Total counts the matching entries before any limit.
+type AuditLogResult struct {
+	Path    string       `json:"path"`
+	Total   int          `json:"total"`
+	Entries []AuditEntry `json:"entries"`
+}
+
+// BudgetWindow is the mutation budget for one window: "run" (this fm
+// process), "hour", or "day" (the trailing hour or day). Limit is 0 when the
+// window has no limit, and Remaining is then 0 too. ResetsAt is when the
+// oldest change counted in the window stops counting.
+type BudgetWindow struct {
+	Window    string     `json:"window"`
+	Limit     int        `json:"limit"`
+	Used      int        `json:"used"`
+	Remaining int        `json:"remaining"`
+	ResetsAt  *time.Time `json:"resets_at,omitempty"`
+}
+
+// BudgetResult is the mutation budget of a profile, reported by fm budget.
+type BudgetResult struct {
+	Profile   string         `json:"profile"`
+	StateFile string         `json:"state_file"`
+	Enabled   bool           `json:"enabled"`
+	Windows   []BudgetWindow `json:"windows"`
+}
+
+// UnsubscribeRecord is the outcome of one unsubscribe, kept in the local
+// unsubscribe state file so the same list is not unsubscribed from twice.
+type UnsubscribeRecord struct {
+	Time       time.Time `json:"time"`
+	EmailID    string    `json:"email_id"`
+	Method     string    `json:"method"`
+	Target     string    `json:"target"`
+	OK         bool      `json:"ok"`
+	HTTPStatus int       `json:"http_status,omitempty"`
+	DraftID    string    `json:"draft_id,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// UnsubscribeResult reports fm unsubscribe. List is the List-Id of the
+// mailing list, or the sender address when there is none. Method is
+// "one_click" (an RFC 8058 POST) or "mailto" (a draft to the unsubscribe
+// address). Status is "unsubscribed", "draft_created", "would_unsubscribe"
+// for a dry run, or "already_unsubscribed" when Previous records an earlier
+// successful unsubscribe from the same list.
+type UnsubscribeResult struct {
+	EmailID    string             `json:"email_id"`
+	From       string             `json:"from"`
+	Subject    string             `json:"subject"`
+	List       string             `json:"list"`
+	Method     string             `json:"method"`
+	Target     string             `json:"target"`
+	Status     string             `json:"status"`
+	DryRun     bool               `json:"dry_run,omitempty"`
+	HTTPStatus int                `json:"http_status,omitempty"`
+	Draft      *DraftResult       `json:"draft,omitempty"`
+	Previous   *UnsubscribeRecord `json:"previous,omitempty"`
+}
+
+// SnoozeEntry is one snoozed email in the local snooze state: when it was
+// snoozed and when fm snooze wake should return it to the Inbox.
+type SnoozeEntry struct {
+	Until     time.Time `json:"until"`
+	SnoozedAt time.Time `json:"snoozed_at"`
+}
+
+// SnoozeWakeResult reports fm snooze wake. Due counts the snoozed emails
+// whose time has come; Woken are those moved back to the Inbox and marked
+// unread. Pending counts the emails still snoozed, and NextWake is the
+// earliest time one of them is due.
+type SnoozeWakeResult struct {
+	Due         int              `json:"due"`
+	Processed   int              `json:"processed"`
+	Failed      int              `json:"failed"`
+	Woken       []string         `json:"woken"`
+	Destination *DestinationInfo `json:"destination"`
+	Pending     int              `json:"pending"`
+	NextWake    *time.Time       `json:"next_wake,omitempty"`
+	Errors      []string         `json:"errors"`
+}
+
 // AppError is a structured error for JSON output.
 type AppError struct {
 	Error   string `json:"error"`
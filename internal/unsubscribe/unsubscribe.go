@@ -0,0 +1,233 @@
+// Package unsubscribe parses List-Unsubscribe headers (RFC 2369), performs
+// RFC 8058 one-click unsubscribes, and keeps a local record of past
+// unsubscribes so the same list is not unsubscribed from twice.
+package unsubscribe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cboone/fm/internal/statefile"
+	"github.com/cboone/fm/internal/types"
+)
+
+// OneClickBody is the form body RFC 8058 requires in the unsubscribe POST,
+// and the List-Unsubscribe-Post header value that offers it.
+const OneClickBody = "List-Unsubscribe=One-Click"
+
+// DefaultTimeout bounds a one-click request, including redirects.
+const DefaultTimeout = 15 * time.Second
+
+// maxRedirects is the most HTTPS redirects a one-click request follows.
+const maxRedirects = 5
+
+// Targets are the unsubscribe URIs offered by a List-Unsubscribe header.
+// HTTPS and Mailto hold the first of each kind; Other holds every URI fm
+// cannot use, such as plain http: links.
+type Targets struct {
+	HTTPS  string
+	Mailto string
+	Other  []string
+}
+
+// Parse reads a List-Unsubscribe header value: a comma-separated list of
+// URIs, each in angle brackets. Anything outside the brackets is ignored.
+func Parse(header string) Targets {
+	var t Targets
+	rest := header
+	for {
+		start := strings.IndexByte(rest, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '>')
+		if end < 0 {
+			break
+		}
+		uri := strings.Join(strings.Fields(rest[start+1:start+end]), "")
+		rest = rest[start+end+1:]
+
+		u, err := url.Parse(uri)
+		switch {
+		case uri == "":
+			continue
+		case err == nil && strings.EqualFold(u.Scheme, "https") && u.Host != "":
+			if t.HTTPS == "" {
+				t.HTTPS = uri
+			}
+		case err == nil && strings.EqualFold(u.Scheme, "mailto"):
+			if t.Mailto == "" {
+				t.Mailto = uri
+			}
+		default:
+			t.Other = append(t.Other, uri)
+		}
+	}
+	return t
+}
+
+// OneClick reports whether a List-Unsubscribe-Post header value offers an
+// RFC 8058 one-click unsubscribe.
+func OneClick(postHeader string) bool {
+	return strings.EqualFold(strings.TrimSpace(postHeader), OneClickBody)
+}
+
+// Mailto is an unsubscribe message described by a mailto: URI (RFC 6068).
+type Mailto struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// ParseMailto reads the recipients, subject, and body of a mailto: URI.
+// The subject defaults to "unsubscribe" when the URI gives none.
+func ParseMailto(uri string) (Mailto, error) {
+	u, err := url.Parse(uri)
+	if err != nil || !strings.EqualFold(u.Scheme, "mailto") {
+		return Mailto{}, fmt.Errorf("not a mailto URI: %s", uri)
+	}
+	var m Mailto
+	addAddresses := func(list string) {
+		for _, addr := range strings.Split(list, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				m.To = append(m.To, addr)
+			}
+		}
+	}
+	path := u.Opaque
+	if decoded, err := url.PathUnescape(path); err == nil {
+		path = decoded
+	}
+	addAddresses(path)
+
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return Mailto{}, fmt.Errorf("parsing %s: %w", uri, err)
+	}
+	for key, values := range query {
+		switch strings.ToLower(key) {
+		case "to":
+			for _, v := range values {
+				addAddresses(v)
+			}
+		case "subject":
+			m.Subject = values[0]
+		case "body":
+			m.Body = values[0]
+		}
+	}
+	if len(m.To) == 0 {
+		return Mailto{}, fmt.Errorf("mailto URI has no recipient: %s", uri)
+	}
+	if m.Subject == "" {
+		m.Subject = "unsubscribe"
+	}
+	return m, nil
+}
+
+// NewHTTPClient returns the client for one-click requests: it gives up
+// after timeout and follows at most a few redirects, all to https: URLs.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, CheckRedirect: CheckRedirect}
+}
+
+// CheckRedirect refuses a redirect to anything but an https: URL, and stops
+// after maxRedirects.
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing redirect to non-HTTPS URL %s", req.URL.Redacted())
+	}
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return nil
+}
+
+// Post sends the RFC 8058 one-click unsubscribe request to an https: URI
+// and returns the HTTP status. Any status outside 2xx is an error.
+func Post(client *http.Client, uri string) (int, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return 0, fmt.Errorf("one-click unsubscribe needs an https: URL, got %s", uri)
+	}
+	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(OneClickBody))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unsubscribe request returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// State is the local record of unsubscribes for one account, kept in the
+// JSON file at Path and keyed by list.
+type State struct {
+	Path    string
+	Account string
+}
+
+type stateFile struct {
+	Accounts map[string]map[string]types.UnsubscribeRecord `json:"accounts"`
+}
+
+// Lookup returns the last recorded outcome for list, if any.
+func (s *State) Lookup(list string) (types.UnsubscribeRecord, bool, error) {
+	state, err := s.load()
+	if err != nil {
+		return types.UnsubscribeRecord{}, false, err
+	}
+	r, ok := state.Accounts[s.Account][list]
+	return r, ok, nil
+}
+
+// Save records r as the latest outcome for list.
+func (s *State) Save(list string, r types.UnsubscribeRecord) error {
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+	if state.Accounts[s.Account] == nil {
+		state.Accounts[s.Account] = map[string]types.UnsubscribeRecord{}
+	}
+	state.Accounts[s.Account][list] = r
+
+	if err := statefile.WriteJSON(s.Path, state); err != nil {
+		return fmt.Errorf("writing unsubscribe state: %w", err)
+	}
+	return nil
+}
+
+func (s *State) load() (stateFile, error) {
+	state := stateFile{Accounts: map[string]map[string]types.UnsubscribeRecord{}}
+	raw, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("reading unsubscribe state: %w", err)
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return state, fmt.Errorf("parsing %s: %w", s.Path, err)
+	}
+	if state.Accounts == nil {
+		state.Accounts = map[string]map[string]types.UnsubscribeRecord{}
+	}
+	return state, nil
+}
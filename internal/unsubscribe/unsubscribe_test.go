@@ -0,0 +1,127 @@
+package unsubscribe
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestParse(t *testing.T) {
+	got := Parse("<http://example.com/u>, <mailto:leave@lists.example?subject=stop>,\r\n <https://example.com/one\r\n click?id=1>, <https://example.com/second>")
+	if got.HTTPS != "https://example.com/oneclick?id=1" {
+		t.Errorf("HTTPS = %q", got.HTTPS)
+	}
+	if got.Mailto != "mailto:leave@lists.example?subject=stop" {
+		t.Errorf("Mailto = %q", got.Mailto)
+	}
+	if len(got.Other) != 1 || got.Other[0] != "http://example.com/u" {
+		t.Errorf("Other = %q", got.Other)
+	}
+	if empty := Parse("not a uri"); empty.HTTPS != "" || empty.Mailto != "" || empty.Other != nil {
+		t.Errorf("expected nothing from a bare value, got %+v", empty)
+	}
+}
+
+func TestOneClick(t *testing.T) {
+	if !OneClick(" list-unsubscribe=one-click ") || OneClick("") || OneClick("List-Unsubscribe=Other") {
+		t.Error("unexpected OneClick result")
+	}
+}
+
+func TestParseMailto(t *testing.T) {
+	m, err := ParseMailto("mailto:leave%2Bx@lists.example,other@lists.example?Subject=Remove%20me&body=unsubscribe%20bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(m.To, ",") != "leave+x@lists.example,other@lists.example" || m.Subject != "Remove me" || m.Body != "unsubscribe bob" {
+		t.Errorf("unexpected mailto: %+v", m)
+	}
+
+	m, err = ParseMailto("mailto:?to=leave@lists.example")
+	if err != nil || len(m.To) != 1 || m.Subject != "unsubscribe" {
+		t.Errorf("expected the default subject and a to= recipient, got %+v, %v", m, err)
+	}
+	if _, err := ParseMailto("mailto:?subject=x"); err == nil {
+		t.Error("expected an error without a recipient")
+	}
+	if _, err := ParseMailto("https://example.com"); err == nil {
+		t.Error("expected an error for a non-mailto URI")
+	}
+}
+
+func TestPost(t *testing.T) {
+	var gotBody, gotType string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/unsubscribe":
+			body, _ := io.ReadAll(r.Body)
+			gotBody, gotType = string(body), r.Header.Get("Content-Type")
+			w.WriteHeader(http.StatusAccepted)
+		case "/moved":
+			http.Redirect(w, r, "/unsubscribe", http.StatusPermanentRedirect)
+		case "/insecure":
+			http.Redirect(w, r, "http://example.com/unsubscribe", http.StatusTemporaryRedirect)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	client := srv.Client()
+	client.CheckRedirect = CheckRedirect
+	client.Timeout = 5 * time.Second
+
+	status, err := Post(client, srv.URL+"/moved")
+	if err != nil || status != http.StatusAccepted {
+		t.Fatalf("Post = %d, %v", status, err)
+	}
+	if gotBody != OneClickBody || gotType != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected request: %q %q", gotBody, gotType)
+	}
+
+	if _, err := Post(client, srv.URL+"/insecure"); err == nil || !strings.Contains(err.Error(), "non-HTTPS") {
+		t.Errorf("expected a refused redirect, got %v", err)
+	}
+	if status, err := Post(client, srv.URL+"/gone"); err == nil || status != http.StatusNotFound {
+		t.Errorf("expected a 404 error, got %d, %v", status, err)
+	}
+	if _, err := Post(client, "http://example.com/u"); err == nil {
+		t.Error("expected plain http to be refused")
+	}
+}
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "unsubscribe.json")
+	s := &State{Path: path, Account: "agent"}
+
+	if _, found, err := s.Lookup("news.example.com"); found || err != nil {
+		t.Fatalf("expected nothing recorded yet, got %v, %v", found, err)
+	}
+	rec := types.UnsubscribeRecord{Time: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), EmailID: "M1", Method: "one_click", OK: true}
+	if err := s.Save("news.example.com", rec); err != nil {
+		t.Fatal(err)
+	}
+	got, found, err := s.Lookup("news.example.com")
+	if err != nil || !found || got != rec {
+		t.Errorf("Lookup = %+v, %v, %v", got, found, err)
+	}
+
+	other := &State{Path: path, Account: "work"}
+	if _, found, _ := other.Lookup("news.example.com"); found {
+		t.Error("expected records to be kept per account")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("state file mode = %v, want 0600", info.Mode().Perm())
+	}
+}